@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"my-scheduler-plugins/pkg/descheduler"
+	"my-scheduler-plugins/pkg/leader"
+)
+
+func main() {
+	// Evict the survivors of gangs that fell below minAvailable, so they are
+	// rescheduled together.
+	kubeconfig := flag.String("kubeconfig", "", "path to a kubeconfig; in-cluster config is used when empty")
+	interval := flag.Duration("interval", 0, "how often gangs are checked; 30s when 0")
+	gracePeriod := flag.Duration("grace-period", 0, "how long a gang may stay below minAvailable before its survivors are evicted; 5m when 0")
+	dryRun := flag.Bool("dry-run", false, "log the evictions instead of making them")
+	leaderElect := flag.Bool("leader-elect", false, "run in the replica holding the my-scheduler-descheduler Lease only, for several replicas")
+	leaseNamespace := flag.String("lease-namespace", "kube-system", "namespace of the Lease with -leader-elect")
+	flag.Parse()
+
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		log.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	client := kubernetes.NewForConfigOrDie(config)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	pods := informerFactory.Core().V1().Pods().Lister()
+
+	stopCh := make(chan struct{})
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+
+	controller, err := descheduler.NewController(client, pods, descheduler.Options{Interval: *interval, GracePeriod: *gracePeriod, DryRun: *dryRun})
+	if err != nil {
+		log.Fatalf("failed to create descheduler: %v", err)
+	}
+	log.Printf("custom-scheduler descheduler runs with dry run %t.", *dryRun)
+	if *leaderElect {
+		lease := leader.Config{LeaseName: "my-scheduler-descheduler", LeaseNamespace: *leaseNamespace}
+		if err := leader.Run(context.Background(), client, lease, controller.Run); err != nil {
+			log.Fatalf("failed to run leader election: %v", err)
+		}
+		return
+	}
+	controller.Run(context.Background())
+}
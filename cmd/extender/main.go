@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"my-scheduler-plugins/pkg/extender"
+)
+
+func main() {
+	// Serve the CustomScheduler logic as a kube-scheduler extender.
+	kubeconfig := flag.String("kubeconfig", "", "path to a kubeconfig; in-cluster config is used when empty")
+	addr := flag.String("addr", ":8888", "address to serve the extender API on")
+	mode := flag.String("mode", "Least", "score mode: Least or Most")
+	flag.Parse()
+
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		log.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	client := kubernetes.NewForConfigOrDie(config)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	pods := informerFactory.Core().V1().Pods().Lister()
+	nodes := informerFactory.Core().V1().Nodes().Lister()
+
+	stopCh := make(chan struct{})
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+
+	server, err := extender.NewServer(*mode, pods, nodes)
+	if err != nil {
+		log.Fatalf("failed to create extender: %v", err)
+	}
+	log.Printf("custom-scheduler extender runs with the mode %s on %s.", *mode, *addr)
+	log.Fatal(http.ListenAndServe(*addr, server.Handler()))
+}
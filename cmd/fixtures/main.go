@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"my-scheduler-plugins/pkg/fixtures"
+	"sigs.k8s.io/yaml"
+)
+
+func main() {
+	// Print a generated pod group workload as a multi-document YAML stream.
+	var opts fixtures.Options
+	flag.Int64Var(&opts.Seed, "seed", 1, "random seed")
+	flag.IntVar(&opts.Groups, "groups", 3, "number of pod groups")
+	flag.IntVar(&opts.MinSize, "min-size", 2, "minimum pods per group")
+	flag.IntVar(&opts.MaxSize, "max-size", 5, "maximum pods per group")
+	flag.StringVar(&opts.Namespace, "namespace", "default", "namespace of the pods")
+	flag.StringVar(&opts.SchedulerName, "scheduler-name", "my-scheduler", "schedulerName of the pods")
+	flag.Parse()
+
+	for _, g := range fixtures.Generate(opts) {
+		for _, p := range g.Pods() {
+			out, err := yaml.Marshal(p)
+			if err != nil {
+				log.Fatalf("failed to marshal pod %s: %v", p.Name, err)
+			}
+			fmt.Fprintf(os.Stdout, "---\n%s", out)
+		}
+	}
+}
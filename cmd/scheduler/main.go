@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 	"log"
 	"k8s.io/component-base/cli"
 	"k8s.io/kubernetes/cmd/kube-scheduler/app"
@@ -11,10 +14,13 @@ import (
 func main() {
 	// Register custom plugins to the scheduler framework.
 	log.Printf("custom-scheduler starts!\n")
+	// Stop the plugins' loops, endpoints and leader election on shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	command := app.NewSchedulerCommand(
-		app.WithPlugin(plugins.Name, plugins.New),
+		app.WithPlugin(plugins.Name, plugins.NewWithContext(ctx)),
 	)
 
 	code := cli.Run(command)
+	stop()
 	os.Exit(code)
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"my-scheduler-plugins/pkg/simulate"
+)
+
+func main() {
+	// Preview how the plugin ranks nodes and admits gangs, without a cluster.
+	argsFile := flag.String("args", "", "path to the plugin's pluginConfig args as YAML; the Least mode when empty")
+	format := flag.String("format", "text", "output format: text or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] FILE...\n\nFILEs hold the Node and Pod YAMLs to simulate; pods with a nodeName run there, the others are scheduled.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var args []byte
+	if *argsFile != "" {
+		var err error
+		if args, err = os.ReadFile(*argsFile); err != nil {
+			log.Fatalf("failed to read args: %v", err)
+		}
+	}
+	cluster := &simulate.Cluster{}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatalf("failed to open %s: %v", name, err)
+		}
+		err = cluster.Load(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("failed to load %s: %v", name, err)
+		}
+	}
+
+	decisions, err := simulate.Run(cluster, args)
+	if err != nil {
+		log.Fatalf("simulation failed: %v", err)
+	}
+	switch *format {
+	case "text":
+		err = simulate.WriteText(os.Stdout, decisions)
+	case "json":
+		err = simulate.WriteJSON(os.Stdout, decisions)
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatalf("failed to write decisions: %v", err)
+	}
+}
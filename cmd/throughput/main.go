@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"my-scheduler-plugins/pkg/fixtures"
+	"my-scheduler-plugins/pkg/throughput"
+)
+
+func main() {
+	// Compare scheduling throughput of the plugin's modes across cluster shapes.
+	var opts fixtures.Options
+	format := flag.String("format", "csv", "report format: csv or json")
+	modes := flag.String("modes", "Least,Most", "comma-separated score modes to compare")
+	flag.Int64Var(&opts.Seed, "seed", 1, "random seed of the workload")
+	flag.IntVar(&opts.Groups, "groups", 50, "number of pod groups")
+	flag.IntVar(&opts.MinSize, "min-size", 2, "minimum pods per group")
+	flag.IntVar(&opts.MaxSize, "max-size", 8, "maximum pods per group")
+	flag.Parse()
+	// the plugin logs through klog; keep the report readable.
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	klog.LogToStderr(false)
+	klog.SetOutput(io.Discard)
+
+	groups := fixtures.Generate(opts)
+	var results []throughput.Result
+	for _, shape := range throughput.Shapes {
+		for _, mode := range strings.Split(*modes, ",") {
+			r, err := throughput.Run(shape, mode, groups)
+			if err != nil {
+				logger.Fatalf("shape %s, mode %s: %v", shape.Name, mode, err)
+			}
+			results = append(results, r)
+		}
+	}
+
+	var err error
+	switch *format {
+	case "csv":
+		err = throughput.WriteCSV(os.Stdout, results)
+	case "json":
+		err = throughput.WriteJSON(os.Stdout, results)
+	default:
+		logger.Fatalf("unknown format %q", *format)
+	}
+	if err != nil {
+		logger.Fatalf("failed to write report: %v", err)
+	}
+}
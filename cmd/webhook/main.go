@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"my-scheduler-plugins/pkg/webhook"
+)
+
+func main() {
+	// Serve the admission webhook that defaults and validates gang labels.
+	kubeconfig := flag.String("kubeconfig", "", "path to a kubeconfig; in-cluster config is used when empty")
+	addr := flag.String("addr", ":8443", "address to serve the webhook on")
+	certFile := flag.String("tls-cert-file", "/etc/webhook/tls/tls.crt", "TLS certificate the API server trusts")
+	keyFile := flag.String("tls-private-key-file", "/etc/webhook/tls/tls.key", "private key of the TLS certificate")
+	podGroups := flag.Bool("pod-groups", true, "default minAvailable from PodGroup custom resources")
+	flag.Parse()
+
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		log.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	client := kubernetes.NewForConfigOrDie(config)
+	var dynamicClient dynamic.Interface
+	if *podGroups {
+		dynamicClient = dynamic.NewForConfigOrDie(config)
+	}
+
+	server := webhook.NewServer(client, dynamicClient)
+	log.Printf("custom-scheduler admission webhook runs on %s.", *addr)
+	log.Fatal(http.ListenAndServeTLS(*addr, *certFile, *keyFile, server.Handler()))
+}
@@ -61,13 +61,19 @@ replace k8s.io/sample-cli-plugin => k8s.io/sample-cli-plugin v0.27.1
 replace k8s.io/sample-controller => k8s.io/sample-controller v0.27.1
 
 require (
-	github.com/golang/protobuf v1.5.3
 	google.golang.org/grpc v1.51.0
+	google.golang.org/protobuf v1.28.1
 	k8s.io/api v0.27.1
 	k8s.io/apimachinery v0.27.1
 	k8s.io/client-go v0.27.1
 	k8s.io/component-base v0.27.1
+	k8s.io/component-helpers v0.27.1
+	k8s.io/dynamic-resource-allocation v0.0.0
+	k8s.io/klog/v2 v2.90.1
+	k8s.io/kube-scheduler v0.25.7
 	k8s.io/kubernetes v1.27.1
+	k8s.io/utils v0.0.0-20230209194617-a36077c30491
+	sigs.k8s.io/yaml v1.3.0
 )
 
 require (
@@ -94,6 +100,7 @@ require (
 	github.com/go-openapi/swag v0.22.3 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/google/cel-go v0.12.6 // indirect
 	github.com/google/gnostic v0.5.7-v3refs // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
@@ -149,26 +156,19 @@ require (
 	golang.org/x/time v0.0.0-20220210224613-90d013bbcef8 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto v0.0.0-20220502173005-c8bf987b8c21 // indirect
-	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	k8s.io/apiserver v0.27.1 // indirect
 	k8s.io/cloud-provider v0.25.7 // indirect
-	k8s.io/component-helpers v0.27.1 // indirect
 	k8s.io/controller-manager v0.27.1 // indirect
 	k8s.io/csi-translation-lib v0.25.7 // indirect
-	k8s.io/dynamic-resource-allocation v0.0.0 // indirect
-	k8s.io/klog/v2 v2.90.1 // indirect
 	k8s.io/kms v0.27.1 // indirect
 	k8s.io/kube-openapi v0.0.0-20230308215209-15aac26d736a // indirect
-	k8s.io/kube-scheduler v0.25.7 // indirect
 	k8s.io/kubelet v0.27.1 // indirect
 	k8s.io/mount-utils v0.25.7 // indirect
-	k8s.io/utils v0.0.0-20230209194617-a36077c30491 // indirect
 	sigs.k8s.io/apiserver-network-proxy/konnectivity-client v0.1.1 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.3 // indirect
-	sigs.k8s.io/yaml v1.3.0 // indirect
 )
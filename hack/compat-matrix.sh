@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+# Runs the plugin's unit tests against every supported scheduler framework
+# version. Each entry is "<k8s.io/kubernetes version> <build tag>"; the staging
+# modules (k8s.io/api, client-go, ...) use the matching v0.<minor>.<patch>.
+# A version of "-" tests against go.mod as checked in.
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+MATRIX=(
+  "- k8s127"
+  "v1.28.4 k8s128"
+)
+
+for entry in "${MATRIX[@]}"; do
+  read -r version tag <<<"${entry}"
+  if [[ "${version}" == "-" ]]; then
+    echo ">>> scheduler framework from go.mod (-tags ${tag})"
+    go test -tags "${tag}" ./pkg/plugins/...
+    continue
+  fi
+  staging="v0.${version#v1.}"
+  modfile="$(mktemp -d)/go.mod"
+  sed -e "s#\(k8s.io/kubernetes\) v1\.[0-9]*\.[0-9]*#\1 ${version}#" \
+      -e "s#\(=> k8s.io/[a-z0-9-]*\) v0\.[0-9]*\.[0-9]*\(-alpha\.[0-9]*\)\?#\1 ${staging}#" \
+      go.mod >"${modfile}"
+  cp go.sum "${modfile%.mod}.sum"
+
+  echo ">>> scheduler framework ${version} (-tags ${tag})"
+  go mod tidy -modfile="${modfile}"
+  go test -modfile="${modfile}" -tags "${tag}" ./pkg/plugins/...
+done
@@ -0,0 +1,183 @@
+// Package descheduler evicts the surviving members of gangs that lost
+// members while running, e.g. to a node failure, and stayed below
+// minAvailable, so that the whole gang is rescheduled together instead of
+// a half-dead distributed job holding on to its nodes.
+package descheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"my-scheduler-plugins/pkg/plugins"
+)
+
+// Options configures a Controller.
+type Options struct {
+	// Interval is how often gangs are checked, 30 seconds by default.
+	Interval time.Duration
+	// GracePeriod is how long a gang may stay below minAvailable, for its
+	// replacement members to be scheduled, before its survivors are
+	// evicted. 5 minutes by default.
+	GracePeriod time.Duration
+	// DryRun logs the evictions instead of making them.
+	DryRun bool
+}
+
+// Controller evicts the survivors of degraded gangs: gangs with members
+// still bound to a node, but fewer than minAvailable of them counting those
+// that completed.
+type Controller struct {
+	client  kubernetes.Interface
+	pods    plugins.PodLister
+	groups  *plugins.CustomScheduler
+	clock   clock.Clock
+	options Options
+
+	// degraded are the times gangs, by namespace/name, were first seen
+	// degraded, and mu serializes the checks updating them.
+	mu       sync.Mutex
+	degraded map[string]time.Time
+}
+
+// NewController returns a Controller reading pods from pods and evicting
+// them through client. Gangs are resolved from pod labels, as the
+// scheduler resolves them.
+func NewController(client kubernetes.Interface, pods plugins.PodLister, options Options) (*Controller, error) {
+	groups, err := plugins.NewStandalone("Least", pods, nil)
+	if err != nil {
+		return nil, err
+	}
+	if options.Interval <= 0 {
+		options.Interval = 30 * time.Second
+	}
+	if options.GracePeriod <= 0 {
+		options.GracePeriod = 5 * time.Minute
+	}
+	return &Controller{client: client, pods: pods, groups: groups, clock: clock.RealClock{}, options: options, degraded: map[string]time.Time{}}, nil
+}
+
+// Run checks the gangs every interval until ctx is done. Gangs degraded
+// before it started count from the first check, so that a replica taking
+// over from another never evicts before the grace period.
+func (c *Controller) Run(ctx context.Context) {
+	c.mu.Lock()
+	c.degraded = map[string]time.Time{}
+	c.mu.Unlock()
+	for {
+		if err := c.RunOnce(ctx); err != nil {
+			klog.ErrorS(err, "Failed to deschedule degraded gangs")
+		}
+		timer := c.clock.NewTimer(c.options.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+// gang is the state of one gang in a check.
+type gang struct {
+	minAvailable int
+	// survivors are the members bound to a node and not terminated.
+	survivors []*v1.Pod
+	// succeeded counts the members that completed, which were not lost:
+	// workers of a gang that is winding down leave its launcher or chief
+	// running below minAvailable.
+	succeeded int
+}
+
+// RunOnce checks every gang once, evicting the survivors of those degraded
+// for longer than the grace period.
+func (c *Controller) RunOnce(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pods, err := c.pods.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	gangs := map[string]*gang{}
+	for _, p := range pods {
+		key, minAvailable, ok := c.groups.GroupOf(p)
+		if !ok {
+			continue
+		}
+		g, ok := gangs[key]
+		if !ok {
+			g = &gang{minAvailable: minAvailable}
+			gangs[key] = g
+		}
+		if p.Status.Phase == v1.PodSucceeded {
+			g.succeeded++
+		} else if p.Spec.NodeName != "" && p.DeletionTimestamp == nil && (p.Status.Phase == v1.PodPending || p.Status.Phase == v1.PodRunning) {
+			g.survivors = append(g.survivors, p)
+		}
+	}
+
+	now := c.clock.Now()
+	keys := make([]string, 0, len(gangs))
+	for key := range gangs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, key := range keys {
+		g := gangs[key]
+		if len(g.survivors) == 0 || len(g.survivors)+g.succeeded >= g.minAvailable {
+			delete(c.degraded, key)
+			continue
+		}
+		since, ok := c.degraded[key]
+		if !ok {
+			klog.V(2).InfoS("Pod group fell below minAvailable", "group", key, "survivors", len(g.survivors), "minAvailable", g.minAvailable)
+			c.degraded[key] = now
+			continue
+		}
+		if now.Sub(since) < c.options.GracePeriod {
+			continue
+		}
+		if err := c.evict(ctx, key, g); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(c.degraded, key)
+	}
+	for key := range c.degraded {
+		if _, ok := gangs[key]; !ok {
+			delete(c.degraded, key)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to evict the survivors of %d pod groups, first: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// evict evicts the survivors of the gang key through the eviction API, so
+// PodDisruptionBudgets are respected.
+func (c *Controller) evict(ctx context.Context, key string, g *gang) error {
+	klog.InfoS("Evicting the survivors of a degraded pod group", "group", key, "survivors", len(g.survivors), "minAvailable", g.minAvailable, "dryRun", c.options.DryRun)
+	if c.options.DryRun {
+		return nil
+	}
+	for _, p := range g.survivors {
+		eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name}}
+		err := c.client.PolicyV1().Evictions(p.Namespace).Evict(ctx, eviction)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to evict pod %s/%s of pod group %s: %w", p.Namespace, p.Name, key, err)
+		}
+	}
+	return nil
+}
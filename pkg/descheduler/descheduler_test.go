@@ -0,0 +1,141 @@
+package descheduler
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+type podList []*v1.Pod
+
+func (l podList) List(selector labels.Selector) ([]*v1.Pod, error) {
+	var pods []*v1.Pod
+	for _, p := range l {
+		if selector.Matches(labels.Set(p.Labels)) {
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
+
+// running binds pods to a node and marks them running.
+func running(pods []*v1.Pod) []*v1.Pod {
+	for _, p := range pods {
+		p.Spec.NodeName = "n1"
+		p.Status.Phase = v1.PodRunning
+	}
+	return pods
+}
+
+func TestController_RunOnce(t *testing.T) {
+	// degraded lost one of its three members, healthy has all of them, and
+	// pending was never scheduled.
+	degraded := running(fixtures.GroupSpec{Name: "degraded", Namespace: "default", Size: 3, MinAvailable: 3}.Pods())
+	degraded[2].Spec.NodeName = ""
+	degraded[2].Status.Phase = v1.PodPending
+	healthy := running(fixtures.GroupSpec{Name: "healthy", Namespace: "default", Size: 2, MinAvailable: 2}.Pods())
+	pending := fixtures.GroupSpec{Name: "pending", Namespace: "default", Size: 2, MinAvailable: 2}.Pods()
+	pods := podList(append(append(append([]*v1.Pod{}, degraded...), healthy...), pending...))
+
+	for _, tt := range []struct {
+		name   string
+		dryRun bool
+		want   []string
+	}{
+		{name: "evict", want: []string{"degraded-0", "degraded-1"}},
+		{name: "dry run", dryRun: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			var evicted []string
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				evicted = append(evicted, action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction).Name)
+				return true, nil, nil
+			})
+			c, err := NewController(client, pods, Options{GracePeriod: time.Minute, DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+			clock := testingclock.NewFakeClock(time.Now())
+			c.clock = clock
+
+			if err := c.RunOnce(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if len(evicted) != 0 {
+				t.Fatalf("expected no eviction within the grace period, got %v", evicted)
+			}
+			clock.Step(time.Minute)
+			if err := c.RunOnce(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(evicted)
+			if len(evicted) != len(tt.want) || (len(tt.want) > 0 && (evicted[0] != tt.want[0] || evicted[1] != tt.want[1])) {
+				t.Errorf("expected %v to be evicted, got %v", tt.want, evicted)
+			}
+		})
+	}
+}
+
+func TestController_Recovered(t *testing.T) {
+	pods := running(fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 2}.Pods())
+	pods[1].Spec.NodeName = ""
+	client := fake.NewSimpleClientset()
+	c, err := NewController(client, podList(pods), Options{GracePeriod: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := testingclock.NewFakeClock(time.Now())
+	c.clock = clock
+	if err := c.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.degraded["default/g1"]; !ok {
+		t.Fatalf("expected g1 to be degraded, got %v", c.degraded)
+	}
+	// the replacement member is scheduled before the grace period ends.
+	pods[1].Spec.NodeName = "n2"
+	clock.Step(time.Minute)
+	if err := c.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.degraded) != 0 || len(client.Actions()) != 0 {
+		t.Errorf("expected g1 to be forgotten without evictions, got %v and actions %v", c.degraded, client.Actions())
+	}
+}
+
+func TestController_PartlyCompleted(t *testing.T) {
+	// the workers of g1 completed, leaving its launcher running alone.
+	pods := running(fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods())
+	pods[1].Status.Phase = v1.PodSucceeded
+	pods[2].Status.Phase = v1.PodSucceeded
+	client := fake.NewSimpleClientset()
+	c, err := NewController(client, podList(pods), Options{GracePeriod: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := testingclock.NewFakeClock(time.Now())
+	c.clock = clock
+	for i := 0; i < 2; i++ {
+		if err := c.RunOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		clock.Step(2 * time.Minute)
+	}
+	if len(c.degraded) != 0 || len(client.Actions()) != 0 {
+		t.Errorf("expected the partly completed g1 not to be degraded nor evicted, got %v and actions %v", c.degraded, client.Actions())
+	}
+}
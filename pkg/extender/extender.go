@@ -0,0 +1,179 @@
+// Package extender serves the CustomScheduler plugin's PreFilter and Score
+// logic through the kube-scheduler extender HTTP API, for clusters where the
+// scheduler binary cannot be replaced.
+package extender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/plugins"
+)
+
+// Server implements the extender filter and prioritize verbs.
+type Server struct {
+	mode  string
+	pods  plugins.PodLister
+	nodes listersv1.NodeLister
+}
+
+// NewServer returns a Server scoring in mode. nodes is only used when the
+// scheduler is configured with nodeCacheCapable and sends node names only.
+func NewServer(mode string, pods plugins.PodLister, nodes listersv1.NodeLister) (*Server, error) {
+	if _, err := plugins.NewStandalone(mode, pods, nil); err != nil {
+		return nil, err
+	}
+	return &Server{mode: mode, pods: pods, nodes: nodes}, nil
+}
+
+// Handler returns the HTTP handler serving /filter and /prioritize.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/filter", s.serve(func(args *extenderv1.ExtenderArgs) interface{} { return s.Filter(args) }))
+	mux.HandleFunc("/prioritize", s.serve(func(args *extenderv1.ExtenderArgs) interface{} { return s.Prioritize(args) }))
+	return mux
+}
+
+func (s *Server) serve(verb func(*extenderv1.ExtenderArgs) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var args extenderv1.ExtenderArgs
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode extender args: %v", err), http.StatusBadRequest)
+			return
+		}
+		if args.Pod == nil {
+			http.Error(w, "missing pod in extender args", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(verb(&args)); err != nil {
+			klog.ErrorS(err, "Failed to encode extender response")
+		}
+	}
+}
+
+// Filter runs the gang check. The check does not depend on the node, so the
+// pod either fits on every candidate node or on none of them.
+func (s *Server) Filter(args *extenderv1.ExtenderArgs) *extenderv1.ExtenderFilterResult {
+	nodes, err := s.nodeInfos(args)
+	if err != nil {
+		return &extenderv1.ExtenderFilterResult{Error: err.Error()}
+	}
+	cs, err := plugins.NewStandalone(s.mode, s.pods, nodes)
+	if err != nil {
+		return &extenderv1.ExtenderFilterResult{Error: err.Error()}
+	}
+
+	_, status := cs.PreFilter(context.Background(), framework.NewCycleState(), args.Pod)
+	if status.IsSuccess() {
+		return &extenderv1.ExtenderFilterResult{Nodes: args.Nodes, NodeNames: args.NodeNames}
+	}
+	if status.Code() == framework.Error {
+		return &extenderv1.ExtenderFilterResult{Error: status.Message()}
+	}
+	failed := extenderv1.FailedNodesMap{}
+	for name := range nodes {
+		failed[name] = status.Message()
+	}
+	result := &extenderv1.ExtenderFilterResult{FailedNodes: failed}
+	if args.Nodes != nil {
+		result.Nodes = &v1.NodeList{}
+	} else {
+		result.NodeNames = &[]string{}
+	}
+	return result
+}
+
+// Prioritize scores and normalizes every candidate node, scaled to the
+// extender priority range.
+func (s *Server) Prioritize(args *extenderv1.ExtenderArgs) *extenderv1.HostPriorityList {
+	result := extenderv1.HostPriorityList{}
+	nodes, err := s.nodeInfos(args)
+	if err != nil {
+		klog.ErrorS(err, "Failed to prioritize pod", "pod", klog.KObj(args.Pod))
+		return &result
+	}
+	cs, err := plugins.NewStandalone(s.mode, s.pods, nodes)
+	if err != nil {
+		klog.ErrorS(err, "Failed to prioritize pod", "pod", klog.KObj(args.Pod))
+		return &result
+	}
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	scores := framework.NodeScoreList{}
+	for name := range nodes {
+		score, status := cs.Score(ctx, state, args.Pod, name)
+		if !status.IsSuccess() {
+			klog.ErrorS(status.AsError(), "Failed to score node", "pod", klog.KObj(args.Pod), "node", name)
+			continue
+		}
+		scores = append(scores, framework.NodeScore{Name: name, Score: score})
+	}
+	if status := cs.NormalizeScore(ctx, state, args.Pod, scores); !status.IsSuccess() {
+		klog.ErrorS(status.AsError(), "Failed to normalize scores", "pod", klog.KObj(args.Pod))
+		return &result
+	}
+	for _, score := range scores {
+		result = append(result, extenderv1.HostPriority{
+			Host:  score.Name,
+			Score: score.Score * extenderv1.MaxExtenderPriority / framework.MaxNodeScore,
+		})
+	}
+	return &result
+}
+
+// nodeInfos builds the node infos of the candidate nodes in args, resolving
+// node names through the node lister when the scheduler only sent names.
+func (s *Server) nodeInfos(args *extenderv1.ExtenderArgs) (nodeInfoMap, error) {
+	nodes := nodeInfoMap{}
+	if args.Nodes != nil {
+		for i := range args.Nodes.Items {
+			ni := framework.NewNodeInfo()
+			ni.SetNode(&args.Nodes.Items[i])
+			nodes[args.Nodes.Items[i].Name] = ni
+		}
+		return nodes, nil
+	}
+	if args.NodeNames == nil {
+		return nodes, nil
+	}
+	if s.nodes == nil {
+		return nil, fmt.Errorf("extender received node names but has no node lister")
+	}
+	for _, name := range *args.NodeNames {
+		node, err := s.nodes.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get node %s: %w", name, err)
+		}
+		ni := framework.NewNodeInfo()
+		ni.SetNode(node)
+		nodes[name] = ni
+	}
+	return nodes, nil
+}
+
+// nodeInfoMap serves the candidate nodes of one extender call.
+type nodeInfoMap map[string]*framework.NodeInfo
+
+func (m nodeInfoMap) List() ([]*framework.NodeInfo, error) {
+	var nodes []*framework.NodeInfo
+	for _, ni := range m {
+		nodes = append(nodes, ni)
+	}
+	return nodes, nil
+}
+
+func (m nodeInfoMap) Get(nodeName string) (*framework.NodeInfo, error) {
+	if ni, ok := m[nodeName]; ok {
+		return ni, nil
+	}
+	return nil, fmt.Errorf("node %s is not a candidate", nodeName)
+}
@@ -0,0 +1,100 @@
+package extender
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+type podList []*v1.Pod
+
+func (l podList) List(selector labels.Selector) ([]*v1.Pod, error) {
+	var pods []*v1.Pod
+	for _, p := range l {
+		if selector.Matches(labels.Set(p.Labels)) {
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
+
+func makeNode(name, memory string) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{v1.ResourceMemory: resource.MustParse(memory)},
+		},
+	}
+}
+
+func post(t *testing.T, srv *httptest.Server, path string, args extenderv1.ExtenderArgs, out interface{}) {
+	t.Helper()
+	body, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s: unexpected status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	ready := fixtures.GroupSpec{Name: "ready", Size: 2, MinAvailable: 2}.Pods()
+	waiting := fixtures.GroupSpec{Name: "waiting", Size: 1, MinAvailable: 3}.Pods()
+	s, err := NewServer("Most", podList(append(ready, waiting...)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	nodes := &v1.NodeList{Items: []v1.Node{makeNode("small", "1Gi"), makeNode("large", "4Gi")}}
+
+	var filtered extenderv1.ExtenderFilterResult
+	post(t, srv, "/filter", extenderv1.ExtenderArgs{Pod: ready[0], Nodes: nodes}, &filtered)
+	if len(filtered.Nodes.Items) != 2 || len(filtered.FailedNodes) != 0 {
+		t.Errorf("expected both nodes to pass, got %+v", filtered)
+	}
+
+	filtered = extenderv1.ExtenderFilterResult{}
+	post(t, srv, "/filter", extenderv1.ExtenderArgs{Pod: waiting[0], Nodes: nodes}, &filtered)
+	if len(filtered.Nodes.Items) != 0 || len(filtered.FailedNodes) != 2 {
+		t.Errorf("expected both nodes to fail, got %+v", filtered)
+	}
+
+	var priorities extenderv1.HostPriorityList
+	post(t, srv, "/prioritize", extenderv1.ExtenderArgs{Pod: ready[0], Nodes: nodes}, &priorities)
+	got := map[string]int64{}
+	for _, p := range priorities {
+		got[p.Host] = p.Score
+	}
+	if got["large"] != extenderv1.MaxExtenderPriority || got["small"] != extenderv1.MinExtenderPriority {
+		t.Errorf("unexpected priorities %v", got)
+	}
+}
+
+func TestNewServer_InvalidMode(t *testing.T) {
+	// modes other than Least and Most need args the extender does not take.
+	for _, mode := range []string{"Random", "", "LeastCPU", "Weighted", "NUMA", "RealMost"} {
+		if _, err := NewServer(mode, podList(nil), nil); err == nil {
+			t.Errorf("expected an error for the mode %q", mode)
+		}
+	}
+}
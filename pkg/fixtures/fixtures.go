@@ -0,0 +1,128 @@
+// Package fixtures generates pod group workloads shared by tests, benchmarks
+// and demos.
+package fixtures
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Label keys understood by the CustomScheduler plugin.
+const (
+	GroupNameLabel    = "podGroup"
+	MinAvailableLabel = "minAvailable"
+)
+
+// Shape is the resource request of every container in a group.
+type Shape struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// Shapes are the named resource shapes used by Generate.
+var Shapes = map[string]Shape{
+	"small":  {CPU: "100m", Memory: "128Mi"},
+	"medium": {CPU: "500m", Memory: "512Mi"},
+	"large":  {CPU: "2", Memory: "4Gi"},
+}
+
+// GroupSpec describes a pod group.
+type GroupSpec struct {
+	Name          string            `json:"name"`
+	Namespace     string            `json:"namespace,omitempty"`
+	Size          int               `json:"size"`
+	MinAvailable  int               `json:"minAvailable"`
+	Priority      int32             `json:"priority,omitempty"`
+	Shape         Shape             `json:"shape"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	SchedulerName string            `json:"schedulerName,omitempty"`
+}
+
+// Pods returns the member pods of the group.
+func (g GroupSpec) Pods() []*v1.Pod {
+	requests := v1.ResourceList{}
+	if g.Shape.CPU != "" {
+		requests[v1.ResourceCPU] = resource.MustParse(g.Shape.CPU)
+	}
+	if g.Shape.Memory != "" {
+		requests[v1.ResourceMemory] = resource.MustParse(g.Shape.Memory)
+	}
+
+	var pods []*v1.Pod
+	for i := 0; i < g.Size; i++ {
+		labels := map[string]string{
+			GroupNameLabel:    g.Name,
+			MinAvailableLabel: strconv.Itoa(g.MinAvailable),
+		}
+		for k, v := range g.Labels {
+			labels[k] = v
+		}
+		priority := g.Priority
+		pods = append(pods, &v1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", g.Name, i),
+				Namespace: g.Namespace,
+				Labels:    labels,
+			},
+			Spec: v1.PodSpec{
+				SchedulerName: g.SchedulerName,
+				Priority:      &priority,
+				Containers: []v1.Container{{
+					Name:  "main",
+					Image: "registry.k8s.io/pause:3.9",
+					Resources: v1.ResourceRequirements{
+						Requests: requests,
+						Limits:   requests,
+					},
+				}},
+			},
+		})
+	}
+	return pods
+}
+
+// Options parameterizes Generate.
+type Options struct {
+	// Seed makes the generated workload reproducible.
+	Seed          int64
+	Groups        int
+	MinSize       int
+	MaxSize       int
+	Namespace     string
+	SchedulerName string
+}
+
+// Generate returns opts.Groups pod groups with sizes in [MinSize, MaxSize],
+// a random named shape, a priority in [0, 1000) and minAvailable between
+// half of the group and the whole group.
+func Generate(opts Options) []GroupSpec {
+	if opts.MinSize < 1 {
+		opts.MinSize = 1
+	}
+	if opts.MaxSize < opts.MinSize {
+		opts.MaxSize = opts.MinSize
+	}
+	shapeNames := []string{"small", "medium", "large"}
+	r := rand.New(rand.NewSource(opts.Seed))
+
+	var groups []GroupSpec
+	for i := 0; i < opts.Groups; i++ {
+		size := opts.MinSize + r.Intn(opts.MaxSize-opts.MinSize+1)
+		groups = append(groups, GroupSpec{
+			Name:          fmt.Sprintf("group%d", i),
+			Namespace:     opts.Namespace,
+			Size:          size,
+			MinAvailable:  (size+1)/2 + r.Intn(size/2+1),
+			Priority:      int32(r.Intn(1000)),
+			Shape:         Shapes[shapeNames[r.Intn(len(shapeNames))]],
+			SchedulerName: opts.SchedulerName,
+		})
+	}
+	return groups
+}
@@ -0,0 +1,41 @@
+package fixtures
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := Options{Seed: 42, Groups: 20, MinSize: 2, MaxSize: 8, Namespace: "demo"}
+	groups := Generate(opts)
+	if len(groups) != opts.Groups {
+		t.Fatalf("expected %d groups, got %d", opts.Groups, len(groups))
+	}
+	for _, g := range groups {
+		if g.Size < opts.MinSize || g.Size > opts.MaxSize {
+			t.Errorf("group %s: size %d out of range", g.Name, g.Size)
+		}
+		if g.MinAvailable < 1 || g.MinAvailable > g.Size {
+			t.Errorf("group %s: minAvailable %d out of range", g.Name, g.MinAvailable)
+		}
+	}
+	if !reflect.DeepEqual(groups, Generate(opts)) {
+		t.Errorf("expected the same seed to generate the same groups")
+	}
+}
+
+func TestGroupSpec_Pods(t *testing.T) {
+	g := GroupSpec{Name: "g1", Namespace: "demo", Size: 3, MinAvailable: 2, Shape: Shapes["small"], Labels: map[string]string{"app": "x"}}
+	pods := g.Pods()
+	if len(pods) != 3 {
+		t.Fatalf("expected 3 pods, got %d", len(pods))
+	}
+	for _, p := range pods {
+		if p.Labels[GroupNameLabel] != "g1" || p.Labels[MinAvailableLabel] != "2" || p.Labels["app"] != "x" {
+			t.Errorf("unexpected labels %v", p.Labels)
+		}
+		if p.Spec.Containers[0].Resources.Requests.Memory().String() != "128Mi" {
+			t.Errorf("unexpected requests %v", p.Spec.Containers[0].Resources.Requests)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package leader runs the stateful components of the scheduler, such as
+// the descheduler and the background loops of the plugin, in one replica
+// at a time, holding a Lease.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// Config is the Lease a component holds while it runs, and how it is
+// renewed.
+type Config struct {
+	// LeaseName and LeaseNamespace name the Lease; LeaseNamespace is
+	// kube-system by default.
+	LeaseName      string
+	LeaseNamespace string
+	// Identity tells the replicas apart, the hostname with a random suffix
+	// by default.
+	Identity string
+	// LeaseDuration, RenewDeadline and RetryPeriod are those of
+	// kube-scheduler's leader election, 15, 10 and 2 seconds by default.
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+func (c Config) withDefaults() (Config, error) {
+	if c.LeaseName == "" {
+		return c, fmt.Errorf("lease name must not be empty")
+	}
+	if c.LeaseNamespace == "" {
+		c.LeaseNamespace = "kube-system"
+	}
+	if c.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return c, fmt.Errorf("failed to get the hostname: %w", err)
+		}
+		c.Identity = hostname + "_" + string(uuid.NewUUID())
+	}
+	if c.LeaseDuration == 0 {
+		c.LeaseDuration = 15 * time.Second
+	}
+	if c.RenewDeadline == 0 {
+		c.RenewDeadline = 10 * time.Second
+	}
+	if c.RetryPeriod == 0 {
+		c.RetryPeriod = 2 * time.Second
+	}
+	return c, nil
+}
+
+// Run calls run, with a context cancelled once the Lease is lost, every
+// time this replica acquires the Lease, until ctx is done. A replica that
+// loses the Lease contends for it again, so run must start over from the
+// state in the API server rather than from what it held before.
+func Run(ctx context.Context, client kubernetes.Interface, config Config, run func(ctx context.Context)) error {
+	config, err := config.withDefaults()
+	if err != nil {
+		return err
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, config.LeaseNamespace, config.LeaseName,
+		client.CoreV1(), client.CoordinationV1(), resourcelock.ResourceLockConfig{Identity: config.Identity})
+	if err != nil {
+		return fmt.Errorf("failed to create the lock of lease %s/%s: %w", config.LeaseNamespace, config.LeaseName, err)
+	}
+	logger := klog.FromContext(ctx).WithValues("lease", klog.KRef(config.LeaseNamespace, config.LeaseName), "identity", config.Identity)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            config.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.Info("Acquired the lease")
+				run(ctx)
+			},
+			OnStoppedLeading: func() {
+				logger.Info("Lost the lease")
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up leader election on lease %s/%s: %w", config.LeaseNamespace, config.LeaseName, err)
+	}
+	for ctx.Err() == nil {
+		elector.Run(ctx)
+	}
+	return nil
+}
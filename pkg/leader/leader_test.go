@@ -0,0 +1,51 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRun(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	config := Config{LeaseName: "test", Identity: "replica-1", LeaseDuration: time.Second, RenewDeadline: 500 * time.Millisecond, RetryPeriod: 100 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	leading := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, client, config, func(ctx context.Context) {
+			close(leading)
+			<-ctx.Done()
+		})
+	}()
+	select {
+	case <-leading:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected to acquire the lease")
+	}
+	lease, err := client.CoordinationV1().Leases("kube-system").Get(context.Background(), "test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "replica-1" {
+		t.Errorf("expected the lease to be held by replica-1, got %v", lease.Spec.HolderIdentity)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Run to return once the context is done")
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	if err := Run(context.Background(), fake.NewSimpleClientset(), Config{}, func(context.Context) {}); err == nil {
+		t.Error("expected an error without a lease name")
+	}
+}
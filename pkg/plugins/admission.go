@@ -0,0 +1,125 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// groupPriorityLabel is the priority of a labelled gang in the admission
+// gate, by default the priority of its pods.
+const groupPriorityLabel string = "groupPriority"
+
+// admissionTicketTTL is how long a gang turned away by the admission gate
+// keeps its place in line without coming back to Permit. It outlasts the
+// five minutes the scheduling queue keeps unschedulable pods before
+// retrying them, so only gangs that are gone lose their place.
+const admissionTicketTTL = 10 * time.Minute
+
+// admissionTicket is the place in line of a gang waiting to be admitted.
+type admissionTicket struct {
+	priority int32
+	arrived  time.Time
+	seen     time.Time
+}
+
+// gangAdmission lets a single incomplete gang at a time, by namespace/name
+// of the group, wait in Permit holding reserved resources, so that gangs
+// competing for the same capacity cannot each hold part of it and starve
+// one another. The others line up by priority and then arrival.
+type gangAdmission struct {
+	mu      sync.Mutex
+	holder  string
+	tickets map[string]admissionTicket
+}
+
+// admit reports whether the gang key may wait in Permit, admitting it if
+// the gate is free and no gang is ahead of it in line. Otherwise it lines
+// up the gang and returns the gang that holds the gate or is ahead of it.
+func (g *gangAdmission) admit(key string, priority int32, now time.Time) (ahead string, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.holder == key {
+		return "", true
+	}
+	if g.tickets == nil {
+		g.tickets = map[string]admissionTicket{}
+	}
+	ticket, found := g.tickets[key]
+	if !found {
+		ticket.arrived = now
+	}
+	ticket.priority, ticket.seen = priority, now
+	g.tickets[key] = ticket
+	if g.holder != "" {
+		return g.holder, false
+	}
+	head := key
+	for k, t := range g.tickets {
+		if now.Sub(t.seen) > admissionTicketTTL {
+			delete(g.tickets, k)
+			continue
+		}
+		if t.before(k, g.tickets[head], head) {
+			head = k
+		}
+	}
+	if head != key {
+		return head, false
+	}
+	delete(g.tickets, key)
+	g.holder = key
+	return "", true
+}
+
+// before reports whether the ticket t of the gang key goes before the
+// ticket other of the gang otherKey.
+func (t admissionTicket) before(key string, other admissionTicket, otherKey string) bool {
+	if t.priority != other.priority {
+		return t.priority > other.priority
+	}
+	if !t.arrived.Equal(other.arrived) {
+		return t.arrived.Before(other.arrived)
+	}
+	return key < otherKey
+}
+
+// release frees the gate if the gang key holds it, once the gang is
+// complete or rejected.
+func (g *gangAdmission) release(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.holder == key {
+		g.holder = ""
+	}
+}
+
+// groupPriority is the priority of the gang of pod in the admission gate:
+// that of its PodGroup or groupPriority label, or else that of pod.
+func groupPriority(pod *v1.Pod, group *podGroup) int32 {
+	if group.priority != nil {
+		return *group.priority
+	}
+	return corev1helpers.PodPriority(pod)
+}
+
+// labelGroupPriority returns the groupPriority label of pod in the
+// labelled gang name, or nil if it has none.
+func labelGroupPriority(pod *v1.Pod, name string) (*int32, *framework.Status) {
+	value, ok := pod.Labels[groupPriorityLabel]
+	if !ok {
+		return nil, nil
+	}
+	priority, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("invalid %s label %q of pod group %s: must be a 32-bit integer", groupPriorityLabel, truncate(value), name))
+	}
+	p := int32(priority)
+	return &p, nil
+}
@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func TestGangAdmission_Order(t *testing.T) {
+	now := time.Now()
+	var g gangAdmission
+	if _, ok := g.admit("default/a", 0, now); !ok {
+		t.Fatal("expected the first gang to be admitted")
+	}
+	// b arrives before c, but c has the higher priority.
+	g.admit("default/b", 0, now.Add(time.Second))
+	g.admit("default/c", 10, now.Add(2*time.Second))
+	g.admit("default/d", 0, now.Add(3*time.Second))
+
+	if ahead, ok := g.admit("default/c", 10, now.Add(4*time.Second)); ok || ahead != "default/a" {
+		t.Fatalf("expected c to wait for a, got %q, %v", ahead, ok)
+	}
+	g.release("default/a")
+	if ahead, ok := g.admit("default/b", 0, now.Add(5*time.Second)); ok || ahead != "default/c" {
+		t.Fatalf("expected b to wait for c, got %q, %v", ahead, ok)
+	}
+	for _, next := range []struct {
+		key      string
+		priority int32
+	}{{"default/c", 10}, {"default/b", 0}, {"default/d", 0}} {
+		if _, ok := g.admit(next.key, next.priority, now.Add(6*time.Second)); !ok {
+			t.Fatalf("expected %s to be admitted next", next.key)
+		}
+		g.release(next.key)
+	}
+}
+
+func TestGangAdmission_StaleTickets(t *testing.T) {
+	now := time.Now()
+	var g gangAdmission
+	g.admit("default/a", 0, now)
+	g.admit("default/gone", 10, now)
+	g.release("default/a")
+	if _, ok := g.admit("default/b", 0, now.Add(admissionTicketTTL+time.Second)); !ok {
+		t.Error("expected a gang that stopped coming back to lose its place")
+	}
+}
+
+func TestCustomScheduler_PermitGroupAdmission(t *testing.T) {
+	g1 := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 2}.Pods()
+	g2 := fixtures.GroupSpec{Name: "g2", Namespace: "default", Size: 2, MinAvailable: 2, Labels: map[string]string{groupPriorityLabel: "100"}}.Pods()
+	cs := &CustomScheduler{
+		clock:          testingclock.NewFakeClock(time.Now()),
+		pods:           &faultyPodLister{stale: append(g1, g2...)},
+		nodes:          fakeframework.NodeInfoLister{makeNodeInfo("n1", 1000, 1000)},
+		groupAdmission: true,
+	}
+
+	if status, _ := cs.Permit(context.Background(), nil, g1[0], "n1"); !status.IsWait() {
+		t.Fatalf("expected the first gang to wait for its members, got %v", status)
+	}
+	if status, _ := cs.Permit(context.Background(), nil, g2[0], "n1"); status.Code() != framework.Unschedulable {
+		t.Fatalf("expected the second gang to be turned away while the first waits, got %v", status)
+	}
+	if status, _ := cs.Permit(context.Background(), nil, g1[1], "n1"); !status.IsWait() {
+		t.Fatalf("expected the admitted gang to keep waiting, got %v", status)
+	}
+
+	// the first gang is rejected, say on timeout, and unreserved.
+	cs.gangAdmission.release("default/g1")
+	if status, _ := cs.Permit(context.Background(), nil, g1[0], "n1"); status.Code() != framework.Unschedulable {
+		t.Fatalf("expected the higher-priority gang to go first, got %v", status)
+	}
+	if status, _ := cs.Permit(context.Background(), nil, g2[0], "n1"); !status.IsWait() {
+		t.Fatalf("expected the higher-priority gang to be admitted, got %v", status)
+	}
+}
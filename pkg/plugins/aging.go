@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+)
+
+// AgingArgs configures a priority boost for pods that have waited long, so
+// large gangs are not starved by a stream of small pods. The boost grows
+// with time while the scheduling queue is a heap ordered as pods are added
+// and popped, so the order is approximate: a pod overtakes the pods it now
+// outranks once it, or they, next move through the queue, e.g. when
+// requeued after a failed attempt.
+type AgingArgs struct {
+	// AfterSeconds is how long a pod waits before it is boosted, 600 by default.
+	AfterSeconds int64 `json:"afterSeconds,omitempty"`
+	// IntervalSeconds is how often the boost grows after that, 60 by default.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+	// Boost is added to the priority of a pod in QueueSort once it is aged
+	// and again every interval, 10 by default.
+	Boost int64 `json:"boost,omitempty"`
+	// MaxBoost caps the boost, 1000 by default, so aged pods never overtake
+	// pods of much higher priority such as system-critical ones.
+	MaxBoost int64 `json:"maxBoost,omitempty"`
+	// RelaxTopology lets aged gangs fall back to any rack, treating a
+	// Required rack topology as Preferred.
+	RelaxTopology bool `json:"relaxTopology,omitempty"`
+}
+
+func newAgingArgs(args AgingArgs) (*AgingArgs, error) {
+	if args.AfterSeconds == 0 {
+		args.AfterSeconds = 600
+	}
+	if args.IntervalSeconds == 0 {
+		args.IntervalSeconds = 60
+	}
+	if args.Boost == 0 {
+		args.Boost = 10
+	}
+	if args.MaxBoost == 0 {
+		args.MaxBoost = 1000
+	}
+	if args.AfterSeconds < 0 || args.IntervalSeconds < 0 || args.Boost < 0 || args.MaxBoost < 0 {
+		return nil, fmt.Errorf("aging must not be negative, got %+v", args)
+	}
+	return &args, nil
+}
+
+// agingBoost returns the priority boost of pod: nothing until it has waited
+// AfterSeconds since it was created, then Boost for every started interval,
+// up to MaxBoost.
+func (cs *CustomScheduler) agingBoost(pod *v1.Pod) int64 {
+	if cs.aging == nil || pod.CreationTimestamp.IsZero() {
+		return 0
+	}
+	waited := cs.clock.Since(pod.CreationTimestamp.Time) - time.Duration(cs.aging.AfterSeconds)*time.Second
+	if waited < 0 {
+		return 0
+	}
+	boost := (int64(waited/(time.Duration(cs.aging.IntervalSeconds)*time.Second)) + 1) * cs.aging.Boost
+	if boost > cs.aging.MaxBoost || boost < 0 {
+		return cs.aging.MaxBoost
+	}
+	return boost
+}
+
+// agedPriority is the priority of pod in QueueSort.
+func (cs *CustomScheduler) agedPriority(pod *v1.Pod) int64 {
+	return int64(corev1helpers.PodPriority(pod)) + cs.agingBoost(pod)
+}
+
+// topologyRelaxed reports whether pod has waited long enough to be placed
+// in any rack.
+func (cs *CustomScheduler) topologyRelaxed(pod *v1.Pod) bool {
+	return cs.aging != nil && cs.aging.RelaxTopology && cs.agingBoost(pod) > 0
+}
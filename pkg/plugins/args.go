@@ -0,0 +1,147 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// SchemeGroupVersion is the group and version of CustomSchedulerArgsV1beta3,
+// those of a v1beta3 KubeSchedulerConfiguration.
+var SchemeGroupVersion = schema.GroupVersion{Group: "kubescheduler.config.k8s.io", Version: "v1beta3"}
+
+var (
+	// SchemeBuilder registers CustomSchedulerArgs and its versions.
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes, addVersionedTypes)
+	// AddToScheme adds CustomSchedulerArgs and its versions to a scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+	// Scheme is a scheme CustomSchedulerArgs is registered in.
+	Scheme = runtime.NewScheme()
+)
+
+func init() {
+	if err := AddToScheme(Scheme); err != nil {
+		panic(err)
+	}
+}
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(internalGroupVersion, &CustomSchedulerArgs{})
+	return nil
+}
+
+// DeepCopyObject implements runtime.Object. The args are plain data, so a
+// JSON round trip copies them.
+func (args *CustomSchedulerArgs) DeepCopyObject() runtime.Object {
+	if args == nil {
+		return nil
+	}
+	raw, err := json.Marshal(args)
+	if err != nil {
+		panic(fmt.Sprintf("failed to copy %s args: %v", Name, err))
+	}
+	out := &CustomSchedulerArgs{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		panic(fmt.Sprintf("failed to copy %s args: %v", Name, err))
+	}
+	return out
+}
+
+// decodeArgs returns the args LoadArgs has no version for: obj itself if it
+// is typed, otherwise decoded from the runtime.Unknown the scheduler hands
+// out-of-tree plugins.
+// Unknown fields are rejected rather than silently ignored. Without args,
+// the plugin runs in the Least mode.
+func decodeArgs(obj runtime.Object) (*CustomSchedulerArgs, error) {
+	if obj == nil {
+		return &CustomSchedulerArgs{Mode: leastMode}, nil
+	}
+	if args, ok := obj.(*CustomSchedulerArgs); ok {
+		return args, nil
+	}
+	args := &CustomSchedulerArgs{}
+	if err := frameworkruntime.DecodeInto(obj, args); err != nil {
+		if _, ok := obj.(*runtime.Unknown); !ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to decode %s args: %w", Name, err)
+	}
+	if err := yaml.UnmarshalStrict(obj.(*runtime.Unknown).Raw, &CustomSchedulerArgs{}); err != nil {
+		return nil, fmt.Errorf("failed to decode %s args: %w", Name, err)
+	}
+	return args, nil
+}
+
+// ValidateCustomSchedulerArgs validates the scalar fields of args; the
+// nested integrations validate theirs as New sets them up.
+func ValidateCustomSchedulerArgs(path *field.Path, args *CustomSchedulerArgs) error {
+	var errs field.ErrorList
+	if !validMode(args.Mode) && (args.Mode != "" || len(args.ScoreResources) == 0) {
+		errs = append(errs, field.NotSupported(path.Child("mode"), args.Mode, []string{leastMode, mostMode, leastCPUMode, mostCPUMode, consolidationMode, balancedMode, numaMode, weightedMode, realLeastMode, realMostMode}))
+	}
+	if args.Mode == weightedMode && len(args.ScoreResources) == 0 {
+		errs = append(errs, field.Required(path.Child("scoreResources"), "the Weighted mode scores the resources it lists"))
+	}
+	if (args.Mode == realLeastMode || args.Mode == realMostMode) && args.MetricsProvider == nil {
+		errs = append(errs, field.Required(path.Child("metricsProvider"), fmt.Sprintf("the %s mode reads the usage of nodes from it", args.Mode)))
+	}
+	if args.Resource != "" {
+		for _, msg := range validation.IsQualifiedName(string(args.Resource)) {
+			errs = append(errs, field.Invalid(path.Child("resource"), string(args.Resource), msg))
+		}
+	}
+	if err := validateResourceStrategies(args.ScoreResources); err != nil {
+		errs = append(errs, field.Invalid(path.Child("scoreResources"), args.ScoreResources, err.Error()))
+	}
+	if args.MaxMinAvailable < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxMinAvailable"), args.MaxMinAvailable, "must not be negative"))
+	}
+	if !validConflictPolicy(args.MinAvailableConflictPolicy) {
+		errs = append(errs, field.NotSupported(path.Child("minAvailableConflictPolicy"), args.MinAvailableConflictPolicy, []string{conflictMax, conflictPodGroup, conflictReject}))
+	}
+	if err := validateScoreRange(args.ScoreRange); err != nil {
+		errs = append(errs, field.Invalid(path.Child("scoreRange"), args.ScoreRange, err.Error()))
+	}
+	lo, hi := int64(framework.MinNodeScore), int64(framework.MaxNodeScore)
+	if r := args.ScoreRange; r != nil && validateScoreRange(r) == nil {
+		lo, hi = r.Min, r.Max
+	}
+	if t := args.TieScore; t != nil && (*t < lo || *t > hi) {
+		errs = append(errs, field.Invalid(path.Child("tieScore"), *t, fmt.Sprintf("must be between %d and %d", lo, hi)))
+	}
+	if args.ImageLocalityWeight < 0 || args.ImageLocalityWeight > framework.MaxNodeScore {
+		errs = append(errs, field.Invalid(path.Child("imageLocalityWeight"), args.ImageLocalityWeight, fmt.Sprintf("must be between 0 and %d", framework.MaxNodeScore)))
+	}
+	if !validQueueOrder(args.QueueOrder) {
+		errs = append(errs, field.NotSupported(path.Child("queueOrder"), args.QueueOrder, []string{queueOrderPriority, queueOrderEarliestDeadlineFirst, queueOrderGroup}))
+	}
+	if err := validateResourceWeights(args.BalancedResources); err != nil {
+		errs = append(errs, field.Invalid(path.Child("balancedResources"), args.BalancedResources, err.Error()))
+	}
+	if err := validateResourceWeights(args.ExtendedResources); err != nil {
+		errs = append(errs, field.Invalid(path.Child("extendedResources"), args.ExtendedResources, err.Error()))
+	}
+	if err := validateFreeResourceThresholds(args.FreeResourceThresholds); err != nil {
+		errs = append(errs, field.Invalid(path.Child("freeResourceThresholds"), args.FreeResourceThresholds, err.Error()))
+	}
+	if args.PermitTimeoutSeconds < 0 || args.PermitTimeoutSeconds > int64(maxPermitTimeout/time.Second) {
+		errs = append(errs, field.Invalid(path.Child("permitTimeoutSeconds"), args.PermitTimeoutSeconds, fmt.Sprintf("must be between 0 and %d", int(maxPermitTimeout.Seconds()))))
+	}
+	if _, err := newFailurePolicies(args.FailurePolicies); err != nil {
+		errs = append(errs, field.Invalid(path.Child("failurePolicies"), args.FailurePolicies, err.Error()))
+	}
+	if args.StartWindowTimeZone != "" {
+		if _, err := time.LoadLocation(args.StartWindowTimeZone); err != nil {
+			errs = append(errs, field.Invalid(path.Child("startWindowTimeZone"), args.StartWindowTimeZone, err.Error()))
+		}
+	}
+	return errs.ToAggregate()
+}
@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestNew_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     runtime.Object
+		wantErr string
+	}{
+		{name: "malformed JSON", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least"`)}, wantErr: "failed to decode"},
+		{name: "wrong field type", obj: &runtime.Unknown{Raw: []byte(`{"mode": 1}`)}, wantErr: "failed to decode"},
+		{name: "unknown mode", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Random"}`)}, wantErr: `mode: Unsupported value: "Random"`},
+		{name: "tie score out of range", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "tieScore": 101}`)}, wantErr: "tieScore: Invalid value: 101"},
+		{name: "tie score outside the score range", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "scoreRange": {"min": 10, "max": 50}, "tieScore": 60}`)}, wantErr: "tieScore: Invalid value: 60"},
+		{name: "empty score range", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "scoreRange": {"min": 50, "max": 50}}`)}, wantErr: "scoreRange: Invalid value"},
+		{name: "unknown failure policy", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "failurePolicies": {"kueue": "Retry"}}`)}, wantErr: "invalid failure policy"},
+		{name: "unknown queue order", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "queueOrder": "Random"}`)}, wantErr: `queueOrder: Unsupported value: "Random"`},
+		{name: "weighted mode without resources", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Weighted"}`)}, wantErr: "scoreResources: Required value"},
+		{name: "real usage mode without a metrics provider", obj: &runtime.Unknown{Raw: []byte(`{"mode": "RealMost"}`)}, wantErr: "metricsProvider: Required value"},
+		{name: "invalid resource", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "resource": "gpu count"}`)}, wantErr: `resource: Invalid value: "gpu count"`},
+		{name: "image locality weight out of range", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "imageLocalityWeight": 101}`)}, wantErr: "imageLocalityWeight: Invalid value: 101"},
+		{name: "resource weight too large", obj: &runtime.Unknown{Raw: []byte(`{"scoreResources": [{"name": "cpu", "weight": 10000000, "strategy": "Most"}]}`)}, wantErr: "weight of resource cpu must be between 1 and 1000000"},
+		{name: "unknown resource strategy", obj: &runtime.Unknown{Raw: []byte(`{"scoreResources": [{"name": "cpu", "weight": 1, "strategy": "Balanced"}]}`)}, wantErr: "strategy of resource cpu must be Least or Most"},
+		{name: "unknown field", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Least", "scoreMode": "Most"}`)}, wantErr: `unknown field "scoreMode"`},
+		{name: "typed args", obj: &CustomSchedulerArgs{Mode: "Random"}, wantErr: `mode: Unsupported value: "Random"`},
+		{name: "wrong args type", obj: &v1.Pod{}, wantErr: "want args of type runtime.Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.obj, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateCustomSchedulerArgs(t *testing.T) {
+	tieScore := int64(-1)
+	err := ValidateCustomSchedulerArgs(field.NewPath("args"), &CustomSchedulerArgs{Mode: "Random", TieScore: &tieScore, PermitTimeoutSeconds: 901})
+	if err == nil {
+		t.Fatal("expected invalid args to be rejected")
+	}
+	for _, want := range []string{"args.mode", "args.tieScore", "args.permitTimeoutSeconds"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error about %s, got %v", want, err)
+		}
+	}
+	if err := ValidateCustomSchedulerArgs(nil, &CustomSchedulerArgs{Mode: mostMode}); err != nil {
+		t.Errorf("expected valid args, got %v", err)
+	}
+	// seconds this large overflow a time.Duration.
+	if err := ValidateCustomSchedulerArgs(nil, &CustomSchedulerArgs{Mode: mostMode, PermitTimeoutSeconds: math.MaxInt64}); err == nil || !strings.Contains(err.Error(), "permitTimeoutSeconds") {
+		t.Errorf("expected an error about permitTimeoutSeconds, got %v", err)
+	}
+	weighted := &CustomSchedulerArgs{ScoreResources: []ResourceStrategy{{Name: v1.ResourceMemory, Weight: 2, Strategy: leastMode}}}
+	if err := ValidateCustomSchedulerArgs(nil, weighted); err != nil {
+		t.Errorf("expected scoreResources to imply the Weighted mode, got %v", err)
+	}
+}
+
+func TestCustomSchedulerArgs_Scheme(t *testing.T) {
+	gvks, _, err := Scheme.ObjectKinds(&CustomSchedulerArgs{})
+	if err != nil || len(gvks) != 1 || gvks[0].Kind != "CustomSchedulerArgs" {
+		t.Fatalf("expected CustomSchedulerArgs to be registered, got %v: %v", gvks, err)
+	}
+	tieScore := int64(10)
+	args := &CustomSchedulerArgs{Mode: mostMode, TieScore: &tieScore, FailurePolicies: map[string]string{integrationKueue: failurePolicyFail}}
+	clone := args.DeepCopyObject().(*CustomSchedulerArgs)
+	*clone.TieScore = 20
+	clone.FailurePolicies[integrationKueue] = failurePolicyIgnore
+	if *args.TieScore != 10 || args.FailurePolicies[integrationKueue] != failurePolicyFail || clone.Mode != mostMode {
+		t.Errorf("expected a deep copy, got %+v from %+v", clone, args)
+	}
+}
@@ -0,0 +1,106 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// balancedMode prefers nodes whose resources would be equally utilized with
+// the pod on them, like the upstream NodeResourcesBalancedAllocation.
+const balancedMode string = "Balanced"
+
+// ResourceWeight weighs a resource, such as cpu, memory or nvidia.com/gpu.
+type ResourceWeight struct {
+	Name   v1.ResourceName `json:"name"`
+	Weight int64           `json:"weight"`
+}
+
+// defaultBalancedResources are balanced unless CustomSchedulerArgs.BalancedResources is set.
+var defaultBalancedResources = []ResourceWeight{{Name: v1.ResourceCPU, Weight: 1}, {Name: v1.ResourceMemory, Weight: 1}}
+
+func validateResourceWeights(weights []ResourceWeight) error {
+	seen := map[v1.ResourceName]bool{}
+	for _, w := range weights {
+		if w.Name == "" {
+			return fmt.Errorf("resource name must not be empty")
+		}
+		if seen[w.Name] {
+			return fmt.Errorf("resource %s is listed twice", w.Name)
+		}
+		seen[w.Name] = true
+		if w.Weight <= 0 {
+			return fmt.Errorf("weight of resource %s must be positive, got %d", w.Name, w.Weight)
+		}
+	}
+	return nil
+}
+
+// resourceAmount returns the amount of name in r, CPU in millicores.
+func resourceAmount(r *framework.Resource, name v1.ResourceName) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return r.MilliCPU
+	case v1.ResourceMemory:
+		return r.Memory
+	case v1.ResourceEphemeralStorage:
+		return r.EphemeralStorage
+	case v1.ResourcePods:
+		return int64(r.AllowedPodNumber)
+	}
+	return r.ScalarResources[name]
+}
+
+// requestAmount returns the amount of name in requests, CPU in millicores.
+func requestAmount(requests v1.ResourceList, name v1.ResourceName) int64 {
+	quantity, ok := requests[name]
+	if !ok {
+		return 0
+	}
+	if name == v1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+// balancedScore scores, in permille, how evenly the weighted resources of
+// nodeInfo would be utilized with pod on it: one less the weighted standard
+// deviation of the utilized fractions. Resources the node has none of are
+// left out.
+func (cs *CustomScheduler) balancedScore(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	weights := cs.balancedResources
+	if len(weights) == 0 {
+		weights = defaultBalancedResources
+	}
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	var fractions []float64
+	var fractionWeights []float64
+	var totalWeight, mean float64
+	for _, w := range weights {
+		allocatable := resourceAmount(nodeInfo.Allocatable, w.Name)
+		if allocatable <= 0 {
+			continue
+		}
+		fraction := float64(resourceAmount(nodeInfo.Requested, w.Name)+requestAmount(requests, w.Name)) / float64(allocatable)
+		if fraction > 1 {
+			fraction = 1
+		}
+		fractions = append(fractions, fraction)
+		fractionWeights = append(fractionWeights, float64(w.Weight))
+		totalWeight += float64(w.Weight)
+		mean += fraction * float64(w.Weight)
+	}
+	if totalWeight == 0 {
+		return 1000
+	}
+	mean /= totalWeight
+	var variance float64
+	for i, f := range fractions {
+		variance += fractionWeights[i] * (f - mean) * (f - mean)
+	}
+	variance /= totalWeight
+	return int64((1 - math.Sqrt(variance)) * 1000)
+}
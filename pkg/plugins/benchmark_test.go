@@ -0,0 +1,153 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+// benchmarkClusters are the sizes of the synthetic clusters the benchmarks
+// run against. Run them with go test -bench . -run ^$ ./pkg/plugins/.
+var benchmarkClusters = []struct {
+	nodes, pods int
+}{
+	{nodes: 1000, pods: 10000},
+	{nodes: 5000, pods: 10000},
+}
+
+// benchmarkGroupSize is the size, and minAvailable, of the gangs of the
+// synthetic clusters.
+const benchmarkGroupSize = 10
+
+// newBenchmarkCluster returns a plugin in mode over a synthetic snapshot of
+// nodes nodes and pods gang members in the pod cache, half of them bound
+// round-robin, and a pending member of the first gang to schedule. With
+// indexed, the plugin looks gangs up in the pod cache by index, as it does
+// once created by New, rather than by listing their namespace.
+func newBenchmarkCluster(b *testing.B, mode string, nodes, pods int, indexed bool) (*CustomScheduler, *v1.Pod) {
+	b.Helper()
+	nodeInfos := make([]*framework.NodeInfo, nodes)
+	for i := range nodeInfos {
+		// vary the allocatable memory so that the score modes rank nodes.
+		nodeInfos[i] = plugintesting.MakeNodeInfo(fmt.Sprintf("node-%d", i), 64000, int64(128+i%128)<<30)
+	}
+	var members []*v1.Pod
+	for i := 0; len(members) < pods; i++ {
+		group := fixtures.GroupSpec{
+			Name: fmt.Sprintf("group-%d", i), Namespace: "default", Size: benchmarkGroupSize, MinAvailable: benchmarkGroupSize,
+			Shape: fixtures.Shape{CPU: "100m", Memory: "128Mi"},
+		}.Pods()
+		for j, p := range group {
+			p.UID = types.UID(p.Name)
+			if j >= benchmarkGroupSize/2 {
+				p.Spec.NodeName = nodeInfos[len(members)%nodes].Node().Name
+				nodeInfos[len(members)%nodes].AddPod(p)
+			}
+			members = append(members, p)
+		}
+	}
+	fwk, err := plugintesting.NewFramework(nodeInfos, members)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cs := &CustomScheduler{handle: fwk, scoreMode: mode}
+	if indexed {
+		informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Pods().Informer()
+		if cs.podIndexer, err = indexPodsByGroup(informer); err != nil {
+			b.Fatal(err)
+		}
+		for _, p := range members {
+			if err := cs.podIndexer.Add(p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return cs, members[0]
+}
+
+func BenchmarkCustomScheduler_PreFilter(b *testing.B) {
+	for _, indexed := range []bool{false, true} {
+		lookup := "list"
+		if indexed {
+			lookup = "index"
+		}
+		for _, c := range benchmarkClusters {
+			b.Run(fmt.Sprintf("%s/%dnodes-%dpods", lookup, c.nodes, c.pods), func(b *testing.B) {
+				cs, pod := newBenchmarkCluster(b, leastMode, c.nodes, c.pods, indexed)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pod); !status.IsSuccess() {
+						b.Fatal(status.AsError())
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkCustomScheduler_Score(b *testing.B) {
+	for _, mode := range []string{leastMode, mostMode} {
+		for _, c := range benchmarkClusters {
+			b.Run(fmt.Sprintf("%s/%dnodes-%dpods", mode, c.nodes, c.pods), func(b *testing.B) {
+				cs, pod := newBenchmarkCluster(b, mode, c.nodes, c.pods, true)
+				nodeInfos, err := cs.nodeInfos().List()
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				// one iteration scores every node, as a scheduling cycle does.
+				for i := 0; i < b.N; i++ {
+					state := framework.NewCycleState()
+					if status := cs.PreScore(context.Background(), state, pod, nil); !status.IsSuccess() {
+						b.Fatal(status.AsError())
+					}
+					for _, ni := range nodeInfos {
+						if _, status := cs.Score(context.Background(), state, pod, ni.Node().Name); !status.IsSuccess() {
+							b.Fatal(status.AsError())
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkCustomScheduler_NormalizeScore(b *testing.B) {
+	for _, c := range benchmarkClusters {
+		b.Run(fmt.Sprintf("%dnodes-%dpods", c.nodes, c.pods), func(b *testing.B) {
+			cs, pod := newBenchmarkCluster(b, leastMode, c.nodes, c.pods, true)
+			nodeInfos, err := cs.nodeInfos().List()
+			if err != nil {
+				b.Fatal(err)
+			}
+			state := framework.NewCycleState()
+			raw := make(framework.NodeScoreList, len(nodeInfos))
+			for i, ni := range nodeInfos {
+				score, status := cs.Score(context.Background(), state, pod, ni.Node().Name)
+				if !status.IsSuccess() {
+					b.Fatal(status.AsError())
+				}
+				raw[i] = framework.NodeScore{Name: ni.Node().Name, Score: score}
+			}
+			scores := make(framework.NodeScoreList, len(raw))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				copy(scores, raw)
+				if status := cs.NormalizeScore(context.Background(), state, pod, scores); !status.IsSuccess() {
+					b.Fatal(status.AsError())
+				}
+			}
+		})
+	}
+}
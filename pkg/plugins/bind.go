@@ -0,0 +1,132 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// bindBatch collects the members of a gang that reached Bind until there
+// are enough of them to bind together.
+type bindBatch struct {
+	pods  map[string]*v1.Pod
+	nodes map[string]string
+	// done is closed once the batch was bound, with the error of each
+	// member that failed to bind in errs.
+	done chan struct{}
+	errs map[string]error
+}
+
+// gangBindings are the batches of the gangs being bound, by namespace/name
+// of the group.
+type gangBindings struct {
+	mu      sync.Mutex
+	batches map[string]*bindBatch
+}
+
+// join adds pod, bound to nodeName, to the batch of the gang key and
+// returns how many members the batch is missing. Once it has need members
+// it is missing none and is removed to be bound.
+func (g *gangBindings) join(key string, pod *v1.Pod, nodeName string, need int) (batch *bindBatch, missing int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.batches == nil {
+		g.batches = map[string]*bindBatch{}
+	}
+	batch = g.batches[key]
+	if batch == nil {
+		batch = &bindBatch{pods: map[string]*v1.Pod{}, nodes: map[string]string{}, done: make(chan struct{})}
+		g.batches[key] = batch
+	}
+	batch.pods[podKey(pod)] = pod
+	batch.nodes[podKey(pod)] = nodeName
+	if missing = need - len(batch.pods); missing > 0 {
+		return batch, missing
+	}
+	delete(g.batches, key)
+	return batch, 0
+}
+
+// leave removes pod from the batch of the gang key, reporting false if the
+// batch is already being bound, in which case pod is bound with it.
+func (g *gangBindings) leave(key string, batch *bindBatch, pod *v1.Pod) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.batches[key] != batch {
+		return false
+	}
+	delete(batch.pods, podKey(pod))
+	delete(batch.nodes, podKey(pod))
+	if len(batch.pods) == 0 {
+		delete(g.batches, key)
+	}
+	return true
+}
+
+// Bind binds the members of a gang only once minAvailable of them, counting
+// those already bound, have reached Bind, and then all of them at once, so
+// a gang never binds partially even in profiles without the Permit
+// extension point. Other pods, and all pods unless
+// CustomSchedulerArgs.GroupBinding is set, are left to the next binder.
+func (cs *CustomScheduler) Bind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	if !cs.groupBinding || cs.isUngrouped(pod) {
+		return framework.NewStatus(framework.Skip)
+	}
+	group, members, status := cs.groupAndMembers(state, pod)
+	if !status.IsSuccess() {
+		return status
+	}
+	bound := 0
+	for _, p := range members {
+		if p.Spec.NodeName != "" && podKey(p) != podKey(pod) {
+			bound++
+		}
+	}
+	key := group.key()
+	batch, missing := cs.gangBindings.join(key, pod, nodeName, group.minAvailable-bound)
+	if missing == 0 {
+		cs.bindBatch(ctx, group, batch)
+	} else {
+		cs.logger().V(2).Info("Pod waits in Bind for more members of its pod group", "pod", klog.KObj(pod), "group", group.name, "missing", missing)
+		timeout := cs.clock.NewTimer(cs.permitTimeout(group))
+		defer timeout.Stop()
+		select {
+		case <-batch.done:
+		case <-timeout.C():
+			if cs.gangBindings.leave(key, batch, pod) {
+				return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("timed out binding with the rest of pod group %s", group.name))
+			}
+			<-batch.done
+		case <-ctx.Done():
+			if cs.gangBindings.leave(key, batch, pod) {
+				return framework.AsStatus(ctx.Err())
+			}
+			<-batch.done
+		}
+	}
+	if err := batch.errs[podKey(pod)]; err != nil {
+		return framework.AsStatus(err)
+	}
+	return nil
+}
+
+// bindBatch binds the members in batch and then releases their Bind calls.
+func (cs *CustomScheduler) bindBatch(ctx context.Context, group *podGroup, batch *bindBatch) {
+	defer close(batch.done)
+	batch.errs = map[string]error{}
+	for k, p := range batch.pods {
+		binding := &v1.Binding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name, UID: p.UID},
+			Target:     v1.ObjectReference{Kind: "Node", Name: batch.nodes[k]},
+		}
+		if err := cs.handle.ClientSet().CoreV1().Pods(p.Namespace).Bind(ctx, binding, metav1.CreateOptions{}); err != nil {
+			batch.errs[k] = fmt.Errorf("failed to bind member %s of pod group %s: %w", p.Name, group.name, err)
+		}
+	}
+	cs.logger().V(2).Info("Bound the members of a pod group", "group", group.name, "members", len(batch.pods), "failed", len(batch.errs))
+}
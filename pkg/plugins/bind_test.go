@@ -0,0 +1,104 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func bindings(fwk *plugintesting.Framework) []string {
+	var bound []string
+	for _, a := range fwk.Client.Actions() {
+		if create, ok := a.(clienttesting.CreateAction); ok && a.GetSubresource() == "binding" {
+			bound = append(bound, create.GetObject().(*v1.Binding).Name)
+		}
+	}
+	return bound
+}
+
+func TestCustomScheduler_BindGroupAtomically(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 2}.Pods()
+	fwk, err := plugintesting.NewFramework(nil, pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &CustomScheduler{handle: fwk, groupBinding: true, clock: testingclock.NewFakeClock(time.Now())}
+
+	first := make(chan *framework.Status)
+	go func() {
+		first <- cs.Bind(context.Background(), framework.NewCycleState(), pods[0], "n1")
+	}()
+	select {
+	case status := <-first:
+		t.Fatalf("expected the first member to wait for the second, got %v", status)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if bound := bindings(fwk); len(bound) != 0 {
+		t.Fatalf("expected no member bound before minAvailable reached Bind, got %v", bound)
+	}
+
+	if status := cs.Bind(context.Background(), framework.NewCycleState(), pods[1], "n2"); !status.IsSuccess() {
+		t.Fatalf("expected the second member to bind, got %v", status)
+	}
+	if status := <-first; !status.IsSuccess() {
+		t.Fatalf("expected the first member to bind with the second, got %v", status)
+	}
+	if bound := bindings(fwk); len(bound) != 2 {
+		t.Fatalf("expected both members bound together, got %v", bound)
+	}
+}
+
+func TestCustomScheduler_BindTimeout(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 2}.Pods()
+	fwk, err := plugintesting.NewFramework(nil, pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := testingclock.NewFakeClock(time.Now())
+	cs := &CustomScheduler{handle: fwk, groupBinding: true, clock: clock}
+
+	result := make(chan *framework.Status)
+	go func() {
+		result <- cs.Bind(context.Background(), framework.NewCycleState(), pods[0], "n1")
+	}()
+	for !clock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Step(defaultPermitTimeout)
+	if status := <-result; status.Code() != framework.Unschedulable {
+		t.Fatalf("expected the member to time out unschedulable, got %v", status)
+	}
+	if bound := bindings(fwk); len(bound) != 0 {
+		t.Errorf("expected no member bound, got %v", bound)
+	}
+	if n := len(cs.gangBindings.batches); n != 0 {
+		t.Errorf("expected the timed out member to leave its batch, got %d batches", n)
+	}
+}
+
+func TestCustomScheduler_BindSkips(t *testing.T) {
+	grouped := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 1, MinAvailable: 1}.Pods()[0]
+	ungrouped := &v1.Pod{}
+	for _, tt := range []struct {
+		name         string
+		groupBinding bool
+		pod          *v1.Pod
+	}{
+		{name: "group binding disabled", pod: grouped},
+		{name: "ungrouped pod", groupBinding: true, pod: ungrouped},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{groupBinding: tt.groupBinding}
+			if status := cs.Bind(context.Background(), framework.NewCycleState(), tt.pod, "n1"); status.Code() != framework.Skip {
+				t.Errorf("expected Skip, got %v", status)
+			}
+		})
+	}
+}
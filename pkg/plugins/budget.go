@@ -0,0 +1,151 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// maxPreemptibleLabel caps how many bound members of a pod group preemption
+// may evict at once. Whatever its value, preemption never leaves a group
+// with fewer bound members than its minAvailable.
+const maxPreemptibleLabel string = "maxPreemptible"
+
+const preemptionStateKey = framework.StateKey(Name + "/preemption")
+
+// preemptedGroup counts the members of a group a preemption dry run removed.
+type preemptedGroup struct {
+	group   *podGroup
+	removed map[string]bool
+	// maxPreemptible is the group's maxPreemptibleLabel, -1 if unset.
+	maxPreemptible int
+}
+
+// preemptionState tracks the group members removed from a node while the
+// framework simulates preemption, by namespace/name of the group.
+type preemptionState struct {
+	groups map[string]*preemptedGroup
+}
+
+func (s *preemptionState) Clone() framework.StateData {
+	clone := &preemptionState{groups: make(map[string]*preemptedGroup, len(s.groups))}
+	for key, g := range s.groups {
+		removed := make(map[string]bool, len(g.removed))
+		for pod := range g.removed {
+			removed[pod] = true
+		}
+		clone.groups[key] = &preemptedGroup{group: g.group, removed: removed, maxPreemptible: g.maxPreemptible}
+	}
+	return clone
+}
+
+// PreFilterExtensions returns the extensions the framework calls while it
+// simulates preemption.
+func (cs *CustomScheduler) PreFilterExtensions() framework.PreFilterExtensions {
+	return cs
+}
+
+// AddPod puts a pod back on a node during a preemption dry run, and back
+// into the group snapshot if it is a member.
+func (cs *CustomScheduler) AddPod(ctx context.Context, state *framework.CycleState, podToSchedule *v1.Pod, podInfoToAdd *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	addSnapshotMember(state, podInfoToAdd.Pod)
+	s := readPreemptionState(state)
+	if s == nil {
+		return nil
+	}
+	pod := podInfoToAdd.Pod
+	for _, g := range s.groups {
+		delete(g.removed, podKey(pod))
+	}
+	return nil
+}
+
+// RemovePod removes a pod from a node during a preemption dry run, and from
+// the group snapshot, and records it against the budget of its group.
+func (cs *CustomScheduler) RemovePod(ctx context.Context, state *framework.CycleState, podToSchedule *v1.Pod, podInfoToRemove *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	victim := podInfoToRemove.Pod
+	removeSnapshotMember(state, victim)
+	if cs.isUngrouped(victim) {
+		return nil
+	}
+	group, status := cs.groupOf(victim)
+	if !status.IsSuccess() {
+		return nil
+	}
+	s := readPreemptionState(state)
+	if s == nil {
+		s = &preemptionState{groups: map[string]*preemptedGroup{}}
+		state.Write(preemptionStateKey, s)
+	}
+	key := group.key()
+	g, ok := s.groups[key]
+	if !ok {
+		g = &preemptedGroup{group: group, removed: map[string]bool{}, maxPreemptible: maxPreemptible(victim)}
+		s.groups[key] = g
+	}
+	g.removed[podKey(victim)] = true
+	return nil
+}
+
+func readPreemptionState(state *framework.CycleState) *preemptionState {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(preemptionStateKey)
+	if err != nil {
+		return nil
+	}
+	return data.(*preemptionState)
+}
+
+// maxPreemptible returns the maxPreemptibleLabel of pod, -1 if it is unset or invalid.
+func maxPreemptible(pod *v1.Pod) int {
+	value, ok := pod.Labels[maxPreemptibleLabel]
+	if !ok {
+		return -1
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || len(value) > maxMinAvailableDigits {
+		return -1
+	}
+	return n
+}
+
+// filterPreemptionBudgets rejects a node during a preemption dry run if the
+// victims removed from it would exceed the preemption budget of their group.
+// The framework removes every lower-priority pod from a node before it
+// reprieves any, so a node whose full set of victims breaks a budget is not
+// a preemption candidate at all.
+func (cs *CustomScheduler) filterPreemptionBudgets(state *framework.CycleState) *framework.Status {
+	s := readPreemptionState(state)
+	if s == nil {
+		return nil
+	}
+	for _, g := range s.groups {
+		if len(g.removed) == 0 {
+			continue
+		}
+		members, err := cs.groupMembers(g.group)
+		if err != nil {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list pods: %v", err))
+		}
+		bound := 0
+		for _, p := range members {
+			if p.Spec.NodeName != "" {
+				bound++
+			}
+		}
+		budget := bound - g.group.minAvailable
+		if g.maxPreemptible >= 0 && g.maxPreemptible < budget {
+			budget = g.maxPreemptible
+		}
+		if len(g.removed) > budget {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				fmt.Sprintf("preempting %d members of pod group %s exceeds its preemption budget of %d", len(g.removed), g.group.name, nonNegative(int64(budget))))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func TestCustomScheduler_PreemptionBudget(t *testing.T) {
+	bound := func(labels map[string]string) []*v1.Pod {
+		pods := fixtures.GroupSpec{Name: "g1", Size: 4, MinAvailable: 2, Labels: labels}.Pods()
+		for _, p := range pods {
+			p.Spec.NodeName = "n1"
+		}
+		return pods
+	}
+	tests := []struct {
+		name     string
+		members  []*v1.Pod
+		remove   int
+		reprieve int
+		want     framework.Code
+	}{
+		{name: "within minAvailable", members: bound(nil), remove: 2, want: framework.Success},
+		{name: "below minAvailable", members: bound(nil), remove: 3, want: framework.UnschedulableAndUnresolvable},
+		{name: "reprieved", members: bound(nil), remove: 3, reprieve: 1, want: framework.Success},
+		{name: "maxPreemptible", members: bound(map[string]string{maxPreemptibleLabel: "1"}), remove: 2, want: framework.UnschedulableAndUnresolvable},
+		{name: "no preemption", members: bound(map[string]string{maxPreemptibleLabel: "0"}), remove: 0, want: framework.Success},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: &faultyPodLister{stale: tt.members}}
+			nodeInfo := makeNodeInfo("n1", 1000, 100)
+			state := framework.NewCycleState()
+			preemptor := &v1.Pod{}
+			for _, p := range tt.members[:tt.remove] {
+				if status := cs.RemovePod(context.Background(), state, preemptor, &framework.PodInfo{Pod: p}, nodeInfo); !status.IsSuccess() {
+					t.Fatal(status)
+				}
+			}
+			// every node gets its own copy of the state.
+			clone := state.Clone()
+			for _, p := range tt.members[:tt.reprieve] {
+				if status := cs.AddPod(context.Background(), clone, preemptor, &framework.PodInfo{Pod: p}, nodeInfo); !status.IsSuccess() {
+					t.Fatal(status)
+				}
+			}
+			if got := cs.Filter(context.Background(), clone, preemptor, nodeInfo); got.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// cycleAborted returns an Error status once ctx is cancelled or past its
+// deadline, so a plugin stops working on a scheduling cycle nobody waits for
+// and never writes to the API server on its behalf. It returns nil otherwise.
+func cycleAborted(ctx context.Context) *framework.Status {
+	if ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	return framework.AsStatus(fmt.Errorf("scheduling cycle aborted: %w", ctx.Err()))
+}
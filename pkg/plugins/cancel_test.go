@@ -0,0 +1,55 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"my-scheduler-plugins/pkg/scorepolicy"
+)
+
+func TestCustomScheduler_CancelledCycle(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100), makeNodeInfo("m2", 1000, 200)}
+	nodes := []*v1.Node{nodeInfos[0].Node(), nodeInfos[1].Node()}
+	var policyCalls int
+	policy := scorePolicyFunc(func(ctx context.Context, req scorepolicy.Request) (map[string]int64, error) {
+		policyCalls++
+		return nil, ctx.Err()
+	})
+	cs := &CustomScheduler{scoreMode: leastMode, nodes: nodeInfos, scorePolicy: policy}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pod := &v1.Pod{}
+	state := framework.NewCycleState()
+
+	if _, status := cs.PreFilter(ctx, state, pod); status.Code() != framework.Error {
+		t.Errorf("PreFilter: expected Error, got %v", status)
+	}
+	if status := cs.PreScore(ctx, state, pod, nodes); status.Code() != framework.Error {
+		t.Errorf("PreScore: expected Error, got %v", status)
+	}
+	if policyCalls != 0 {
+		t.Errorf("expected no score policy calls, got %d", policyCalls)
+	}
+	if _, status := cs.Score(ctx, state, pod, "m1"); status.Code() != framework.Error {
+		t.Errorf("Score: expected Error, got %v", status)
+	}
+	if status := cs.NormalizeScore(ctx, state, pod, framework.NodeScoreList{{Name: "m1"}}); status.Code() != framework.Error {
+		t.Errorf("NormalizeScore: expected Error, got %v", status)
+	}
+}
+
+func TestCustomScheduler_ScorePolicyCancelledDuringCall(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100)}
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := scorePolicyFunc(func(ctx context.Context, req scorepolicy.Request) (map[string]int64, error) {
+		cancel()
+		return nil, ctx.Err()
+	})
+	cs := &CustomScheduler{scoreMode: leastMode, nodes: nodeInfos, scorePolicy: policy}
+	if status := cs.PreScore(ctx, framework.NewCycleState(), &v1.Pod{}, []*v1.Node{nodeInfos[0].Node()}); status.Code() != framework.Error {
+		t.Errorf("expected the aborted cycle not to fall back to the score mode, got %v", status)
+	}
+}
@@ -0,0 +1,209 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// capacityShortfallAnnotation holds the capacity a blocked gang is missing, as a JSON resource list.
+	capacityShortfallAnnotation string = "nthu.scheduler/capacity-shortfall"
+	// failedSchedulingReason is the event reason the scheduler and the Cluster Autoscaler use for unschedulable pods.
+	failedSchedulingReason string = "FailedScheduling"
+	// invalidPodGroupReason is the event reason for pods whose group is misconfigured.
+	invalidPodGroupReason string = "InvalidPodGroup"
+)
+
+// PostFilter runs when no node fits pod. With gang preemption enabled, it
+// first tries to make room for the gang of pod by evicting lower-priority
+// pods. Otherwise, if pod belongs to a gang whose pending members request
+// more than the free capacity of the whole cluster, it reports the missing
+// capacity so the Cluster Autoscaler can scale up by the right amount.
+func (cs *CustomScheduler) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	unschedulable := framework.NewStatus(framework.Unschedulable)
+	if cs.gangPreemption && !cs.isUngrouped(pod) {
+		if result, status := cs.preemptForGang(ctx, state, pod, filteredNodeStatusMap); result != nil || status != nil {
+			return result, status
+		}
+	}
+	if !cs.capacityHints {
+		return nil, unschedulable
+	}
+	group, status := cs.groupOf(pod)
+	if !status.IsSuccess() {
+		return nil, unschedulable
+	}
+	members, err := cs.groupMembers(group)
+	if err != nil || len(members) < group.minAvailable {
+		// the gang is waiting for members, not for capacity.
+		return nil, unschedulable
+	}
+	var pending []*v1.Pod
+	for _, p := range members {
+		if p.Spec.NodeName == "" {
+			pending = append(pending, p)
+		}
+	}
+	nodes, err := cs.nodeInfos().List()
+	if err != nil {
+		return nil, unschedulable
+	}
+	shortfall := capacityShortfall(pending, nodes)
+	if len(shortfall) == 0 {
+		return nil, unschedulable
+	}
+
+	// the cycle may have been aborted while summing up the cluster; its
+	// findings are stale, so do not publish them.
+	if status := cycleAborted(ctx); status != nil {
+		return nil, status
+	}
+	msg := fmt.Sprintf("pod group %s needs %s more than the free capacity of the cluster", group.name, formatResourceList(shortfall))
+	cs.recordEvent(pod, v1.EventTypeWarning, failedSchedulingReason, "Scheduling", msg)
+	if cs.capacityHintAnnotation {
+		if err := cs.annotateShortfall(ctx, pod, shortfall); err != nil {
+			cs.logger().Error(err, "Failed to annotate pod with its capacity shortfall", "pod", klog.KObj(pod))
+		}
+	}
+	if cs.karpenter != nil {
+		if err := cs.annotateProvisioningHint(ctx, pod, group, pending, shortfall); err != nil {
+			cs.logger().Error(err, "Failed to annotate pod with its provisioning hint", "pod", klog.KObj(pod))
+		}
+	}
+	return nil, framework.NewStatus(framework.Unschedulable, msg)
+}
+
+// checkGangCapacity rejects a gang early, in PreFilter, if the members it
+// is missing to reach minAvailable request more than the summed free
+// capacity of the nodes it may run on, rather than placing some of them
+// only for the gang to time out in Permit. Members on a node in the
+// snapshot, bound or assumed, are not missing; pod itself always is.
+func (cs *CustomScheduler) checkGangCapacity(pod *v1.Pod, group *podGroup, members []*v1.Pod) *framework.Status {
+	if !cs.capacityCheck {
+		return nil
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list node infos: %v", err))
+	}
+	placed := map[string]bool{}
+	var nodes []*framework.NodeInfo
+	for _, ni := range nodeInfos {
+		for _, pi := range ni.Pods {
+			placed[podKey(pi.Pod)] = true
+		}
+		if group.nodePool == "" || (ni.Node() != nil && cs.inNodePool(ni.Node(), group.nodePool)) {
+			nodes = append(nodes, ni)
+		}
+	}
+	assigned := 0
+	for _, p := range members {
+		if placed[podKey(p)] {
+			assigned++
+		}
+	}
+	missing := []*v1.Pod{pod}
+	for _, p := range members {
+		if len(missing) >= group.minAvailable-assigned {
+			break
+		}
+		if !placed[podKey(p)] && podKey(p) != podKey(pod) {
+			missing = append(missing, p)
+		}
+	}
+	shortfall := capacityShortfall(missing, nodes)
+	if len(shortfall) == 0 {
+		return nil
+	}
+	return framework.NewStatus(framework.Unschedulable,
+		fmt.Sprintf("pod group %s needs %s more than the free capacity of the cluster to place the %d members it is missing", group.name, formatResourceList(shortfall), len(missing)))
+}
+
+// capacityShortfall returns the resources pods request beyond the summed free
+// capacity of nodes, or an empty list if they fit in aggregate.
+func capacityShortfall(pods []*v1.Pod, nodes []*framework.NodeInfo) v1.ResourceList {
+	requested := &framework.Resource{}
+	for _, p := range pods {
+		requested.Add(resourcehelper.PodRequests(p, resourcehelper.PodResourcesOptions{}))
+	}
+	free := &framework.Resource{}
+	for _, n := range nodes {
+		free.MilliCPU += nonNegative(n.Allocatable.MilliCPU - n.Requested.MilliCPU)
+		free.Memory += nonNegative(n.Allocatable.Memory - n.Requested.Memory)
+		free.EphemeralStorage += nonNegative(n.Allocatable.EphemeralStorage - n.Requested.EphemeralStorage)
+		for name, quantity := range n.Allocatable.ScalarResources {
+			free.AddScalar(name, nonNegative(quantity-n.Requested.ScalarResources[name]))
+		}
+	}
+
+	shortfall := v1.ResourceList{}
+	if d := requested.MilliCPU - free.MilliCPU; d > 0 {
+		shortfall[v1.ResourceCPU] = *resource.NewMilliQuantity(d, resource.DecimalSI)
+	}
+	if d := requested.Memory - free.Memory; d > 0 {
+		shortfall[v1.ResourceMemory] = *resource.NewQuantity(d, resource.BinarySI)
+	}
+	if d := requested.EphemeralStorage - free.EphemeralStorage; d > 0 {
+		shortfall[v1.ResourceEphemeralStorage] = *resource.NewQuantity(d, resource.BinarySI)
+	}
+	for name, quantity := range requested.ScalarResources {
+		if d := quantity - free.ScalarResources[name]; d > 0 {
+			shortfall[name] = *resource.NewQuantity(d, resource.DecimalSI)
+		}
+	}
+	return shortfall
+}
+
+func nonNegative(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// formatResourceList renders rl as "cpu=2, memory=4Gi" in a stable order.
+func formatResourceList(rl v1.ResourceList) string {
+	var parts []string
+	for name, quantity := range rl {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func (cs *CustomScheduler) annotateShortfall(ctx context.Context, pod *v1.Pod, shortfall v1.ResourceList) error {
+	value, err := json.Marshal(shortfall)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{capacityShortfallAnnotation: string(value)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cs.handle.ClientSet().CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// recordEvent emits an event on obj if the framework provides a recorder.
+func (cs *CustomScheduler) recordEvent(obj runtime.Object, eventtype, reason, action, msg string) {
+	if cs.handle == nil || cs.handle.EventRecorder() == nil {
+		return
+	}
+	cs.handle.EventRecorder().Eventf(obj, nil, eventtype, reason, action, msg)
+}
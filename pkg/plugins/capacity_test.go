@@ -0,0 +1,149 @@
+package plugins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestCapacityShortfall(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Size: 3, MinAvailable: 3, Shape: fixtures.Shapes["small"]}.Pods()
+	busy := makeNodeInfo("busy", 1000, 512*1024*1024)
+	busy.Requested.Memory = 512 * 1024 * 1024
+
+	tests := []struct {
+		name  string
+		nodes []*framework.NodeInfo
+		want  v1.ResourceList
+	}{
+		{
+			name:  "fits in aggregate",
+			nodes: []*framework.NodeInfo{makeNodeInfo("n1", 200, 256*1024*1024), makeNodeInfo("n2", 200, 256*1024*1024)},
+			want:  v1.ResourceList{},
+		},
+		{
+			name:  "short on memory",
+			nodes: []*framework.NodeInfo{makeNodeInfo("n1", 1000, 256*1024*1024), busy},
+			want:  v1.ResourceList{v1.ResourceMemory: resource.MustParse("128Mi")},
+		},
+		{
+			name:  "no nodes",
+			nodes: nil,
+			want:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("300m"), v1.ResourceMemory: resource.MustParse("384Mi")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capacityShortfall(pods, tt.nodes)
+			if formatResourceList(got) != formatResourceList(tt.want) {
+				t.Errorf("expected %s, got %s", formatResourceList(tt.want), formatResourceList(got))
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_PostFilterCapacityHints(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3, Shape: fixtures.Shapes["small"]}.Pods()
+	client := clientsetfake.NewSimpleClientset(pods[0])
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	recorder := events.NewFakeRecorder(10)
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithEventRecorder(recorder),
+		frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: []*framework.NodeInfo{makeNodeInfo("n1", 1000, 256*1024*1024)}}),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	for _, p := range pods {
+		informerFactory.Core().V1().Pods().Informer().GetStore().Add(p)
+	}
+	cs := &CustomScheduler{handle: fh, scoreMode: leastMode, capacityHints: true, capacityHintAnnotation: true}
+
+	_, status := cs.PostFilter(context.Background(), framework.NewCycleState(), pods[0], nil)
+	if status.Code() != framework.Unschedulable {
+		t.Errorf("expected Unschedulable, got %v", status.Code())
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, failedSchedulingReason) || !strings.Contains(e, "memory=128Mi") {
+			t.Errorf("unexpected event %q", e)
+		}
+	default:
+		t.Errorf("expected a %s event", failedSchedulingReason)
+	}
+	got, err := client.CoreV1().Pods("default").Get(context.Background(), pods[0].Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Annotations[capacityShortfallAnnotation] != `{"memory":"128Mi"}` {
+		t.Errorf("unexpected annotation %q", got.Annotations[capacityShortfallAnnotation])
+	}
+}
+
+func TestCustomScheduler_PreFilterCapacityCheck(t *testing.T) {
+	gang := func(size, minAvailable int) []*v1.Pod {
+		return fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: size, MinAvailable: minAvailable, Shape: fixtures.Shape{CPU: "1", Memory: "1Gi"}}.Pods()
+	}
+	placed := func(pods []*v1.Pod, n int) []*v1.Pod {
+		for _, p := range pods[:n] {
+			p.Spec.NodeName = "n1"
+		}
+		return pods
+	}
+	for _, tt := range []struct {
+		name          string
+		pods          []*v1.Pod
+		capacityCheck bool
+		want          framework.Code
+	}{
+		{name: "minAvailable members fit", pods: gang(6, 4), capacityCheck: true, want: framework.Success},
+		{name: "minAvailable members do not fit", pods: gang(5, 5), capacityCheck: true, want: framework.Unschedulable},
+		{name: "placed members are not missing", pods: placed(gang(4, 4), 2), capacityCheck: true, want: framework.Success},
+		{name: "check disabled", pods: gang(5, 5), want: framework.Success},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// 4 CPUs in all, less what the placed members request.
+			n1 := plugintesting.MakeNodeInfo("n1", 2000, 8<<30)
+			n2 := plugintesting.MakeNodeInfo("n2", 2000, 8<<30)
+			var pending []*v1.Pod
+			for _, p := range tt.pods {
+				if p.Spec.NodeName != "" {
+					n1.AddPod(p)
+				} else {
+					pending = append(pending, p)
+				}
+			}
+			fwk, err := plugintesting.NewFramework([]*framework.NodeInfo{n1, n2}, tt.pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, capacityCheck: tt.capacityCheck}
+			if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pending[0]); status.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %s", tt.want, status.Code(), status.Message())
+			}
+		})
+	}
+}
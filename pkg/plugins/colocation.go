@@ -0,0 +1,90 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// GroupColocationArgs configures scoring nodes by the members of the gang
+// of a pod they already host, like inter-pod affinity to the gang.
+type GroupColocationArgs struct {
+	// TopologyKey is the node label whose nodes of equal value count as one
+	// domain, kubernetes.io/hostname by default; topology.kubernetes.io/zone
+	// co-locates or spreads gangs by zone.
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// Weight is the score bonus, 0 to 100, of the domain hosting the most
+	// members, others getting a share of it by how many they host. 30 by
+	// default.
+	Weight int64 `json:"weight,omitempty"`
+	// AntiAffinity spreads gangs instead, for fault tolerance: the bonus goes
+	// to the domains hosting the fewest members.
+	AntiAffinity bool `json:"antiAffinity,omitempty"`
+}
+
+func newGroupColocationArgs(args GroupColocationArgs) (*GroupColocationArgs, error) {
+	if args.TopologyKey == "" {
+		args.TopologyKey = v1.LabelHostname
+	}
+	if args.Weight == 0 {
+		args.Weight = 30
+	}
+	if args.Weight < 0 || args.Weight > framework.MaxNodeScore {
+		return nil, fmt.Errorf("group colocation weight must be between 0 and %d, got %d", framework.MaxNodeScore, args.Weight)
+	}
+	return &args, nil
+}
+
+// groupDomains counts the members of the gang of pod on the nodes of each
+// topology domain in the snapshot, bound or assumed.
+func (cs *CustomScheduler) groupDomains(state *framework.CycleState, pod *v1.Pod) (map[string]int, error) {
+	_, members, status := cs.groupAndMembers(state, pod)
+	if !status.IsSuccess() {
+		return nil, status.AsError()
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	domains := map[string]int{}
+	for _, ni := range nodeInfos {
+		domain, ok := ni.Node().Labels[cs.groupColocation.TopologyKey]
+		if !ok {
+			continue
+		}
+		for _, pi := range ni.Pods {
+			if isMember[podKey(pi.Pod)] && podKey(pi.Pod) != podKey(pod) {
+				domains[domain]++
+			}
+		}
+	}
+	return domains, nil
+}
+
+// groupColocationBonus is the score bonus of node given the members each
+// domain hosts: the weight scaled by its share of the most any domain
+// hosts, or with anti-affinity by the share it falls short of it. Nodes
+// outside every domain get no bonus, and neither do any before the first
+// member is placed.
+func (cs *CustomScheduler) groupColocationBonus(domains map[string]int, node *v1.Node) int64 {
+	most := 0
+	for _, n := range domains {
+		if n > most {
+			most = n
+		}
+	}
+	domain, ok := node.Labels[cs.groupColocation.TopologyKey]
+	if most == 0 || !ok {
+		return 0
+	}
+	hosted := int64(domains[domain])
+	if cs.groupColocation.AntiAffinity {
+		hosted = int64(most) - hosted
+	}
+	return cs.groupColocation.Weight * hosted / int64(most)
+}
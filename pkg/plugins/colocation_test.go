@@ -0,0 +1,65 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestCustomScheduler_GroupColocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		antiAffinity bool
+		want         map[string]int64
+	}{
+		// with equal raw scores every node starts at the neutral 50.
+		{name: "affinity", want: map[string]int64{"a1": 80, "a2": 80, "b1": 65, "c1": 50, "none": 50}},
+		{name: "anti-affinity", antiAffinity: true, want: map[string]int64{"a1": 50, "a2": 50, "b1": 65, "c1": 80, "none": 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 4, MinAvailable: 4}.Pods()
+			zoned := func(name, zone string, members ...*v1.Pod) *framework.NodeInfo {
+				ni := plugintesting.MakeNodeInfo(name, 1000, 1<<30, members...)
+				node := ni.Node()
+				if zone != "" {
+					node.Labels = map[string]string{v1.LabelTopologyZone: zone}
+				}
+				ni.SetNode(node)
+				return ni
+			}
+			nodes := []*framework.NodeInfo{
+				zoned("a1", "a", pods[0]),
+				zoned("a2", "a", pods[1]),
+				zoned("b1", "b", pods[2]),
+				zoned("c1", "c"),
+				zoned("none", ""),
+			}
+			fwk, err := plugintesting.NewFramework(nodes, pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			colocation, err := newGroupColocationArgs(GroupColocationArgs{TopologyKey: v1.LabelTopologyZone, AntiAffinity: tt.antiAffinity})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, groupColocation: colocation}
+			var scores framework.NodeScoreList
+			for _, ni := range nodes {
+				scores = append(scores, framework.NodeScore{Name: ni.Node().Name})
+			}
+			if status := cs.NormalizeScore(context.Background(), framework.NewCycleState(), pods[3], scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for _, s := range scores {
+				if s.Score != tt.want[s.Name] {
+					t.Errorf("expected %s to score %d, got %d", s.Name, tt.want[s.Name], s.Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !k8s128
+
+package plugins
+
+import "k8s.io/kubernetes/pkg/scheduler/framework"
+
+// frameworkMinorVersion is the k8s.io/kubernetes minor version the shims in
+// this file target. Build with -tags k8s128 for newer frameworks.
+const frameworkMinorVersion = 27
+
+// clusterEvent is the element type returned by EventsToRegister.
+type clusterEvent = framework.ClusterEvent
+
+// toClusterEvent adapts e to the EventsToRegister element type.
+func toClusterEvent(e framework.ClusterEvent) clusterEvent {
+	return e
+}
+
+// eventOf returns the event wrapped by e.
+func eventOf(e clusterEvent) framework.ClusterEvent {
+	return e
+}
@@ -0,0 +1,22 @@
+//go:build k8s128
+
+package plugins
+
+import "k8s.io/kubernetes/pkg/scheduler/framework"
+
+// frameworkMinorVersion is the k8s.io/kubernetes minor version the shims in
+// this file target. Since 1.28 EventsToRegister returns events with queueing hints.
+const frameworkMinorVersion = 28
+
+// clusterEvent is the element type returned by EventsToRegister.
+type clusterEvent = framework.ClusterEventWithHint
+
+// toClusterEvent adapts e to the EventsToRegister element type.
+func toClusterEvent(e framework.ClusterEvent) clusterEvent {
+	return framework.ClusterEventWithHint{Event: e}
+}
+
+// eventOf returns the event wrapped by e.
+func eventOf(e clusterEvent) framework.ClusterEvent {
+	return e.Event
+}
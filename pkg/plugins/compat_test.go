@@ -0,0 +1,15 @@
+package plugins
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestCompat_ClusterEvent(t *testing.T) {
+	t.Logf("running against scheduler framework 1.%d", frameworkMinorVersion)
+	e := framework.ClusterEvent{Resource: framework.Pod, ActionType: framework.Add}
+	if got := eventOf(toClusterEvent(e)); got != e {
+		t.Errorf("expected %v, got %v", e, got)
+	}
+}
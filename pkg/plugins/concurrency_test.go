@@ -0,0 +1,240 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+// TestCustomScheduler_Concurrent calls every extension point from many
+// goroutines at once, the way the framework does when it scores nodes in
+// parallel. Run it with -race to validate the plugin's shared state.
+func TestCustomScheduler_Concurrent(t *testing.T) {
+	const workers = 16
+	const iterations = 50
+
+	client := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	var nodes []*framework.NodeInfo
+	var nodeNames []string
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("n%d", i)
+		nodes = append(nodes, makeNodeInfo(name, 1000, int64(100*(i+1))))
+		nodeNames = append(nodeNames, name)
+	}
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: nodes}),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	p, err := New(nil, fh)
+	if err != nil {
+		t.Fatalf("fail to create plugin: %s", err)
+	}
+	cs := p.(*CustomScheduler)
+
+	store := informerFactory.Core().V1().Pods().Informer().GetStore()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ctx := context.Background()
+			for i := 0; i < iterations; i++ {
+				group := fmt.Sprintf("g%d", i%4)
+				pods := makeGroupPods(group, 2, 2)
+				pod := pods[w%2]
+				store.Add(pod)
+
+				if _, status := cs.PreFilter(ctx, framework.NewCycleState(), pod); status.Code() == framework.Error {
+					t.Errorf("unexpected PreFilter error: %v", status)
+				}
+				scores := make(framework.NodeScoreList, len(nodeNames))
+				for j, nodeName := range nodeNames {
+					score, status := cs.Score(ctx, framework.NewCycleState(), pod, nodeName)
+					if !status.IsSuccess() {
+						t.Errorf("unexpected Score error: %v", status)
+					}
+					scores[j] = framework.NodeScore{Name: nodeName, Score: score}
+				}
+				if status := cs.NormalizeScore(ctx, framework.NewCycleState(), pod, scores); !status.IsSuccess() {
+					t.Errorf("unexpected NormalizeScore error: %v", status)
+				}
+			}
+		}(w)
+	}
+	// swap the configuration while the extension points read it.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			cs.updateConfig(func(c *schedulerConfig) {
+				c.scoreMode = []string{leastMode, mostMode}[i%2]
+			})
+		}
+	}()
+	wg.Wait()
+}
+
+// TestNew_Profiles runs the plugin in two profiles of one scheduler with
+// opposite modes, each with its own rebalancer, state exporter and Lease,
+// which must not share any state and must stop with the scheduler.
+func TestNew_Profiles(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	nodes := []*framework.NodeInfo{makeNodeInfo("small", 1000, 100), makeNodeInfo("large", 1000, 200)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	freeAddress := func() string {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("fail to find a free address: %s", err)
+		}
+		defer listener.Close()
+		return listener.Addr().String()
+	}
+	// urls are the recommendations and the state of every profile.
+	urls := map[string][]string{}
+	var rebalanceAddress string
+	newFramework := func(profile string) framework.Handle {
+		fh, err := st.NewFramework(
+			[]st.RegisterPluginFunc{
+				st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			},
+			profile,
+			wait.NeverStop,
+			frameworkruntime.WithClientSet(client),
+			frameworkruntime.WithInformerFactory(informerFactory),
+			frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: nodes}),
+		)
+		if err != nil {
+			t.Fatalf("fail to create framework: %s", err)
+		}
+		return fh
+	}
+	newProfile := func(profile, mode string) *CustomScheduler {
+		rebalance, exporter := freeAddress(), freeAddress()
+		rebalanceAddress = rebalance
+		urls[profile] = []string{"http://" + rebalance + "/recommendations", "http://" + exporter + "/state"}
+		p, err := NewWithContext(ctx)(&CustomSchedulerArgs{
+			Mode:           mode,
+			Rebalance:      &RebalanceArgs{Address: rebalance},
+			StateExporter:  &StateExporterArgs{Address: exporter},
+			LeaderElection: &LeaderElectionArgs{},
+		}, newFramework(profile))
+		if err != nil {
+			t.Fatalf("fail to create plugin: %s", err)
+		}
+		return p.(*CustomScheduler)
+	}
+	least := newProfile("custom-least", leastMode)
+	most := newProfile("custom-most", mostMode)
+
+	// A third profile cannot take an address already served.
+	if _, err := NewWithContext(ctx)(&CustomSchedulerArgs{
+		Rebalance: &RebalanceArgs{Address: rebalanceAddress},
+	}, newFramework("custom-duplicate")); err == nil {
+		t.Errorf("custom-duplicate: expected an error for an address already served")
+	}
+
+	pod := makeGroupPods("g1", 1, 1)[0]
+	informerFactory.Core().V1().Pods().Informer().GetStore().Add(pod)
+	best := func(cs *CustomScheduler) string {
+		scores := framework.NodeScoreList{}
+		for _, ni := range nodes {
+			score, status := cs.Score(context.Background(), framework.NewCycleState(), pod, ni.Node().Name)
+			if !status.IsSuccess() {
+				t.Fatalf("unexpected Score error: %v", status)
+			}
+			scores = append(scores, framework.NodeScore{Name: ni.Node().Name, Score: score})
+		}
+		if status := cs.NormalizeScore(context.Background(), framework.NewCycleState(), pod, scores); !status.IsSuccess() {
+			t.Fatalf("unexpected NormalizeScore error: %v", status)
+		}
+		if scores[0].Score > scores[1].Score {
+			return scores[0].Name
+		}
+		return scores[1].Name
+	}
+	if got := best(least); got != "small" {
+		t.Errorf("custom-least: expected small, got %s", got)
+	}
+	if got := best(most); got != "large" {
+		t.Errorf("custom-most: expected large, got %s", got)
+	}
+
+	least.updateConfig(func(c *schedulerConfig) { c.scoreMode = mostMode })
+	if got := most.config().scoreMode; got != mostMode {
+		t.Errorf("custom-most: expected its mode to stay %s, got %s", mostMode, got)
+	}
+	if least.profileName() != "custom-least" || most.profileName() != "custom-most" {
+		t.Errorf("expected profiles custom-least and custom-most, got %s and %s", least.profileName(), most.profileName())
+	}
+
+	for profile, urls := range urls {
+		for _, url := range urls {
+			resp, err := http.Get(url)
+			if err != nil {
+				t.Fatalf("%s: fail to get %s: %s", profile, url, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%s: expected %s to return 200, got %d", profile, url, resp.StatusCode)
+			}
+		}
+	}
+	holder := func(profile string) string {
+		lease, err := client.CoordinationV1().Leases("kube-system").Get(context.Background(), "custom-scheduler-"+profile, metav1.GetOptions{})
+		if err != nil || lease.Spec.HolderIdentity == nil {
+			return ""
+		}
+		return *lease.Spec.HolderIdentity
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return holder("custom-least") != "" && holder("custom-most") != "", nil
+	}); err != nil {
+		t.Fatalf("expected both profiles to hold their own Lease: %s", err)
+	}
+
+	// Stopping the scheduler releases the Leases and the addresses.
+	cancel()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return holder("custom-least") == "" && holder("custom-most") == "", nil
+	}); err != nil {
+		t.Errorf("expected the Leases to be released: %s", err)
+	}
+	for profile, urls := range urls {
+		for _, url := range urls {
+			if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+				_, err := http.Get(url)
+				return err != nil, nil
+			}); err != nil {
+				t.Errorf("%s: expected %s to stop being served: %s", profile, url, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package plugins
+
+// schedulerConfig is the part of the plugin's configuration that may change
+// while the scheduler runs. A published schedulerConfig is never modified:
+// updates publish a modified copy, so every extension point sees one
+// consistent version however many of them run concurrently.
+type schedulerConfig struct {
+	scoreMode                  string
+	maxMinAvailable            int
+	minAvailableConflictPolicy string
+	tieScore                   *int64
+	queueOrder                 string
+	scoreRange                 *ScoreRange
+	invertScores               bool
+	extendedResources          []ResourceWeight
+	imageLocalityWeight        int64
+	nodeTiers                  []nodeTier
+	freeResourceThresholds     []FreeResourceThreshold
+}
+
+// newSchedulerConfig returns the configuration valid args ask for.
+func newSchedulerConfig(args *CustomSchedulerArgs) (*schedulerConfig, error) {
+	mode := args.Mode
+	if mode == "" && len(args.ScoreResources) > 0 {
+		mode = weightedMode
+	}
+	nodeTiers, err := newNodeTiers(args.NodeTierWeights)
+	if err != nil {
+		return nil, err
+	}
+	return &schedulerConfig{
+		scoreMode:                  mode,
+		maxMinAvailable:            args.MaxMinAvailable,
+		minAvailableConflictPolicy: args.MinAvailableConflictPolicy,
+		tieScore:                   args.TieScore,
+		queueOrder:                 args.QueueOrder,
+		scoreRange:                 args.ScoreRange,
+		invertScores:               args.InvertScores,
+		extendedResources:          args.ExtendedResources,
+		imageLocalityWeight:        args.ImageLocalityWeight,
+		nodeTiers:                  nodeTiers,
+		freeResourceThresholds:     args.FreeResourceThresholds,
+	}, nil
+}
+
+// config returns the current configuration. New publishes one; plugins
+// created without it, as in tests, are configured by their fields.
+func (cs *CustomScheduler) config() *schedulerConfig {
+	if c := cs.cfg.Load(); c != nil {
+		return c
+	}
+	return &schedulerConfig{
+		scoreMode:                  cs.scoreMode,
+		maxMinAvailable:            cs.maxMinAvailable,
+		minAvailableConflictPolicy: cs.minAvailableConflictPolicy,
+		tieScore:                   cs.tieScore,
+		queueOrder:                 cs.queueOrder,
+		scoreRange:                 cs.scoreRange,
+		invertScores:               cs.invertScores,
+		extendedResources:          cs.extendedResources,
+		imageLocalityWeight:        cs.imageLocalityWeight,
+		nodeTiers:                  cs.nodeTiers,
+		freeResourceThresholds:     cs.freeResourceThresholds,
+	}
+}
+
+// updateConfig publishes a copy of the current configuration modified by
+// update. Concurrent updates are serialized so that none of them is lost.
+func (cs *CustomScheduler) updateConfig(update func(*schedulerConfig)) {
+	cs.cfgMu.Lock()
+	defer cs.cfgMu.Unlock()
+	c := *cs.config()
+	update(&c)
+	cs.cfg.Store(&c)
+	cs.recordScoreMode(c.scoreMode)
+}
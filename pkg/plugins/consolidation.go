@@ -0,0 +1,40 @@
+package plugins
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// cordonCandidateAnnotation marks a node that an operator or autoscaler
+// intends to drain. The Consolidation mode scores it lowest so that it
+// empties out.
+const cordonCandidateAnnotation string = "nthu.scheduler/cordon-candidate"
+
+// requestedFraction returns the requested fraction of the busier of CPU and
+// memory on nodeInfo, at most 1.
+func requestedFraction(nodeInfo *framework.NodeInfo) float64 {
+	var fraction float64
+	if nodeInfo.Allocatable.MilliCPU > 0 {
+		fraction = float64(nodeInfo.Requested.MilliCPU) / float64(nodeInfo.Allocatable.MilliCPU)
+	}
+	if nodeInfo.Allocatable.Memory > 0 {
+		if f := float64(nodeInfo.Requested.Memory) / float64(nodeInfo.Allocatable.Memory); f > fraction {
+			fraction = f
+		}
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return fraction
+}
+
+// consolidationScore is the raw Consolidation mode score: the requested
+// fraction of the node in permille, so that gangs fill busy nodes and empty
+// nodes stay empty for the autoscaler to remove.
+func consolidationScore(nodeInfo *framework.NodeInfo) int64 {
+	return int64(requestedFraction(nodeInfo) * 1000)
+}
+
+func isCordonCandidate(node *v1.Node) bool {
+	return node != nil && node.Annotations[cordonCandidateAnnotation] == "true"
+}
@@ -0,0 +1,41 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func TestCustomScheduler_ConsolidationMode(t *testing.T) {
+	busy := makeKarpenterNodeInfo("busy", 75)
+	half := makeKarpenterNodeInfo("half", 50)
+	draining := makeKarpenterNodeInfo("draining", 90)
+	draining.Node().Annotations = map[string]string{cordonCandidateAnnotation: "true"}
+	empty := makeKarpenterNodeInfo("empty", 0)
+	cs := &CustomScheduler{scoreMode: consolidationMode, nodes: fakeframework.NodeInfoLister{busy, half, draining, empty}}
+
+	scores := framework.NodeScoreList{}
+	for _, name := range []string{"busy", "half", "draining", "empty"} {
+		score, status := cs.Score(context.Background(), nil, &v1.Pod{}, name)
+		if !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		scores = append(scores, framework.NodeScore{Name: name, Score: score})
+	}
+	if status := cs.NormalizeScore(context.Background(), nil, &v1.Pod{}, scores); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	got := map[string]int64{}
+	for _, s := range scores {
+		got[s.Name] = s.Score
+	}
+	if !(got["busy"] > got["half"] && got["half"] > got["empty"]) {
+		t.Errorf("expected busier nodes to score higher, got %v", got)
+	}
+	if got["draining"] != framework.MinNodeScore || got["empty"] != framework.MinNodeScore {
+		t.Errorf("expected the empty and the draining node to score lowest, got %v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package plugins
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// deadlineAnnotation is the RFC 3339 time by which the group of a pod
+	// must have finished.
+	deadlineAnnotation string = "nthu.scheduler/deadline"
+	// expectedRuntimeAnnotation is how long a pod is expected to run, as a
+	// Go duration such as "90m".
+	expectedRuntimeAnnotation string = "nthu.scheduler/expected-runtime"
+)
+
+// Queue orders accepted in CustomSchedulerArgs.QueueOrder.
+const (
+	queueOrderPriority              string = "Priority"
+	queueOrderEarliestDeadlineFirst string = "EarliestDeadlineFirst"
+	queueOrderGroup                 string = "Group"
+)
+
+func validQueueOrder(order string) bool {
+	return order == "" || order == queueOrderPriority || order == queueOrderEarliestDeadlineFirst || order == queueOrderGroup
+}
+
+// expectedRuntime returns the expected runtime annotation of pod, if valid.
+func expectedRuntime(pod *v1.Pod) (time.Duration, bool) {
+	runtime, err := time.ParseDuration(pod.Annotations[expectedRuntimeAnnotation])
+	if err != nil || runtime < 0 {
+		return 0, false
+	}
+	return runtime, true
+}
+
+// latestStart returns the latest time pod can start and still meet its
+// deadline: the deadline less the expected runtime, if any. Ordering by it
+// rather than by the deadline alone runs the group with the least slack
+// first.
+func latestStart(pod *v1.Pod) (time.Time, bool) {
+	deadline, err := time.Parse(time.RFC3339, pod.Annotations[deadlineAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if runtime, ok := expectedRuntime(pod); ok {
+		return deadline.Add(-runtime), true
+	}
+	return deadline, true
+}
@@ -0,0 +1,204 @@
+package plugins
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// DebugArgs configures the debug endpoint.
+type DebugArgs struct {
+	// Address serves the internal state as JSON at /debug/config,
+	// /debug/gangs, /debug/groups and /debug/scores, "127.0.0.1:10263" by
+	// default; every profile needs its own. The endpoint has no authentication: keep it on the loopback
+	// interface, reached with kubectl port-forward, or behind an
+	// authenticating proxy.
+	Address string `json:"address,omitempty"`
+	// MaxScoredPods is how many pods the last scores are kept for, the
+	// oldest being dropped first. 1000 by default.
+	MaxScoredPods int `json:"maxScoredPods,omitempty"`
+	// Pprof additionally serves the net/http/pprof profiles of the scheduler
+	// at /debug/pprof/, to profile it on large clusters. Profiles expose
+	// memory contents and can load the scheduler, so never serve them beyond
+	// the loopback interface without authentication.
+	Pprof bool `json:"pprof,omitempty"`
+}
+
+// defaultDebugAddress keeps the unauthenticated debug endpoint off the
+// network unless an address is configured.
+const defaultDebugAddress = "127.0.0.1:10263"
+
+// DebugConfig is the configuration the plugin currently runs with.
+type DebugConfig struct {
+	Profile                    string `json:"profile,omitempty"`
+	ScoreMode                  string `json:"scoreMode"`
+	QueueOrder                 string `json:"queueOrder,omitempty"`
+	TieScore                   *int64 `json:"tieScore,omitempty"`
+	MaxMinAvailable            int    `json:"maxMinAvailable,omitempty"`
+	MinAvailableConflictPolicy string `json:"minAvailableConflictPolicy,omitempty"`
+	InvertScores               bool   `json:"invertScores,omitempty"`
+	GroupAdmission             bool   `json:"groupAdmission,omitempty"`
+}
+
+// GroupMembers counts the live members of a pod group.
+type GroupMembers struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	MinAvailable int    `json:"minAvailable"`
+	Members      int    `json:"members"`
+	Bound        int    `json:"bound"`
+}
+
+// PodScores are the normalized scores of the last scheduling cycle of a
+// pod, by node name.
+type PodScores struct {
+	Time   time.Time        `json:"time"`
+	Scores map[string]int64 `json:"scores"`
+}
+
+// debugServer serves the internal state of the plugin for troubleshooting.
+// Unlike the state exporter, it computes its views on request.
+type debugServer struct {
+	cs            *CustomScheduler
+	maxScoredPods int
+	pprof         bool
+
+	mu     sync.Mutex
+	scores map[string]PodScores
+	// scored are the keys of scores, oldest first.
+	scored []string
+}
+
+func newDebugServer(args DebugArgs, cs *CustomScheduler) *debugServer {
+	d := &debugServer{cs: cs, maxScoredPods: args.MaxScoredPods, pprof: args.Pprof, scores: map[string]PodScores{}}
+	if d.maxScoredPods <= 0 {
+		d.maxScoredPods = 1000
+	}
+	return d
+}
+
+// recordScores keeps scores as the last scores of pod.
+func (d *debugServer) recordScores(pod *v1.Pod, scores framework.NodeScoreList) {
+	s := PodScores{Time: d.cs.clock.Now(), Scores: make(map[string]int64, len(scores))}
+	for _, score := range scores {
+		s.Scores[score.Name] = score.Score
+	}
+	key := pod.Namespace + "/" + pod.Name
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.scores[key]; !ok {
+		d.scored = append(d.scored, key)
+	}
+	d.scores[key] = s
+	for len(d.scored) > d.maxScoredPods {
+		delete(d.scores, d.scored[0])
+		d.scored = d.scored[1:]
+	}
+}
+
+// Handler returns the handler serving the views.
+func (d *debugServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, d.config()) })
+	mux.HandleFunc("/debug/gangs", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, d.cs.podGroupManager.ListWaitingGroups()) })
+	mux.HandleFunc("/debug/groups", func(w http.ResponseWriter, r *http.Request) {
+		groups, err := d.groupMembers()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, groups)
+	})
+	mux.HandleFunc("/debug/scores", func(w http.ResponseWriter, r *http.Request) { writeJSON(w, d.lastScores(r.URL.Query().Get("pod"))) })
+	if d.pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (d *debugServer) config() DebugConfig {
+	c := d.cs.config()
+	return DebugConfig{
+		Profile:                    d.cs.profileName(),
+		ScoreMode:                  c.scoreMode,
+		QueueOrder:                 c.queueOrder,
+		TieScore:                   c.tieScore,
+		MaxMinAvailable:            c.maxMinAvailable,
+		MinAvailableConflictPolicy: c.minAvailableConflictPolicy,
+		InvertScores:               c.invertScores,
+		GroupAdmission:             d.cs.groupAdmission,
+	}
+}
+
+// groupMembers counts the live members of every pod group in the pod cache.
+func (d *debugServer) groupMembers() ([]GroupMembers, error) {
+	pods, err := d.cs.podLister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string]*GroupMembers{}
+	for _, p := range pods {
+		if d.cs.isUngrouped(p) || !isLive(p) {
+			continue
+		}
+		group, status := d.cs.groupOf(p)
+		if !status.IsSuccess() {
+			continue
+		}
+		key := group.key()
+		g, ok := groups[key]
+		if !ok {
+			g = &GroupMembers{Namespace: group.namespace, Name: group.name, MinAvailable: group.minAvailable}
+			groups[key] = g
+		}
+		g.Members++
+		if p.Spec.NodeName != "" {
+			g.Bound++
+		}
+	}
+	list := make([]GroupMembers, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, *g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Namespace != list[j].Namespace {
+			return list[i].Namespace < list[j].Namespace
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// lastScores returns the last scores of the pod namespace/name, or of every
+// pod kept if pod is empty.
+func (d *debugServer) lastScores(pod string) map[string]PodScores {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	scores := map[string]PodScores{}
+	if pod != "" {
+		if s, ok := d.scores[pod]; ok {
+			scores[pod] = s
+		}
+		return scores
+	}
+	for key, s := range d.scores {
+		scores[key] = s
+	}
+	return scores
+}
@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// getDebug decodes the view served by d at path into v.
+func getDebug(t *testing.T, d *debugServer, path string, v interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	d.Handler().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	if rec.Code != 200 {
+		t.Fatalf("%s: expected 200, got %d: %s", path, rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDebugServer(t *testing.T) {
+	pods := makeGroupPods("g1", 3, 3)
+	for _, p := range pods {
+		p.UID = types.UID(p.Name)
+	}
+	pods[0].Spec.NodeName = "n1"
+	node := makeNodeInfo("n1", 1000, 1000)
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(now), pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{node}, scoreMode: leastMode}
+	fwk := newPermitFramework(t, cs)
+	d := newDebugServer(DebugArgs{}, cs)
+
+	var config DebugConfig
+	getDebug(t, d, "/debug/config", &config)
+	if config.ScoreMode != leastMode {
+		t.Errorf("expected score mode %s, got %s", leastMode, config.ScoreMode)
+	}
+
+	var groups []GroupMembers
+	getDebug(t, d, "/debug/groups", &groups)
+	wantGroups := []GroupMembers{{Namespace: pods[0].Namespace, Name: "g1", MinAvailable: 3, Members: 3, Bound: 1}}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Errorf("expected groups %+v, got %+v", wantGroups, groups)
+	}
+
+	if status := fwk.RunPermitPlugins(context.Background(), framework.NewCycleState(), pods[1], "n1"); !status.IsWait() {
+		t.Fatalf("expected to wait, got %v", status)
+	}
+	var gangs []GroupStatus
+	getDebug(t, d, "/debug/gangs", &gangs)
+	if len(gangs) != 1 || gangs[0].Name != "g1" || !reflect.DeepEqual(gangs[0].Waiting, []string{pods[1].Name}) || gangs[0].Deadline == nil || !gangs[0].Deadline.Equal(now.Add(defaultPermitTimeout)) {
+		t.Errorf("expected g1 to wait for %v with member %s, got %+v", defaultPermitTimeout, pods[1].Name, gangs)
+	}
+	fwk.RejectWaitingPod(pods[1].UID)
+}
+
+func TestDebugServer_Scores(t *testing.T) {
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now())}
+	d := newDebugServer(DebugArgs{MaxScoredPods: 2}, cs)
+	pods := makeGroupPods("g1", 3, 3)
+	for i, p := range pods {
+		d.recordScores(p, framework.NodeScoreList{{Name: "n1", Score: int64(i)}})
+	}
+	// scoring a pod again keeps its place.
+	d.recordScores(pods[1], framework.NodeScoreList{{Name: "n1", Score: 10}})
+
+	var scores map[string]PodScores
+	getDebug(t, d, "/debug/scores", &scores)
+	if len(scores) != 2 {
+		t.Fatalf("expected the scores of 2 pods, got %+v", scores)
+	}
+	if _, ok := scores[pods[0].Namespace+"/"+pods[0].Name]; ok {
+		t.Errorf("expected the oldest pod to be dropped, got %+v", scores)
+	}
+
+	scores = nil
+	getDebug(t, d, "/debug/scores?pod="+pods[1].Namespace+"/"+pods[1].Name, &scores)
+	if s, ok := scores[pods[1].Namespace+"/"+pods[1].Name]; len(scores) != 1 || !ok || s.Scores["n1"] != 10 {
+		t.Errorf("expected the last score of %s to be 10, got %+v", pods[1].Name, scores)
+	}
+}
+
+func TestDebugServer_Pprof(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		d := newDebugServer(DebugArgs{Pprof: enabled}, &CustomScheduler{})
+		rec := httptest.NewRecorder()
+		d.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/", nil))
+		want := 404
+		if enabled {
+			want = 200
+		}
+		if rec.Code != want {
+			t.Errorf("pprof %t: expected %d, got %d", enabled, want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/dynamic-resource-allocation/resourceclaim"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// filterResourceClaims rejects nodes on which an already allocated
+// ResourceClaim of pod is not available, so gang members are only placed where
+// their devices are. Claims that are not allocated yet are left to the
+// DynamicResources plugin.
+func (cs *CustomScheduler) filterResourceClaims(pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if cs.resourceClaims == nil {
+		return nil
+	}
+	for i := range pod.Spec.ResourceClaims {
+		name := resourceclaim.Name(pod, &pod.Spec.ResourceClaims[i])
+		claim, err := cs.resourceClaims.ResourceClaims(pod.Namespace).Get(name)
+		if err != nil {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("waiting for resource claim %s: %v", name, err))
+		}
+		if claim.Status.Allocation == nil {
+			continue
+		}
+		if !resourceclaim.IsReservedForPod(pod, claim) && !resourceclaim.CanBeReserved(claim) {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("resource claim %s is in use", name))
+		}
+		if claim.Status.Allocation.AvailableOnNodes == nil {
+			continue
+		}
+		selector, err := nodeaffinity.NewNodeSelector(claim.Status.Allocation.AvailableOnNodes)
+		if err != nil {
+			return framework.AsStatus(fmt.Errorf("invalid availableOnNodes of resource claim %s: %w", name, err))
+		}
+		if !selector.Match(nodeInfo.Node()) {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("resource claim %s is not available on the node", name))
+		}
+	}
+	return nil
+}
+
+// draUnsuitableNodes returns the nodes that resource drivers reported as
+// unable to allocate at least one of the claims of pod.
+func (cs *CustomScheduler) draUnsuitableNodes(pod *v1.Pod) map[string]bool {
+	if cs.podSchedulingContexts == nil || len(pod.Spec.ResourceClaims) == 0 {
+		return nil
+	}
+	sc, err := cs.podSchedulingContexts.PodSchedulingContexts(pod.Namespace).Get(pod.Name)
+	if err != nil {
+		return nil
+	}
+	unsuitable := map[string]bool{}
+	for _, claim := range sc.Status.ResourceClaims {
+		for _, node := range claim.UnsuitableNodes {
+			unsuitable[node] = true
+		}
+	}
+	return unsuitable
+}
@@ -0,0 +1,98 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	resourcev1alpha2 "k8s.io/api/resource/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makeClaimPod(claims ...string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default", UID: "pod-uid"}}
+	for _, c := range claims {
+		name := c
+		pod.Spec.ResourceClaims = append(pod.Spec.ResourceClaims, v1.PodResourceClaim{
+			Name:   c,
+			Source: v1.ClaimSource{ResourceClaimName: &name},
+		})
+	}
+	return pod
+}
+
+func makeClaim(name string, allocatedOn string, reservedByOther bool) *resourcev1alpha2.ResourceClaim {
+	claim := &resourcev1alpha2.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	if allocatedOn != "" {
+		claim.Status.Allocation = &resourcev1alpha2.AllocationResult{
+			AvailableOnNodes: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchFields: []v1.NodeSelectorRequirement{{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{allocatedOn}}},
+			}}},
+		}
+	}
+	if reservedByOther {
+		claim.Status.ReservedFor = []resourcev1alpha2.ResourceClaimConsumerReference{{Resource: "pods", Name: "other", UID: "other-uid"}}
+	}
+	return claim
+}
+
+func TestCustomScheduler_FilterResourceClaims(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	claims := informerFactory.Resource().V1alpha2().ResourceClaims()
+	for _, c := range []*resourcev1alpha2.ResourceClaim{
+		makeClaim("pending", "", false),
+		makeClaim("on-n1", "n1", false),
+		makeClaim("in-use", "n1", true),
+	} {
+		claims.Informer().GetStore().Add(c)
+	}
+	cs := &CustomScheduler{scoreMode: leastMode, resourceClaims: claims.Lister()}
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		node string
+		want framework.Code
+	}{
+		{name: "no claims", pod: makeClaimPod(), node: "n2", want: framework.Success},
+		{name: "claim not allocated yet", pod: makeClaimPod("pending"), node: "n2", want: framework.Success},
+		{name: "claim available on node", pod: makeClaimPod("on-n1"), node: "n1", want: framework.Success},
+		{name: "claim allocated elsewhere", pod: makeClaimPod("on-n1"), node: "n2", want: framework.UnschedulableAndUnresolvable},
+		{name: "claim in use by another pod", pod: makeClaimPod("in-use"), node: "n1", want: framework.UnschedulableAndUnresolvable},
+		{name: "claim missing", pod: makeClaimPod("missing"), node: "n1", want: framework.UnschedulableAndUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := cs.Filter(context.Background(), framework.NewCycleState(), tt.pod, makeNodeInfo(tt.node, 1000, 100))
+			if status.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %v", tt.want, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_NormalizeScoreUnsuitableNodes(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	contexts := informerFactory.Resource().V1alpha2().PodSchedulingContexts()
+	contexts.Informer().GetStore().Add(&resourcev1alpha2.PodSchedulingContext{
+		ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default"},
+		Status: resourcev1alpha2.PodSchedulingContextStatus{
+			ResourceClaims: []resourcev1alpha2.ResourceClaimSchedulingStatus{{Name: "gpu", UnsuitableNodes: []string{"m3"}}},
+		},
+	})
+	cs := &CustomScheduler{scoreMode: mostMode, podSchedulingContexts: contexts.Lister()}
+
+	scores := framework.NodeScoreList{{Name: "m1", Score: 1}, {Name: "m2", Score: 2}, {Name: "m3", Score: 3}}
+	if status := cs.NormalizeScore(context.Background(), nil, makeClaimPod("gpu"), scores); !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	want := []int64{framework.MinNodeScore, framework.MaxNodeScore / 2, framework.MinNodeScore}
+	for i := range scores {
+		if scores[i].Score != want[i] {
+			t.Errorf("node %s: expected %d, got %d", scores[i].Name, want[i], scores[i].Score)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ownerUIDIndex indexes objects by namespace and the UIDs of their owners.
+const ownerUIDIndex = "ownerUID"
+
+// dynamicInformerFactory returns the informer factory for custom resources
+// that have no typed client in this module, creating it on first use. It is
+// only called from New, and started once New has registered all informers.
+func (cs *CustomScheduler) dynamicInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
+	if cs.dynamicInformers != nil {
+		return cs.dynamicInformers, nil
+	}
+	if cs.handle == nil || cs.handle.KubeConfig() == nil {
+		return nil, fmt.Errorf("no kubeconfig available to watch custom resources")
+	}
+	client, err := dynamic.NewForConfig(cs.handle.KubeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	cs.dynamicInformers = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	return cs.dynamicInformers, nil
+}
+
+// unstructuredLister serves custom resources of one kind from an informer cache.
+type unstructuredLister struct {
+	indexer cache.Indexer
+	synced  cache.InformerSynced
+}
+
+// newUnstructuredLister registers an informer for gvr, indexed by owner UID.
+// Callers register its synced func with their integration rather than with
+// the informers PreFilter waits for, since the CRD may not be installed.
+func (cs *CustomScheduler) newUnstructuredLister(gvr schema.GroupVersionResource) (*unstructuredLister, error) {
+	factory, err := cs.dynamicInformerFactory()
+	if err != nil {
+		return nil, err
+	}
+	informer := factory.ForResource(gvr).Informer()
+	if err := informer.AddIndexers(cache.Indexers{ownerUIDIndex: indexByOwnerUID}); err != nil {
+		return nil, fmt.Errorf("failed to index %s: %w", gvr.Resource, err)
+	}
+	return &unstructuredLister{indexer: informer.GetIndexer(), synced: informer.HasSynced}, nil
+}
+
+func indexByOwnerUID(obj interface{}) ([]string, error) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, ref := range o.GetOwnerReferences() {
+		keys = append(keys, o.GetNamespace()+"/"+string(ref.UID))
+	}
+	return keys, nil
+}
+
+// Get returns the object with the given namespace and name, or nil if it does not exist.
+func (l *unstructuredLister) Get(namespace, name string) (*unstructured.Unstructured, error) {
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	obj, exists, err := l.indexer.GetByKey(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return obj.(*unstructured.Unstructured), nil
+}
+
+// ByOwnerUID returns the objects in namespace owned by the object with uid.
+func (l *unstructuredLister) ByOwnerUID(namespace string, uid types.UID) ([]*unstructured.Unstructured, error) {
+	objs, err := l.indexer.ByIndex(ownerUIDIndex, namespace+"/"+string(uid))
+	if err != nil {
+		return nil, err
+	}
+	var result []*unstructured.Unstructured
+	for _, obj := range objs {
+		result = append(result, obj.(*unstructured.Unstructured))
+	}
+	return result, nil
+}
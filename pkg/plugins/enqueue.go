@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// gangGate holds pod in the unschedulable pods pool while its group has
+// fewer members than minAvailable, rather than letting it churn through
+// scheduling cycles that PreFilter rejects. The member that completes the
+// group activates the held ones (see activateSiblings). Groups past their
+// schedule timeout are let through, so PreFilter can reject them for good.
+func (cs *CustomScheduler) gangGate(pod *v1.Pod) *framework.Status {
+	if cs.isUngrouped(pod) || !cs.cachesSynced() {
+		return nil
+	}
+	// invalid groups are reported by PreFilter.
+	group, status := cs.groupOf(pod)
+	if !status.IsSuccess() || group.minAvailable <= 1 {
+		return nil
+	}
+	members, err := cs.groupMembers(group)
+	if err != nil || len(members) >= group.minAvailable {
+		return nil
+	}
+	if _, ok := cs.groupTimedOut(group, members); ok {
+		return nil
+	}
+	msg := insufficientMembers(group, len(members))
+	cs.recordGangEvent(pod, group, insufficientMembersReason, "PreEnqueue", msg)
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable, msg)
+}
+
+// activateSiblings moves the members of the gang of pod that are neither
+// bound nor assumed back to the active queue at the end of this scheduling
+// cycle, since they may have been held by gangGate before pod completed the
+// group.
+func (cs *CustomScheduler) activateSiblings(state *framework.CycleState, pod *v1.Pod, members []*v1.Pod) {
+	if state == nil {
+		return
+	}
+	data, err := state.Read(framework.PodsToActivateKey)
+	if err != nil {
+		return
+	}
+	podsToActivate, ok := data.(*framework.PodsToActivate)
+	if !ok {
+		return
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return
+	}
+	placed := map[string]bool{podKey(pod): true}
+	for _, ni := range nodeInfos {
+		for _, pi := range ni.Pods {
+			placed[podKey(pi.Pod)] = true
+		}
+	}
+	podsToActivate.Lock()
+	defer podsToActivate.Unlock()
+	for _, p := range members {
+		if p.Spec.NodeName == "" && !placed[podKey(p)] {
+			podsToActivate.Map[klog.KObj(p).String()] = p
+		}
+	}
+}
+
+// EventsToRegister returns the events that may make a pod this plugin
+// rejected schedulable, so the queue retries it on those alone instead of
+// on every cluster event: members of a group being added (which includes
+// being bound) or deleted, nodes being added or changing, and, where they
+// are enabled, PodGroups and Kueue Workloads changing.
+func (cs *CustomScheduler) EventsToRegister() []clusterEvent {
+	events := []clusterEvent{
+		toClusterEvent(framework.ClusterEvent{Resource: framework.Pod, ActionType: framework.Add | framework.Delete}),
+		toClusterEvent(framework.ClusterEvent{Resource: framework.Node, ActionType: framework.Add | framework.Update}),
+	}
+	if cs.podGroups != nil {
+		events = append(events, toClusterEvent(framework.ClusterEvent{Resource: gvkOf(PodGroupGVR), ActionType: framework.Add | framework.Update}))
+	}
+	if cs.workloads != nil {
+		events = append(events, toClusterEvent(framework.ClusterEvent{Resource: gvkOf(workloadGVR), ActionType: framework.Add | framework.Update}))
+	}
+	return events
+}
+
+// gvkOf returns the name the scheduler watches the custom resource gvr by.
+func gvkOf(gvr schema.GroupVersionResource) framework.GVK {
+	return framework.GVK(gvr.Resource + "." + gvr.Version + "." + gvr.Group)
+}
@@ -0,0 +1,80 @@
+package plugins
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestCustomScheduler_GangGate(t *testing.T) {
+	incomplete := makeGroupPods("g1", 3, 2)
+	complete := makeGroupPods("g2", 2, 2)
+	pods := append(append([]*v1.Pod{}, incomplete...), complete...)
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now()), pods: &faultyPodLister{stale: pods}, scoreMode: leastMode}
+	for _, tt := range []struct {
+		name string
+		pod  *v1.Pod
+		want framework.Code
+	}{
+		{name: "incomplete gang", pod: incomplete[0], want: framework.UnschedulableAndUnresolvable},
+		{name: "complete gang", pod: complete[0], want: framework.Success},
+		{name: "group of one", pod: makeGroupPods("g3", 1, 1)[0], want: framework.Success},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := cs.PreEnqueue(context.Background(), tt.pod); status.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, status)
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_PermitActivatesSiblings(t *testing.T) {
+	pods := makeGroupPods("g1", 3, 4)
+	for _, p := range pods {
+		p.UID = types.UID(p.Name)
+	}
+	pods[3].Spec.NodeName = "n1"
+	node := makeNodeInfo("n1", 1000, 1000)
+	// pods[1] waits in Permit, assumed on n1.
+	node.AddPod(pods[1])
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now()), pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{node}}
+	fwk := newPermitFramework(t, cs)
+
+	state := framework.NewCycleState()
+	podsToActivate := framework.NewPodsToActivate()
+	state.Write(framework.PodsToActivateKey, podsToActivate)
+	if status := fwk.RunPermitPlugins(context.Background(), state, pods[0], "n1"); !status.IsWait() {
+		t.Fatalf("expected to wait, got %v", status)
+	}
+	defer fwk.RejectWaitingPod(pods[0].UID)
+	if len(podsToActivate.Map) != 1 || podsToActivate.Map[klog.KObj(pods[2]).String()] == nil {
+		t.Errorf("expected only %s to be activated, got %v", pods[2].Name, podsToActivate.Map)
+	}
+}
+
+func TestCustomScheduler_EventsToRegister(t *testing.T) {
+	resources := func(cs *CustomScheduler) map[framework.GVK]framework.ActionType {
+		got := map[framework.GVK]framework.ActionType{}
+		for _, e := range cs.EventsToRegister() {
+			got[eventOf(e).Resource] |= eventOf(e).ActionType
+		}
+		return got
+	}
+	got := resources(&CustomScheduler{})
+	want := map[framework.GVK]framework.ActionType{framework.Pod: framework.Add | framework.Delete, framework.Node: framework.Add | framework.Update}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	got = resources(&CustomScheduler{podGroups: newFakeUnstructuredLister(t)})
+	if got["podgroups.v1alpha1.nthu.scheduler"] != framework.Add|framework.Update {
+		t.Errorf("expected PodGroup events with PodGroups enabled, got %v", got)
+	}
+}
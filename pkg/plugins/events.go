@@ -0,0 +1,45 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// insufficientMembersReason is the event reason for gang members rejected
+	// because their group has fewer members than minAvailable.
+	insufficientMembersReason string = "InsufficientMembers"
+	// gangRejectedReason is the event reason for gang members rejected while
+	// waiting in Permit because the gang did not complete.
+	gangRejectedReason string = "GangRejected"
+	// nodePoolFullReason is the event reason for gang members rejected
+	// because the node pool of their group cannot hold minAvailable members.
+	nodePoolFullReason string = "NodePoolFull"
+	// insufficientCapacityReason is the event reason for gang members
+	// rejected because the cluster lacks the capacity for minAvailable members.
+	insufficientCapacityReason string = "InsufficientCapacity"
+	// groupQuotaExceededReason is the event reason for gang members rejected
+	// because their group would exceed its quota.
+	groupQuotaExceededReason string = "GroupQuotaExceeded"
+)
+
+// recordGangEvent records a warning explaining why pod of group was rejected
+// in phase, on pod and, if the group is a PodGroup custom resource, on the
+// PodGroup, so that kubectl describe shows it on either.
+func (cs *CustomScheduler) recordGangEvent(pod *v1.Pod, group *podGroup, reason, phase, msg string) {
+	msg = fmt.Sprintf("%s: %s", phase, msg)
+	cs.recordEvent(pod, v1.EventTypeWarning, reason, "Scheduling", msg)
+	if cs.podGroups == nil || pod.Labels[podGroupLabel] != group.name {
+		return
+	}
+	if pg, err := cs.podGroups.Get(group.namespace, group.name); err == nil && pg != nil {
+		cs.recordEvent(pg, v1.EventTypeWarning, reason, "Scheduling", fmt.Sprintf("pod %s rejected in %s", pod.Name, msg))
+	}
+}
+
+// insufficientMembers is the status and event message of a group with fewer
+// members than minAvailable.
+func insufficientMembers(group *podGroup, members int) string {
+	return fmt.Sprintf("pod group %s has %d of the %d members it needs (minAvailable)", group.name, members, group.minAvailable)
+}
@@ -0,0 +1,51 @@
+package plugins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+func TestCustomScheduler_PreFilterInsufficientMembersEvents(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithEventRecorder(recorder),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	pods := makePodGroupPods("large", 3)
+	podGroups := newFakeUnstructuredLister(t, makePodGroup("large", map[string]interface{}{"minMember": int64(4)}))
+	cs := &CustomScheduler{handle: fh, scoreMode: leastMode, pods: &faultyPodLister{stale: pods}, podGroups: podGroups}
+
+	_, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0])
+	want := "pod group large has 3 of the 4 members it needs (minAvailable)"
+	if status.Code() != framework.Unschedulable || status.Message() != want {
+		t.Errorf("expected Unschedulable with %q, got %v", want, status)
+	}
+	// one event on the pod and one on its PodGroup.
+	for _, prefix := range []string{"PreFilter: ", "pod large-0 rejected in PreFilter: "} {
+		select {
+		case e := <-recorder.Events:
+			if !strings.Contains(e, insufficientMembersReason) || !strings.Contains(e, prefix+want) {
+				t.Errorf("unexpected event %q", e)
+			}
+		default:
+			t.Errorf("expected an event %q", prefix+want)
+		}
+	}
+}
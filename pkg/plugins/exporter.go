@@ -0,0 +1,326 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+)
+
+// Group states reported by the exporter.
+const (
+	// groupWaiting has fewer members than minAvailable.
+	groupWaiting = "Waiting"
+	// groupPending has enough members, but fewer than minAvailable are bound.
+	groupPending = "Pending"
+	// groupScheduled has at least minAvailable members bound.
+	groupScheduled = "Scheduled"
+	// groupRunning has at least minAvailable members running.
+	groupRunning = "Running"
+)
+
+// defaultQueue holds the pending pods of groups without a queue.
+const defaultQueue = "default"
+
+// maxExportedGroups and maxExportedQueues bound the cardinality of the
+// per-group and per-queue metrics; the JSON state lists everything.
+const (
+	maxExportedGroups = 1000
+	maxExportedQueues = 100
+)
+
+// StateExporterArgs configures the state exporter.
+type StateExporterArgs struct {
+	// IntervalSeconds is how often the state is refreshed, 15 by default.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+	// Address serves the state as JSON at /state, "127.0.0.1:10262" by
+	// default; every profile needs its own. The metrics are served on the
+	// scheduler's own /metrics endpoint.
+	Address string `json:"address,omitempty"`
+}
+
+// defaultStateExporterAddress keeps the unauthenticated state off the
+// network unless an address is configured.
+const defaultStateExporterAddress = "127.0.0.1:10262"
+
+// GroupState is the observed state of one pod group.
+type GroupState struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	Queue        string `json:"queue,omitempty"`
+	MinAvailable int    `json:"minAvailable"`
+	Members      int    `json:"members"`
+	Scheduled    int    `json:"scheduled"`
+	Running      int    `json:"running"`
+	State        string `json:"state"`
+}
+
+// ReservationState is the utilization of one active reservation.
+type ReservationState struct {
+	Name  string `json:"name"`
+	Nodes int    `json:"nodes"`
+	// Utilization is the requested fraction of the reserved nodes' allocatable
+	// CPU or memory, whichever is higher.
+	Utilization float64 `json:"utilization"`
+}
+
+// State is the snapshot served by the exporter.
+type State struct {
+	Time         time.Time          `json:"time"`
+	Groups       []GroupState       `json:"groups"`
+	QueueDepths  map[string]int     `json:"queueDepths"`
+	Reservations []ReservationState `json:"reservations,omitempty"`
+}
+
+var (
+	groupMembersGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "pod_group_pods",
+		Help:           "Number of pods of a pod group, by state: members, scheduled or running.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "namespace", "group", "state"})
+	groupMinAvailableGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "pod_group_min_available",
+		Help:           "minAvailable of a pod group.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "namespace", "group"})
+	queueDepthGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "queue_pending_pods",
+		Help:           "Number of unbound pod group members per queue.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "queue"})
+	reservationUtilizationGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "reservation_utilization",
+		Help:           "Requested fraction of the nodes of an active reservation.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "reservation"})
+
+	registerStateMetrics sync.Once
+)
+
+// stateExporter periodically summarizes pod groups, queues and reservations.
+type stateExporter struct {
+	cs       *CustomScheduler
+	nodes    listersv1.NodeLister
+	interval time.Duration
+	// profile labels the metrics, since every scheduler profile backed by
+	// this plugin runs its own exporter.
+	profile string
+
+	mu    sync.RWMutex
+	state State
+	// published is what publish last exported, to be deleted before the
+	// next export without touching the series of other profiles.
+	published State
+}
+
+func newStateExporter(args StateExporterArgs, cs *CustomScheduler, nodes listersv1.NodeLister) *stateExporter {
+	registerStateMetrics.Do(func() {
+		legacyregistry.MustRegister(groupMembersGauge, groupMinAvailableGauge, queueDepthGauge, reservationUtilizationGauge)
+	})
+	e := &stateExporter{cs: cs, nodes: nodes, interval: time.Duration(args.IntervalSeconds) * time.Second, profile: cs.profileName()}
+	if e.interval <= 0 {
+		e.interval = 15 * time.Second
+	}
+	return e
+}
+
+// run refreshes the state every interval until ctx is done.
+func (e *stateExporter) run(ctx context.Context) {
+	for {
+		if err := e.refresh(); err != nil {
+			e.cs.logger().Error(err, "Failed to refresh the scheduler state")
+		}
+		timer := e.cs.clock.NewTimer(e.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+func (e *stateExporter) refresh() error {
+	pods, err := e.cs.podLister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	state := State{Time: e.cs.clock.Now(), Groups: []GroupState{}, QueueDepths: map[string]int{}}
+
+	groups := map[string]*GroupState{}
+	for _, p := range pods {
+		if e.cs.isUngrouped(p) || !isLive(p) {
+			continue
+		}
+		group, status := e.cs.groupOf(p)
+		if !status.IsSuccess() {
+			continue
+		}
+		key := group.key()
+		g, ok := groups[key]
+		if !ok {
+			g = &GroupState{Namespace: group.namespace, Name: group.name, Queue: group.queue, MinAvailable: group.minAvailable}
+			groups[key] = g
+		}
+		g.Members++
+		if p.Spec.NodeName == "" {
+			queue := group.queue
+			if queue == "" {
+				queue = defaultQueue
+			}
+			state.QueueDepths[queue]++
+			continue
+		}
+		g.Scheduled++
+		if p.Status.Phase == v1.PodRunning {
+			g.Running++
+		}
+	}
+	for _, g := range groups {
+		switch {
+		case g.Running >= g.MinAvailable:
+			g.State = groupRunning
+		case g.Scheduled >= g.MinAvailable:
+			g.State = groupScheduled
+		case g.Members >= g.MinAvailable:
+			g.State = groupPending
+		default:
+			g.State = groupWaiting
+		}
+		state.Groups = append(state.Groups, *g)
+	}
+	sort.Slice(state.Groups, func(i, j int) bool {
+		if state.Groups[i].Namespace != state.Groups[j].Namespace {
+			return state.Groups[i].Namespace < state.Groups[j].Namespace
+		}
+		return state.Groups[i].Name < state.Groups[j].Name
+	})
+
+	if e.cs.reservations != nil {
+		reservations, err := e.reservationStates(pods, state.Time)
+		if err != nil {
+			return err
+		}
+		state.Reservations = reservations
+	}
+
+	e.mu.Lock()
+	e.state = state
+	e.mu.Unlock()
+	e.publish(state)
+	return nil
+}
+
+// reservationStates computes the utilization of the reservations active at now.
+func (e *stateExporter) reservationStates(pods []*v1.Pod, now time.Time) ([]ReservationState, error) {
+	reservations, err := e.cs.reservations.get()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := e.nodes.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var states []ReservationState
+	for _, res := range reservations {
+		if now.Before(res.start) || !now.Before(res.end) {
+			continue
+		}
+		var allocatableCPU, allocatableMemory, requestedCPU, requestedMemory int64
+		for _, n := range nodes {
+			if res.nodes[n.Name] {
+				allocatableCPU += n.Status.Allocatable.Cpu().MilliValue()
+				allocatableMemory += n.Status.Allocatable.Memory().Value()
+			}
+		}
+		for _, p := range pods {
+			if !res.nodes[p.Spec.NodeName] || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+				continue
+			}
+			requests := resourcehelper.PodRequests(p, resourcehelper.PodResourcesOptions{})
+			requestedCPU += requests.Cpu().MilliValue()
+			requestedMemory += requests.Memory().Value()
+		}
+		s := ReservationState{Name: res.name, Nodes: len(res.nodes)}
+		if allocatableCPU > 0 {
+			s.Utilization = float64(requestedCPU) / float64(allocatableCPU)
+		}
+		if allocatableMemory > 0 {
+			if u := float64(requestedMemory) / float64(allocatableMemory); u > s.Utilization {
+				s.Utilization = u
+			}
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
+
+// publish replaces the exported gauges with state.
+func (e *stateExporter) publish(state State) {
+	e.unpublish()
+	groups := state.Groups
+	if len(groups) > maxExportedGroups {
+		groups = groups[:maxExportedGroups]
+	}
+	for _, g := range groups {
+		groupMembersGauge.WithLabelValues(e.profile, g.Namespace, g.Name, "members").Set(float64(g.Members))
+		groupMembersGauge.WithLabelValues(e.profile, g.Namespace, g.Name, "scheduled").Set(float64(g.Scheduled))
+		groupMembersGauge.WithLabelValues(e.profile, g.Namespace, g.Name, "running").Set(float64(g.Running))
+		groupMinAvailableGauge.WithLabelValues(e.profile, g.Namespace, g.Name).Set(float64(g.MinAvailable))
+	}
+	queues := make([]string, 0, len(state.QueueDepths))
+	for queue := range state.QueueDepths {
+		queues = append(queues, queue)
+	}
+	sort.Strings(queues)
+	if len(queues) > maxExportedQueues {
+		queues = queues[:maxExportedQueues]
+	}
+	queueDepths := map[string]int{}
+	for _, queue := range queues {
+		queueDepthGauge.WithLabelValues(e.profile, queue).Set(float64(state.QueueDepths[queue]))
+		queueDepths[queue] = state.QueueDepths[queue]
+	}
+	for _, r := range state.Reservations {
+		reservationUtilizationGauge.WithLabelValues(e.profile, r.Name).Set(r.Utilization)
+	}
+	e.published = State{Groups: groups, QueueDepths: queueDepths, Reservations: state.Reservations}
+}
+
+// unpublish deletes the series of the last publish.
+func (e *stateExporter) unpublish() {
+	for _, g := range e.published.Groups {
+		for _, s := range []string{"members", "scheduled", "running"} {
+			groupMembersGauge.Delete(map[string]string{"profile": e.profile, "namespace": g.Namespace, "group": g.Name, "state": s})
+		}
+		groupMinAvailableGauge.Delete(map[string]string{"profile": e.profile, "namespace": g.Namespace, "group": g.Name})
+	}
+	for queue := range e.published.QueueDepths {
+		queueDepthGauge.Delete(map[string]string{"profile": e.profile, "queue": queue})
+	}
+	for _, r := range e.published.Reservations {
+		reservationUtilizationGauge.Delete(map[string]string{"profile": e.profile, "reservation": r.Name})
+	}
+}
+
+// ServeHTTP serves the latest state as JSON.
+func (e *stateExporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e.mu.RLock()
+	state := e.state
+	e.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,72 @@
+package plugins
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestStateExporter_Refresh(t *testing.T) {
+	running := makeGroupPods("running", 2, 2)
+	for _, p := range running {
+		p.Spec.NodeName, p.Status.Phase = "n1", v1.PodRunning
+		p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(25, resource.BinarySI)},
+		}}}
+	}
+	waiting := makeGroupPods("waiting", 3, 2)
+	pods := append(append(running, waiting...), &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "ungrouped"}})
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "reservations", ResourceVersion: "1"},
+		Data:       map[string]string{"maint": "start: 2023-06-01T00:00:00Z\nend: 2023-06-02T00:00:00Z\nnodes: [n1]\n"},
+	})
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	cs := &CustomScheduler{
+		clock:        testingclock.NewFakeClock(now),
+		pods:         podListerFunc(func(labels.Selector) ([]*v1.Pod, error) { return pods, nil }),
+		reservations: &reservations{configMaps: listersv1.NewConfigMapLister(indexer).ConfigMaps("kube-system"), name: "reservations"},
+	}
+	e := newStateExporter(StateExporterArgs{}, cs, newNodeLister(t, makeNodeInfo("n1", 1000, 100).Node()))
+	if err := e.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/state", nil))
+	var got State
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := State{
+		Time: now,
+		Groups: []GroupState{
+			{Namespace: running[0].Namespace, Name: "running", MinAvailable: 2, Members: 2, Scheduled: 2, Running: 2, State: groupRunning},
+			{Namespace: waiting[0].Namespace, Name: "waiting", MinAvailable: 3, Members: 2, State: groupWaiting},
+		},
+		QueueDepths:  map[string]int{defaultQueue: 2},
+		Reservations: []ReservationState{{Name: "maint", Nodes: 1, Utilization: 0.5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if v, err := testutil.GetGaugeMetricValue(groupMembersGauge.WithLabelValues(e.profile, running[0].Namespace, "running", "running")); err != nil || v != 2 {
+		t.Errorf("expected 2 running pods, got %v, %v", v, err)
+	}
+	if v, err := testutil.GetGaugeMetricValue(queueDepthGauge.WithLabelValues(e.profile, defaultQueue)); err != nil || v != 2 {
+		t.Errorf("expected a queue depth of 2, got %v, %v", v, err)
+	}
+}
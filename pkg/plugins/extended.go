@@ -0,0 +1,43 @@
+package plugins
+
+import (
+	v1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// packsExtendedResources reports whether mode packs pods onto the nodes
+// already using their extended resources rather than spreading them out.
+func packsExtendedResources(mode string) bool {
+	return mode != mostMode && mode != mostCPUMode && mode != realMostMode
+}
+
+// extendedResourceBonus scores the extended resources, such as
+// nvidia.com/gpu, pod requests of those in CustomSchedulerArgs.ExtendedResources.
+// Each adds up to its weight: by the fraction of the resource on nodeInfo
+// that would be requested with pod on it when the mode packs, by the
+// fraction left free when it spreads.
+func (cs *CustomScheduler) extendedResourceBonus(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	pack := packsExtendedResources(cs.scoreModeOf(pod))
+	var bonus float64
+	for _, w := range cs.config().extendedResources {
+		request := requestAmount(requests, w.Name)
+		if request == 0 {
+			continue
+		}
+		allocatable := resourceAmount(nodeInfo.Allocatable, w.Name)
+		if allocatable <= 0 {
+			continue
+		}
+		fraction := float64(resourceAmount(nodeInfo.Requested, w.Name)+request) / float64(allocatable)
+		if fraction > 1 {
+			fraction = 1
+		}
+		if !pack {
+			fraction = 1 - fraction
+		}
+		bonus += fraction * float64(w.Weight)
+	}
+	return int64(bonus)
+}
@@ -0,0 +1,46 @@
+package plugins
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const gpu v1.ResourceName = "nvidia.com/gpu"
+
+func makeGPUNodeInfo(name string, gpus, used int64) *framework.NodeInfo {
+	ni := makeNodeInfo(name, 8000, 1024)
+	ni.Allocatable.ScalarResources = map[v1.ResourceName]int64{gpu: gpus}
+	if used > 0 {
+		ni.AddPod(makeRequestingPod("default", name+"-running", name, v1.ResourceList{gpu: *resource.NewQuantity(used, resource.DecimalSI)}))
+	}
+	return ni
+}
+
+func TestCustomScheduler_ExtendedResourceBonus(t *testing.T) {
+	busy := makeGPUNodeInfo("busy", 8, 4)
+	idle := makeGPUNodeInfo("idle", 8, 0)
+	cpuOnly := makeNodeInfo("cpu-only", 8000, 1024)
+	gpuPod := makeRequestingPod("default", "train", "", v1.ResourceList{gpu: *resource.NewQuantity(2, resource.DecimalSI)})
+	weights := []ResourceWeight{{Name: gpu, Weight: 80}}
+
+	for _, tt := range []struct {
+		mode string
+		pod  *v1.Pod
+		want map[string]int64
+	}{
+		// busy would have 6 of 8 GPUs requested, idle 2 of 8.
+		{mode: leastMode, pod: gpuPod, want: map[string]int64{"busy": 60, "idle": 20, "cpu-only": 0}},
+		{mode: mostMode, pod: gpuPod, want: map[string]int64{"busy": 20, "idle": 60, "cpu-only": 0}},
+		{mode: leastMode, pod: makeRequestingPod("default", "web", "", nil), want: map[string]int64{"busy": 0, "idle": 0, "cpu-only": 0}},
+	} {
+		cs := &CustomScheduler{scoreMode: tt.mode, extendedResources: weights}
+		for _, ni := range []*framework.NodeInfo{busy, idle, cpuOnly} {
+			if got := cs.extendedResourceBonus(tt.pod, ni); got != tt.want[ni.Node().Name] {
+				t.Errorf("%s, pod %s on %s: expected a bonus of %d, got %d", tt.mode, tt.pod.Name, ni.Node().Name, tt.want[ni.Node().Name], got)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
+)
+
+// Failure policies accepted in CustomSchedulerArgs.FailurePolicies.
+const (
+	// failurePolicyIgnore fails open: the plugin carries on as if the
+	// unavailable integration were disabled.
+	failurePolicyIgnore string = "Ignore"
+	// failurePolicyFail fails closed: pods that depend on the unavailable
+	// integration are kept unschedulable until it is back.
+	failurePolicyFail string = "Fail"
+)
+
+// Optional integrations that depend on something outside the cluster core,
+// as named in CustomSchedulerArgs.FailurePolicies.
+const (
+	integrationMetricsProvider  string = "metricsProvider"
+	integrationScorePolicy      string = "scorePolicy"
+	integrationKueue            string = "kueue"
+	integrationVolcano          string = "volcano"
+	integrationTrainingOperator string = "trainingOperator"
+	integrationPodGroup         string = "podGroup"
+)
+
+// errCRDMissing is why an integration whose CRD is not installed is unavailable.
+var errCRDMissing = errors.New("its CRD is not installed")
+
+// crdCheckInterval is how long discovery's answer on whether the CRD of an
+// integration is installed is trusted.
+const crdCheckInterval = 30 * time.Second
+
+var integrations = map[string]bool{
+	integrationMetricsProvider:  true,
+	integrationScorePolicy:      true,
+	integrationKueue:            true,
+	integrationVolcano:          true,
+	integrationTrainingOperator: true,
+	integrationPodGroup:         true,
+}
+
+// newFailurePolicies validates the failure policies by integration.
+func newFailurePolicies(policies map[string]string) (map[string]string, error) {
+	for integration, policy := range policies {
+		if !integrations[integration] {
+			return nil, fmt.Errorf("unknown integration %q in failurePolicies", integration)
+		}
+		if policy != failurePolicyIgnore && policy != failurePolicyFail {
+			return nil, fmt.Errorf("invalid failure policy of %s, got %s", integration, policy)
+		}
+	}
+	return policies, nil
+}
+
+// failClosed reports whether pods depending on integration must wait while
+// it is unavailable. Integrations fail open unless configured otherwise.
+func (cs *CustomScheduler) failClosed(integration string) bool {
+	return cs.failurePolicies[integration] == failurePolicyFail
+}
+
+// addIntegrationSynced registers the informer of gvr integration needs.
+// Unlike the informers PreFilter waits for, a custom resource informer never
+// syncs if its CRD is not installed, so it only affects the pods of its
+// integration.
+func (cs *CustomScheduler) addIntegrationSynced(integration string, gvr schema.GroupVersionResource, synced cache.InformerSynced) {
+	if cs.integrationsSynced == nil {
+		cs.integrationsSynced = map[string][]cache.InformerSynced{}
+		cs.integrationResources = map[string][]schema.GroupVersionResource{}
+	}
+	cs.integrationsSynced[integration] = append(cs.integrationsSynced[integration], synced)
+	cs.integrationResources[integration] = append(cs.integrationResources[integration], gvr)
+}
+
+// integrationAvailable reports whether the informers of integration synced.
+func (cs *CustomScheduler) integrationAvailable(integration string) bool {
+	for _, synced := range cs.integrationsSynced[integration] {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// integrationNotSynced returns the status of a pod that depends on an
+// integration whose informers have not synced. If its CRDs are installed,
+// the informers are still syncing, as after every restart, and the pod is
+// Unschedulable until they are: scheduling it meanwhile would, e.g., place
+// the members of a gang one by one. Otherwise the integration is
+// unavailable.
+func (cs *CustomScheduler) integrationNotSynced(integration string, pod *v1.Pod) *framework.Status {
+	if cs.crdsInstalled(integration) {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("waiting for the custom resources of the %s integration to sync", integration))
+	}
+	return cs.integrationUnavailable(integration, pod, errCRDMissing)
+}
+
+// crdsInstalled reports whether discovery serves any resource of
+// integration. Without discovery, they are taken as missing.
+func (cs *CustomScheduler) crdsInstalled(integration string) bool {
+	if cs.discovery == nil {
+		return false
+	}
+	for _, gvr := range cs.integrationResources[integration] {
+		if cs.crds.installed(cs.discovery, gvr, cs.clock) {
+			return true
+		}
+	}
+	return false
+}
+
+// crdCache remembers whether the resources of CRDs are served, for
+// crdCheckInterval, so that pods of an integration whose informers have
+// not synced do not each query discovery.
+type crdCache struct {
+	mu      sync.Mutex
+	checked map[schema.GroupVersionResource]crdCheck
+}
+
+type crdCheck struct {
+	installed bool
+	at        time.Time
+}
+
+// installed reports whether d serves gvr. Errors other than the group
+// version not being found leave the CRD presumed installed, so pods wait
+// rather than lose gang semantics while the API server is unreachable.
+func (c *crdCache) installed(d discovery.DiscoveryInterface, gvr schema.GroupVersionResource, clk clock.PassiveClock) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := clk.Now()
+	if check, ok := c.checked[gvr]; ok && now.Sub(check.at) < crdCheckInterval {
+		return check.installed
+	}
+	installed := true
+	resources, err := d.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	switch {
+	case apierrors.IsNotFound(err):
+		installed = false
+	case err == nil:
+		installed = false
+		for _, r := range resources.APIResources {
+			if r.Name == gvr.Resource {
+				installed = true
+				break
+			}
+		}
+	}
+	if c.checked == nil {
+		c.checked = map[schema.GroupVersionResource]crdCheck{}
+	}
+	c.checked[gvr] = crdCheck{installed: installed, at: now}
+	return installed
+}
+
+// integrationUnavailable returns the status of a pod that depends on an
+// unavailable integration: Unschedulable if the integration fails closed,
+// nil if it fails open.
+func (cs *CustomScheduler) integrationUnavailable(integration string, pod *v1.Pod, err error) *framework.Status {
+	if cs.failClosed(integration) {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("%s integration is unavailable: %v", integration, err))
+	}
+	cs.logger().V(2).Info("Integration is unavailable, ignoring it", "integration", integration, "pod", klog.KObj(pod), "err", err)
+	return nil
+}
+
+// soloGroup is the group of a pod scheduled without gang semantics because
+// the CRD of the integration that knows its group is not installed and the
+// integration fails open.
+func soloGroup(pod *v1.Pod) *podGroup {
+	return &podGroup{
+		name:         pod.Name,
+		namespace:    pod.Namespace,
+		minAvailable: 1,
+		source:       soloSource,
+		selector:     labels.SelectorFromSet(pod.Labels),
+		member: func(p *v1.Pod) bool {
+			return p.Namespace == pod.Namespace && p.Name == pod.Name
+		},
+	}
+}
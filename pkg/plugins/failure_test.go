@@ -0,0 +1,109 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/scorepolicy"
+)
+
+func TestNewFailurePolicies(t *testing.T) {
+	if _, err := newFailurePolicies(map[string]string{integrationKueue: failurePolicyFail, integrationScorePolicy: failurePolicyIgnore}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := newFailurePolicies(map[string]string{"unknown": failurePolicyFail}); err == nil {
+		t.Error("expected an error for an unknown integration")
+	}
+	if _, err := newFailurePolicies(map[string]string{integrationVolcano: "Retry"}); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestCustomScheduler_UnavailableIntegrations(t *testing.T) {
+	notSynced := []cache.InformerSynced{func() bool { return false }}
+	volcanoPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "p",
+		Namespace:   "default",
+		Annotations: map[string]string{volcanoGroupNameAnnotations[0]: "pg"},
+	}}
+	queuedPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "p",
+		Namespace: "default",
+		Labels:    map[string]string{kueueQueueNameLabel: "team-a"},
+	}}
+	// the CRDs are installed, but their informers have not synced yet, as
+	// after a restart.
+	installed := clientsetfake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	for _, gvr := range []schema.GroupVersionResource{workloadGVR, volcanoPodGroupGVR} {
+		installed.Resources = append(installed.Resources, &metav1.APIResourceList{
+			GroupVersion: gvr.GroupVersion().String(),
+			APIResources: []metav1.APIResource{{Name: gvr.Resource}},
+		})
+	}
+	missing := clientsetfake.NewSimpleClientset().Discovery()
+	for _, tt := range []struct {
+		name      string
+		policy    string
+		discovery discovery.DiscoveryInterface
+		want      framework.Code
+	}{
+		{name: "fail open", policy: failurePolicyIgnore, discovery: missing, want: framework.Success},
+		{name: "fail closed", policy: failurePolicyFail, discovery: missing, want: framework.Unschedulable},
+		{name: "fail open while syncing", policy: failurePolicyIgnore, discovery: installed, want: framework.Unschedulable},
+		{name: "without discovery", policy: failurePolicyIgnore, want: framework.Success},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{
+				clock:            testingclock.NewFakeClock(time.Now()),
+				discovery:        tt.discovery,
+				scoreMode:        leastMode,
+				pods:             &faultyPodLister{stale: []*v1.Pod{volcanoPod}},
+				workloads:        newFakeUnstructuredLister(t),
+				volcanoPodGroups: newFakeUnstructuredLister(t),
+				failurePolicies:  map[string]string{integrationKueue: tt.policy, integrationVolcano: tt.policy},
+				integrationsSynced: map[string][]cache.InformerSynced{
+					integrationKueue:   notSynced,
+					integrationVolcano: notSynced,
+				},
+				integrationResources: map[string][]schema.GroupVersionResource{
+					integrationKueue:   {workloadGVR},
+					integrationVolcano: {volcanoPodGroupGVR},
+				},
+			}
+			if got := cs.PreEnqueue(context.Background(), queuedPod); got.Code() != tt.want {
+				t.Errorf("PreEnqueue: expected %v, got %v", tt.want, got)
+			}
+			if _, got := cs.PreFilter(context.Background(), framework.NewCycleState(), volcanoPod); got.Code() != tt.want {
+				t.Errorf("PreFilter: expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_ScorePolicyFailClosed(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100)}
+	policy := scorePolicyFunc(func(ctx context.Context, req scorepolicy.Request) (map[string]int64, error) {
+		return nil, errors.New("connection refused")
+	})
+	cs := &CustomScheduler{
+		scoreMode:       leastMode,
+		nodes:           nodeInfos,
+		scorePolicy:     policy,
+		failurePolicies: map[string]string{integrationScorePolicy: failurePolicyFail},
+	}
+	if status := cs.PreScore(context.Background(), framework.NewCycleState(), &v1.Pod{}, []*v1.Node{nodeInfos[0].Node()}); status.Code() != framework.Error {
+		t.Errorf("expected Error, got %v", status)
+	}
+}
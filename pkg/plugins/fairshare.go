@@ -0,0 +1,124 @@
+package plugins
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/utils/clock"
+)
+
+// FairShareArgs configures Dominant Resource Fairness across namespaces.
+// Shares change as they are recomputed, so, as with aging, the queue order
+// they give is approximate: pods already queued are reordered only as they
+// move through the queue.
+type FairShareArgs struct {
+	// IntervalSeconds is how often the shares are recomputed, 10 by default.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+}
+
+// fairShare tracks the dominant resource share of every namespace: the
+// largest fraction of any cluster resource that its bound pods request.
+type fairShare struct {
+	pods     PodLister
+	nodes    listersv1.NodeLister
+	clock    clock.Clock
+	interval time.Duration
+
+	mu     sync.RWMutex
+	shares map[string]float64
+}
+
+func newFairShare(args FairShareArgs, c clock.Clock, pods PodLister, nodes listersv1.NodeLister) *fairShare {
+	f := &fairShare{pods: pods, nodes: nodes, clock: c, interval: time.Duration(args.IntervalSeconds) * time.Second}
+	if f.interval <= 0 {
+		f.interval = 10 * time.Second
+	}
+	return f
+}
+
+// run recomputes the shares every interval until ctx is done.
+func (f *fairShare) run(ctx context.Context) {
+	for {
+		if err := f.refresh(); err != nil {
+			klog.FromContext(ctx).Error(err, "Failed to compute the fair shares of namespaces")
+		}
+		timer := f.clock.NewTimer(f.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+func (f *fairShare) refresh() error {
+	nodes, err := f.nodes.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	capacity := v1.ResourceList{}
+	for _, n := range nodes {
+		addResources(capacity, n.Status.Allocatable)
+	}
+	pods, err := f.pods.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	used := map[string]v1.ResourceList{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || !isLive(p) {
+			continue
+		}
+		if used[p.Namespace] == nil {
+			used[p.Namespace] = v1.ResourceList{}
+		}
+		addResources(used[p.Namespace], resourcehelper.PodRequests(p, resourcehelper.PodResourcesOptions{}))
+	}
+
+	shares := map[string]float64{}
+	for namespace, requests := range used {
+		shares[namespace] = dominantShare(requests, capacity)
+	}
+	f.mu.Lock()
+	f.shares = shares
+	f.mu.Unlock()
+	return nil
+}
+
+// share returns the dominant resource share of namespace, 0 if it runs nothing.
+func (f *fairShare) share(namespace string) float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.shares[namespace]
+}
+
+// dominantShare returns the largest fraction of any resource in capacity
+// that requests take up.
+func dominantShare(requests, capacity v1.ResourceList) float64 {
+	var dominant float64
+	for name, requested := range requests {
+		total, ok := capacity[name]
+		if !ok || total.IsZero() {
+			continue
+		}
+		if share := float64(requested.MilliValue()) / float64(total.MilliValue()); share > dominant {
+			dominant = share
+		}
+	}
+	return dominant
+}
+
+func addResources(sum, list v1.ResourceList) {
+	for name, q := range list {
+		total := sum[name]
+		total.Add(q)
+		sum[name] = total
+	}
+}
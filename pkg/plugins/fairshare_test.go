@@ -0,0 +1,72 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func makeRequestingPod(namespace, name, nodeName string, requests v1.ResourceList) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: v1.PodSpec{
+			NodeName:   nodeName,
+			Containers: []v1.Container{{Resources: v1.ResourceRequirements{Requests: requests}}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestFairShare(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("10"),
+			v1.ResourceMemory: resource.MustParse("100Gi"),
+			"nvidia.com/gpu":  resource.MustParse("4"),
+		}},
+	}
+	pods := []*v1.Pod{
+		// cpu-heavy: 50% of CPU, 10% of memory.
+		makeRequestingPod("cpu-heavy", "a", "n1", v1.ResourceList{v1.ResourceCPU: resource.MustParse("5"), v1.ResourceMemory: resource.MustParse("10Gi")}),
+		// gpu-heavy: 10% of CPU, 75% of GPUs.
+		makeRequestingPod("gpu-heavy", "b", "n1", v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), "nvidia.com/gpu": resource.MustParse("3")}),
+		// pending pods take no share.
+		makeRequestingPod("idle", "c", "", v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")}),
+	}
+	f := newFairShare(FairShareArgs{}, testingclock.NewFakeClock(time.Now()), &faultyPodLister{stale: pods}, newNodeLister(t, node))
+	if err := f.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	for namespace, want := range map[string]float64{"cpu-heavy": 0.5, "gpu-heavy": 0.75, "idle": 0} {
+		if got := f.share(namespace); got != want {
+			t.Errorf("namespace %s: expected share %v, got %v", namespace, want, got)
+		}
+	}
+
+	now := time.Now()
+	queued := func(namespace string, priority int32, enqueued time.Time) *framework.QueuedPodInfo {
+		pod := makeRequestingPod(namespace, "p", "", nil)
+		pod.Spec.Priority = &priority
+		return &framework.QueuedPodInfo{PodInfo: &framework.PodInfo{Pod: pod}, Timestamp: enqueued}
+	}
+	cs := &CustomScheduler{fairShare: f}
+	for _, tt := range []struct {
+		name string
+		a, b *framework.QueuedPodInfo
+		want bool
+	}{
+		{name: "priority first", a: queued("gpu-heavy", 10, now), b: queued("idle", 0, now), want: true},
+		{name: "smaller share first", a: queued("cpu-heavy", 0, now), b: queued("gpu-heavy", 0, now.Add(-time.Minute)), want: true},
+		{name: "earlier first within a namespace", a: queued("idle", 0, now.Add(time.Minute)), b: queued("idle", 0, now), want: false},
+	} {
+		if got := cs.Less(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
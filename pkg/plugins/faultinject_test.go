@@ -0,0 +1,120 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+// faultyPodLister wraps a PodLister and simulates informer lag and lister errors.
+type faultyPodLister struct {
+	PodLister
+	// err, if set, is returned from every List call.
+	err error
+	// stale, if set, is served instead of the live cache to simulate a lagging informer.
+	stale []*v1.Pod
+	// delay is applied before every List call.
+	delay time.Duration
+}
+
+func (f *faultyPodLister) List(selector labels.Selector) ([]*v1.Pod, error) {
+	time.Sleep(f.delay)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.stale == nil {
+		return f.PodLister.List(selector)
+	}
+	var pods []*v1.Pod
+	for _, p := range f.stale {
+		if selector.Matches(labels.Set(p.Labels)) {
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
+
+// injectWriteFaults makes every create/update/patch against the fake client
+// take delay and then fail with err (if non-nil).
+func injectWriteFaults(client *clientsetfake.Clientset, delay time.Duration, err error) {
+	for _, verb := range []string{"create", "update", "patch"} {
+		client.PrependReactor(verb, "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			time.Sleep(delay)
+			return err != nil, nil, err
+		})
+	}
+}
+
+func makeGroupPods(group string, minAvailable, n int) []*v1.Pod {
+	return fixtures.GroupSpec{Name: group, Size: n, MinAvailable: minAvailable}.Pods()
+}
+
+func TestCustomScheduler_PreFilterFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		lister *faultyPodLister
+		want   framework.Code
+	}{
+		{
+			name:   "lister error",
+			lister: &faultyPodLister{err: errors.New("connection refused")},
+			want:   framework.Unschedulable,
+		},
+		{
+			name:   "stale cache misses members",
+			lister: &faultyPodLister{stale: makeGroupPods("g1", 3, 1)},
+			want:   framework.Unschedulable,
+		},
+		{
+			name:   "slow cache",
+			lister: &faultyPodLister{delay: 10 * time.Millisecond, stale: makeGroupPods("g1", 3, 3)},
+			want:   framework.Success,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: tt.lister}
+			pod := makeGroupPods("g1", 3, 1)[0]
+
+			_, status := cs.PreFilter(context.Background(), nil, pod)
+			if status.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, status.Code())
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_PostFilterFailingWrites(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3, Shape: fixtures.Shapes["small"]}.Pods()
+	fwk, err := plugintesting.NewFramework([]*framework.NodeInfo{makeNodeInfo("n1", 1000, 256*1024*1024)}, pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	injectWriteFaults(fwk.Client, 10*time.Millisecond, errors.New("etcdserver: request timed out"))
+	cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, capacityHints: true, capacityHintAnnotation: true}
+
+	// the capacity shortfall annotation fails to patch; the pod is still
+	// unschedulable for want of capacity rather than failing with an error.
+	_, status := cs.PostFilter(context.Background(), framework.NewCycleState(), pods[0], nil)
+	if status.Code() != framework.Unschedulable {
+		t.Errorf("expected Unschedulable, got %v: %v", status.Code(), status.Message())
+	}
+	got, err := fwk.Client.CoreV1().Pods("default").Get(context.Background(), pods[0].Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Annotations[capacityShortfallAnnotation]; ok {
+		t.Error("expected the failing patch not to annotate the pod")
+	}
+}
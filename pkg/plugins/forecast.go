@@ -0,0 +1,225 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
+	"my-scheduler-plugins/pkg/usage"
+)
+
+// ForecastArgs configures scoring by the free capacity nodes are predicted
+// to have over the expected runtime of a pod, extrapolated from the usage
+// history the metrics provider reports.
+type ForecastArgs struct {
+	// IntervalSeconds is how often the usage of every node is sampled, 60
+	// by default.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+	// HistorySamples is how many samples per node the trend is fitted to,
+	// 60 by default.
+	HistorySamples int `json:"historySamples,omitempty"`
+	// DefaultRuntimeSeconds is the horizon of pods without an
+	// nthu.scheduler/expected-runtime annotation, 3600 by default.
+	DefaultRuntimeSeconds int64 `json:"defaultRuntimeSeconds,omitempty"`
+	// Weight is the share, from 0 to 100, of the normalized score that the
+	// predicted free capacity makes up, 50 by default.
+	Weight *int64 `json:"weight,omitempty"`
+}
+
+// usageSample is the used fraction of the allocatable CPU and memory of a
+// node at a point in time.
+type usageSample struct {
+	at          time.Time
+	cpu, memory float64
+}
+
+// forecaster keeps a bounded usage history of every node.
+type forecaster struct {
+	metrics        usage.MetricsProvider
+	nodes          listersv1.NodeLister
+	clock          clock.Clock
+	interval       time.Duration
+	samples        int
+	defaultRuntime time.Duration
+	weight         int64
+
+	mu      sync.RWMutex
+	history map[string][]usageSample
+}
+
+func newForecaster(args ForecastArgs, metrics usage.MetricsProvider, c clock.Clock, nodes listersv1.NodeLister) (*forecaster, error) {
+	if metrics == nil {
+		return nil, fmt.Errorf("forecast requires a metricsProvider")
+	}
+	f := &forecaster{
+		metrics:        metrics,
+		nodes:          nodes,
+		clock:          c,
+		interval:       time.Duration(args.IntervalSeconds) * time.Second,
+		samples:        args.HistorySamples,
+		defaultRuntime: time.Duration(args.DefaultRuntimeSeconds) * time.Second,
+		weight:         50,
+	}
+	if f.interval <= 0 {
+		f.interval = time.Minute
+	}
+	if f.samples <= 0 {
+		f.samples = 60
+	}
+	if f.defaultRuntime <= 0 {
+		f.defaultRuntime = time.Hour
+	}
+	if w := args.Weight; w != nil {
+		if *w < 0 || *w > 100 {
+			return nil, fmt.Errorf("forecast weight must be between 0 and 100, got %d", *w)
+		}
+		f.weight = *w
+	}
+	return f, nil
+}
+
+// run samples every node every interval until ctx is done.
+func (f *forecaster) run(ctx context.Context) {
+	for {
+		if err := f.sample(ctx); err != nil {
+			klog.FromContext(ctx).Error(err, "Failed to sample the usage of nodes")
+		}
+		timer := f.clock.NewTimer(f.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+// sample records the current usage of every node and forgets deleted nodes.
+// Nodes whose usage is unavailable keep their history as it is.
+func (f *forecaster) sample(ctx context.Context) error {
+	nodes, err := f.nodes.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	now := f.clock.Now()
+	samples := map[string]usageSample{}
+	for _, n := range nodes {
+		u, err := f.metrics.NodeUsage(ctx, n.Name)
+		if err != nil {
+			klog.FromContext(ctx).Error(err, "Failed to sample the usage of node", "node", klog.KObj(n))
+			continue
+		}
+		samples[n.Name] = usageSample{
+			at:     now,
+			cpu:    fraction(u.MilliCPU, n.Status.Allocatable.Cpu().MilliValue()),
+			memory: fraction(u.Memory, n.Status.Allocatable.Memory().Value()),
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	history := make(map[string][]usageSample, len(nodes))
+	for _, n := range nodes {
+		h := f.history[n.Name]
+		if s, ok := samples[n.Name]; ok {
+			h = append(h, s)
+		}
+		if len(h) > f.samples {
+			h = append([]usageSample(nil), h[len(h)-f.samples:]...)
+		}
+		if len(h) > 0 {
+			history[n.Name] = h
+		}
+	}
+	f.history = history
+	return nil
+}
+
+// fraction returns used/allocatable, or 1 if nothing is allocatable.
+func fraction(used, allocatable int64) float64 {
+	if allocatable <= 0 {
+		return 1
+	}
+	return float64(used) / float64(allocatable)
+}
+
+// predictedFree returns the smallest fraction of CPU or memory predicted to
+// be free on node over the next horizon, following the linear trend of its
+// usage history. Both the trend's value at the end of the horizon and the
+// latest sample bound it, so a falling trend never makes a busy node look
+// free. It returns false if the node has too little history for a trend.
+func (f *forecaster) predictedFree(node string, horizon time.Duration) (float64, bool) {
+	f.mu.RLock()
+	h := f.history[node]
+	f.mu.RUnlock()
+	if len(h) < 2 {
+		return 0, false
+	}
+	at := f.clock.Now().Add(horizon)
+	latest := h[len(h)-1]
+	used := func(value func(usageSample) float64) float64 {
+		predicted := extrapolate(h, value, at)
+		if current := value(latest); current > predicted {
+			return current
+		}
+		return predicted
+	}
+	free := 1 - used(func(s usageSample) float64 { return s.cpu })
+	if memory := 1 - used(func(s usageSample) float64 { return s.memory }); memory < free {
+		free = memory
+	}
+	if free < 0 {
+		return 0, true
+	}
+	if free > 1 {
+		return 1, true
+	}
+	return free, true
+}
+
+// extrapolate fits a least-squares line to the values of the samples over
+// time and returns its value at t.
+func extrapolate(samples []usageSample, value func(usageSample) float64, t time.Time) float64 {
+	origin := samples[0].at
+	var sumX, sumY, sumXX, sumXY float64
+	for _, s := range samples {
+		x, y := s.at.Sub(origin).Seconds(), value(s)
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return sumY / n
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	return intercept + slope*t.Sub(origin).Seconds()
+}
+
+// horizon is how far ahead the usage of nodes matters to pod.
+func (f *forecaster) horizon(pod *v1.Pod) time.Duration {
+	if runtime, ok := expectedRuntime(pod); ok && runtime > 0 {
+		return runtime
+	}
+	return f.defaultRuntime
+}
+
+// blend mixes the predicted free capacity of node into its normalized
+// score. Nodes without enough history keep their score.
+func (f *forecaster) blend(pod *v1.Pod, node string, score int64) int64 {
+	free, ok := f.predictedFree(node, f.horizon(pod))
+	if !ok {
+		return score
+	}
+	return (score*(100-f.weight) + int64(free*float64(framework.MaxNodeScore))*f.weight) / 100
+}
@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/usage"
+)
+
+// fakeMetrics reports the usage set per node.
+type fakeMetrics map[string]*usage.NodeUsage
+
+func (m fakeMetrics) NodeUsage(ctx context.Context, nodeName string) (*usage.NodeUsage, error) {
+	u, ok := m[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("no usage of node %s", nodeName)
+	}
+	return u, nil
+}
+
+func TestForecaster(t *testing.T) {
+	clock := testingclock.NewFakeClock(time.Now())
+	// both nodes are 30% busy now, but rising grows by 10% a minute.
+	rising := makeNodeInfo("rising", 1000, 1000).Node()
+	steady := makeNodeInfo("steady", 1000, 1000).Node()
+	metrics := fakeMetrics{}
+	f, err := newForecaster(ForecastArgs{HistorySamples: 3}, metrics, clock, newNodeLister(t, rising, steady))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, busy := range []int64{100, 200, 300} {
+		metrics["rising"] = &usage.NodeUsage{MilliCPU: busy, Memory: 100}
+		metrics["steady"] = &usage.NodeUsage{MilliCPU: 300, Memory: 300}
+		if err := f.sample(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		clock.Step(time.Minute)
+	}
+	clock.Step(-time.Minute)
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Annotations: map[string]string{expectedRuntimeAnnotation: "5m"}}}
+	for _, tt := range []struct {
+		node string
+		want float64
+	}{
+		{node: "rising", want: 0.2},
+		{node: "steady", want: 0.7},
+	} {
+		got, ok := f.predictedFree(tt.node, f.horizon(pod))
+		if !ok || got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("node %s: expected %v free, got %v (%v)", tt.node, tt.want, got, ok)
+		}
+	}
+	if got := f.blend(pod, "rising", 100); got != 60 {
+		t.Errorf("expected the rising node to score 60, got %d", got)
+	}
+	if got := f.blend(pod, "unknown", 100); got != 100 {
+		t.Errorf("expected a node without history to keep its score, got %d", got)
+	}
+
+	// the history is bounded and forgets nodes that are gone.
+	metrics["rising"] = &usage.NodeUsage{MilliCPU: 400}
+	f.nodes = newNodeLister(t, rising)
+	if err := f.sample(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.history["rising"]); got != 3 {
+		t.Errorf("expected 3 samples, got %d", got)
+	}
+	if _, ok := f.history["steady"]; ok {
+		t.Errorf("expected the history of a deleted node to be dropped")
+	}
+
+	if _, err := newForecaster(ForecastArgs{}, nil, clock, nil); err == nil {
+		t.Errorf("expected an error without a metrics provider")
+	}
+}
@@ -0,0 +1,382 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// podGroup is the gang a pod belongs to.
+type podGroup struct {
+	name         string
+	namespace    string
+	minAvailable int
+	// source, if set, is where the group comes from other than the gang
+	// labels, e.g. a Volcano PodGroup, which qualifies its key.
+	source string
+	// queue is the queue the group was submitted to, if its source has one.
+	queue string
+	// selector preselects candidate members from the pod cache, and member,
+	// if set, further narrows them down. indexLabel, if set, is the pod
+	// index that looks them up without a scan, by namespace and indexValue,
+	// or else name.
+	selector   labels.Selector
+	indexLabel string
+	indexValue string
+	member     func(*v1.Pod) bool
+	// fromLabels is set when minAvailable comes from the pod's own labels,
+	// which other members may contradict.
+	fromLabels bool
+	// scheduleTimeout, if set, is how long the gang waits in Permit for the
+	// rest of its members, and how long it may take to reach minAvailable
+	// members before it times out.
+	scheduleTimeout time.Duration
+	// priority, if set, orders the group in the admission gate instead of
+	// the priority of its pods.
+	priority *int32
+	// nodePool, if set, is the pool of nodes all members must run in.
+	nodePool string
+	// quota, if set, bounds what the admitted members may request in total.
+	quota v1.ResourceList
+}
+
+// Sources of groups that do not come from the gang labels.
+const (
+	podGroupSource = "podgroup"
+	volcanoSource  = "volcano"
+	soloSource     = "pod"
+)
+
+// key identifies group in the gang state kept across Permit, Reserve, Bind,
+// preemption and the PodGroupManager. Groups of the same name from different
+// sources, such as a label gang and a training job, are distinct.
+func (g *podGroup) key() string {
+	if g.source == "" {
+		return g.namespace + "/" + g.name
+	}
+	return g.namespace + "/" + g.source + "/" + g.name
+}
+
+// defaultMaxMinAvailable bounds minAvailable unless CustomSchedulerArgs.MaxMinAvailable is set.
+const defaultMaxMinAvailable = 10000
+
+// groupOf resolves the group of pod and validates its minAvailable.
+func (cs *CustomScheduler) groupOf(pod *v1.Pod) (*podGroup, *framework.Status) {
+	group, status := cs.resolveGroup(pod)
+	if !status.IsSuccess() {
+		return nil, status
+	}
+	maxMinAvailable := cs.minAvailableBound()
+	if group.minAvailable <= 0 || group.minAvailable > maxMinAvailable {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("minAvailable of pod group %s must be between 1 and %d, got %d", group.name, maxMinAvailable, group.minAvailable))
+	}
+	return group, nil
+}
+
+// GroupOf returns the namespace/name and minAvailable of the group of pod,
+// resolved the way PreFilter resolves it, so that companions such as the
+// descheduler agree with the scheduler on gangs. ok is false for pods
+// without gang semantics or with an invalid group.
+func (cs *CustomScheduler) GroupOf(pod *v1.Pod) (key string, minAvailable int, ok bool) {
+	if cs.isUngrouped(pod) {
+		return "", 0, false
+	}
+	group, status := cs.groupOf(pod)
+	if !status.IsSuccess() {
+		return "", 0, false
+	}
+	return group.key(), group.minAvailable, true
+}
+
+// maxMinAvailableDigits bounds the length of a minAvailable label, leading zeros included.
+const maxMinAvailableDigits = 10
+
+// maxQuotedLength bounds user-provided values quoted in statuses, events and logs.
+const maxQuotedLength = 63
+
+// truncate shortens s to maxQuotedLength for statuses, events and logs.
+func truncate(s string) string {
+	if len(s) <= maxQuotedLength {
+		return s
+	}
+	return s[:maxQuotedLength] + "..."
+}
+
+// validateGroupName checks that name is usable as a map key, log field and
+// metric label: a non-empty label value of at most 63 characters.
+func validateGroupName(name string) error {
+	if name == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if errs := validation.IsValidLabelValue(name); len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// minAvailableBound is the largest accepted minAvailable.
+func (cs *CustomScheduler) minAvailableBound() int {
+	if bound := cs.config().maxMinAvailable; bound > 0 {
+		return bound
+	}
+	return defaultMaxMinAvailable
+}
+
+// resolveGroup resolves the group of pod, preferring a PodGroup custom
+// resource, then a Volcano PodGroup and then an owning training CR when those
+// integrations are enabled, and falling back to the podGroup and
+// minAvailable labels.
+func (cs *CustomScheduler) resolveGroup(pod *v1.Pod) (*podGroup, *framework.Status) {
+	// skipped is set when an unavailable integration that fails open would
+	// have decided the group.
+	skipped := false
+	if cs.podGroups != nil {
+		if name := pod.GetLabels()[podGroupLabel]; name != "" {
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+					fmt.Sprintf("invalid %s label %q: %s", podGroupLabel, truncate(name), strings.Join(errs, "; ")))
+			}
+			if cs.integrationAvailable(integrationPodGroup) {
+				return cs.crdGroup(pod.Namespace, name)
+			}
+			if status := cs.integrationNotSynced(integrationPodGroup, pod); status != nil {
+				return nil, status
+			}
+			skipped = true
+		}
+	}
+	if cs.volcanoPodGroups != nil && !skipped {
+		if name := volcanoGroupName(pod); name != "" {
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+					fmt.Sprintf("invalid Volcano PodGroup name %q: %s", truncate(name), strings.Join(errs, "; ")))
+			}
+			if cs.integrationAvailable(integrationVolcano) {
+				return cs.volcanoGroup(pod.Namespace, name)
+			}
+			if status := cs.integrationNotSynced(integrationVolcano, pod); status != nil {
+				return nil, status
+			}
+			skipped = true
+		}
+	}
+	if cs.trainingJobs != nil && !skipped {
+		if kind, name, ok := trainingJobOwner(pod); ok {
+			if cs.integrationAvailable(integrationTrainingOperator) {
+				return cs.trainingGroup(pod, kind, name)
+			}
+			if status := cs.integrationNotSynced(integrationTrainingOperator, pod); status != nil {
+				return nil, status
+			}
+			skipped = true
+		}
+	}
+
+	name := pod.GetLabels()[groupNameLabel]
+	if name == "" && skipped {
+		return soloGroup(pod), nil
+	}
+	if err := validateGroupName(name); err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("invalid %s label %q: %v", groupNameLabel, truncate(name), err))
+	}
+	minAvailable, status := labelMinAvailable(pod, name)
+	if status != nil {
+		return nil, status
+	}
+	timeout, status := scheduleTimeoutOf(pod, name)
+	if status != nil {
+		return nil, status
+	}
+	priority, status := labelGroupPriority(pod, name)
+	if status != nil {
+		return nil, status
+	}
+	pool, status := labelNodePool(pod, name)
+	if status != nil {
+		return nil, status
+	}
+	quota, status := labelGroupQuota(pod, name)
+	if status != nil {
+		return nil, status
+	}
+	return &podGroup{
+		name:            name,
+		namespace:       pod.Namespace,
+		minAvailable:    minAvailable,
+		selector:        labels.SelectorFromSet(labels.Set{groupNameLabel: name}),
+		indexLabel:      groupNameLabel,
+		fromLabels:      true,
+		scheduleTimeout: timeout,
+		priority:        priority,
+		nodePool:        pool,
+		quota:           quota,
+	}, nil
+}
+
+// ValidateGroupLabels checks the gang labels of a pod labelled with a
+// podGroup, the way PreFilter would, so that companions such as the
+// admission webhook can reject malformed pods before they are scheduled.
+// Pods without a podGroup label are valid.
+func ValidateGroupLabels(pod *v1.Pod) error {
+	name, ok := pod.Labels[groupNameLabel]
+	if !ok {
+		return nil
+	}
+	if err := validateGroupName(name); err != nil {
+		return fmt.Errorf("invalid %s label %q: %v", groupNameLabel, truncate(name), err)
+	}
+	minAvailable, status := labelMinAvailable(pod, name)
+	if status != nil {
+		return status.AsError()
+	}
+	if minAvailable <= 0 {
+		return fmt.Errorf("%s of pod group %s must be positive, got %d", minAvailableLabel, name, minAvailable)
+	}
+	if _, status := scheduleTimeoutOf(pod, name); status != nil {
+		return status.AsError()
+	}
+	if _, status := labelGroupPriority(pod, name); status != nil {
+		return status.AsError()
+	}
+	if _, status := labelNodePool(pod, name); status != nil {
+		return status.AsError()
+	}
+	if _, status := labelGroupQuota(pod, name); status != nil {
+		return status.AsError()
+	}
+	return nil
+}
+
+// groupMembers lists the live pods that belong to group, which are in its
+// namespace: groups of the same name in other namespaces are distinct.
+// Membership is derived from the informer cache on every call and keyed by
+// pod UID, so a pod that re-enters scheduling, or shows up twice while the
+// cache catches up, is counted once. With profileMembersOnly, pods of other
+// schedulers are not members.
+func (cs *CustomScheduler) groupMembers(group *podGroup) ([]*v1.Pod, error) {
+	pods, err := cs.candidateMembers(group)
+	if err != nil {
+		return nil, err
+	}
+	var members []*v1.Pod
+	seen := map[string]bool{}
+	for _, p := range pods {
+		if p.Namespace != group.namespace || !isLive(p) || p.DeletionTimestamp != nil || (group.member != nil && !group.member(p)) {
+			continue
+		}
+		if cs.profileMembersOnly && schedulerNameOf(p) != cs.profileName() {
+			continue
+		}
+		key := podKey(p)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		members = append(members, p)
+	}
+	return members, nil
+}
+
+// schedulerNameOf returns the scheduler of pod, which the API server
+// defaults to the default scheduler.
+func schedulerNameOf(pod *v1.Pod) string {
+	if pod.Spec.SchedulerName == "" {
+		return v1.DefaultSchedulerName
+	}
+	return pod.Spec.SchedulerName
+}
+
+// podKey identifies pod by UID, or by namespace and name for pods that have
+// not been persisted yet.
+func podKey(pod *v1.Pod) string {
+	if pod.UID != "" {
+		return string(pod.UID)
+	}
+	return pod.Namespace + "/" + pod.Name
+}
+
+// isLive reports whether pod counts towards its group: Pending and Running
+// pods do, while Succeeded, Failed (including evicted) pods are leftovers of
+// earlier runs. Pods in the Unknown phase may be gone with their node, so
+// they are conservatively not counted.
+func isLive(pod *v1.Pod) bool {
+	switch pod.Status.Phase {
+	case v1.PodPending, v1.PodRunning:
+		return true
+	case "":
+		// not yet reported by the kubelet, which the API server defaults to Pending.
+		return true
+	default:
+		return false
+	}
+}
+
+// Policies for the members of a group declaring different minAvailable values.
+const (
+	// conflictMax uses the largest declared value, so a gang is never admitted early.
+	conflictMax = "Max"
+	// conflictPodGroup uses the minMember of the PodGroup, or else the
+	// Volcano PodGroup, of the same name, and keeps the group unschedulable
+	// until one exists.
+	conflictPodGroup = "PodGroup"
+	// conflictReject keeps the group unschedulable until its labels agree.
+	conflictReject = "Reject"
+)
+
+func validConflictPolicy(policy string) bool {
+	return policy == "" || policy == conflictMax || policy == conflictPodGroup || policy == conflictReject
+}
+
+// reconcileMinAvailable settles the minAvailable of a label-based group
+// whose members disagree on it, following the configured conflict policy.
+// Values that fail validation are left for their own pods to report.
+func (cs *CustomScheduler) reconcileMinAvailable(group *podGroup, members []*v1.Pod) *framework.Status {
+	if !group.fromLabels {
+		return nil
+	}
+	largest, conflict := group.minAvailable, false
+	for _, p := range members {
+		v, status := labelMinAvailable(p, group.name)
+		if status != nil || v <= 0 || v > cs.minAvailableBound() || v == group.minAvailable {
+			continue
+		}
+		conflict = true
+		if v > largest {
+			largest = v
+		}
+	}
+	if !conflict {
+		return nil
+	}
+
+	switch cs.config().minAvailableConflictPolicy {
+	case conflictReject:
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("members of pod group %s declare different %s values", group.name, minAvailableLabel))
+	case conflictPodGroup:
+		if cs.podGroups != nil {
+			if pg, status := cs.crdGroup(group.namespace, group.name); status.IsSuccess() {
+				group.minAvailable = pg.minAvailable
+				return nil
+			}
+		}
+		if cs.volcanoPodGroups != nil {
+			if pg, status := cs.volcanoGroup(group.namespace, group.name); status.IsSuccess() {
+				group.minAvailable = pg.minAvailable
+				return nil
+			}
+		}
+		// wait for a PodGroup to settle the conflict rather than guess.
+		return framework.NewStatus(framework.Unschedulable,
+			fmt.Sprintf("members of pod group %s declare different %s values and no PodGroup %s/%s settles them", group.name, minAvailableLabel, group.namespace, group.name))
+	}
+	group.minAvailable = largest
+	return nil
+}
@@ -0,0 +1,227 @@
+package plugins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestCustomScheduler_PreFilterInvalidMinAvailable(t *testing.T) {
+	tests := []struct {
+		name         string
+		minAvailable string
+		max          int
+		wantMessage  string
+	}{
+		{name: "not an integer", minAvailable: "three", wantMessage: "must be an integer"},
+		{name: "empty", minAvailable: "", wantMessage: "must be an integer"},
+		{name: "zero", minAvailable: "0", wantMessage: "between 1 and 10000"},
+		{name: "negative", minAvailable: "-2", wantMessage: "between 1 and 10000"},
+		{name: "above the default bound", minAvailable: "10001", wantMessage: "between 1 and 10000"},
+		{name: "above a configured bound", minAvailable: "65", max: 64, wantMessage: "between 1 and 64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := events.NewFakeRecorder(10)
+			fh, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				},
+				"default-scheduler",
+				wait.NeverStop,
+				frameworkruntime.WithEventRecorder(recorder),
+			)
+			if err != nil {
+				t.Fatalf("fail to create framework: %s", err)
+			}
+			cs := &CustomScheduler{handle: fh, scoreMode: leastMode, maxMinAvailable: tt.max}
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Labels: map[string]string{groupNameLabel: "g1", minAvailableLabel: tt.minAvailable}}}
+
+			_, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pod)
+			if status.Code() != framework.UnschedulableAndUnresolvable || !strings.Contains(status.Message(), tt.wantMessage) {
+				t.Errorf("expected UnschedulableAndUnresolvable with %q, got %v", tt.wantMessage, status)
+			}
+			select {
+			case e := <-recorder.Events:
+				if !strings.Contains(e, invalidPodGroupReason) {
+					t.Errorf("unexpected event %q", e)
+				}
+			default:
+				t.Errorf("expected an %s event", invalidPodGroupReason)
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_PreFilterMinAvailableConflict(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 2}.Pods()
+	pods[2].Labels[minAvailableLabel] = "4"
+	lister := &faultyPodLister{stale: pods}
+	volcanoPodGroups := newFakeUnstructuredLister(t, makeVolcanoPodGroup("g1", 3))
+	podGroups := newFakeUnstructuredLister(t, makePodGroup("g1", map[string]interface{}{"minMember": int64(3)}))
+
+	tests := []struct {
+		name             string
+		policy           string
+		podGroups        PodGroupGetter
+		volcanoPodGroups PodGroupGetter
+		want             framework.Code
+	}{
+		// the largest value, 4, exceeds the 3 members.
+		{name: "default", want: framework.Unschedulable},
+		{name: "max", policy: conflictMax, want: framework.Unschedulable},
+		// the PodGroup asks for the 3 members that exist.
+		{name: "PodGroup", policy: conflictPodGroup, podGroups: podGroups, want: framework.Success},
+		{name: "Volcano PodGroup", policy: conflictPodGroup, volcanoPodGroups: volcanoPodGroups, want: framework.Success},
+		{name: "no PodGroup", policy: conflictPodGroup, want: framework.Unschedulable},
+		{name: "reject", policy: conflictReject, want: framework.UnschedulableAndUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: lister, minAvailableConflictPolicy: tt.policy}
+			if tt.podGroups != nil {
+				cs.podGroups = tt.podGroups
+			}
+			if tt.volcanoPodGroups != nil {
+				cs.volcanoPodGroups = tt.volcanoPodGroups
+			}
+			if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, status)
+			}
+		})
+	}
+
+	// without a PodGroup, the largest value is not applied quietly even
+	// though the members would reach it.
+	agreeing := fixtures.GroupSpec{Name: "g2", Namespace: "default", Size: 3, MinAvailable: 2}.Pods()
+	agreeing[2].Labels[minAvailableLabel] = "3"
+	cs := &CustomScheduler{scoreMode: leastMode, pods: &faultyPodLister{stale: agreeing}, minAvailableConflictPolicy: conflictPodGroup}
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), agreeing[0]); status.Code() != framework.Unschedulable {
+		t.Errorf("expected Unschedulable without a PodGroup, got %v", status)
+	}
+}
+
+func TestCustomScheduler_GroupMembersIdempotent(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 3}.Pods()
+	pods[0].UID, pods[1].UID = "uid-0", "uid-1"
+	// the informer briefly serves a rescheduled pod twice.
+	requeued := pods[1].DeepCopy()
+	// a deleted member is still cached while its replacement is created.
+	deleted := pods[0].DeepCopy()
+	deleted.UID, deleted.DeletionTimestamp = "uid-old", &metav1.Time{}
+	cs := &CustomScheduler{scoreMode: leastMode, pods: &faultyPodLister{stale: append(pods, requeued, deleted)}}
+
+	group, status := cs.groupOf(pods[0])
+	if !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	members, err := cs.groupMembers(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Errorf("expected 2 members, got %d", len(members))
+	}
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != framework.Unschedulable {
+		t.Errorf("expected the gang of 2 out of 3 to stay Unschedulable, got %v", status)
+	}
+}
+
+func TestCustomScheduler_GroupMembersNamespaceAndPhase(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 3}.Pods()
+	pods[1].Status.Phase = v1.PodRunning
+	// a finished run and a group of the same name elsewhere must not count.
+	succeeded := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 1, MinAvailable: 3}.Pods()[0]
+	succeeded.Name, succeeded.Status.Phase = "g1-done", v1.PodSucceeded
+	failed := succeeded.DeepCopy()
+	failed.Name, failed.Status.Phase = "g1-failed", v1.PodFailed
+	elsewhere := fixtures.GroupSpec{Name: "g1", Namespace: "other", Size: 3, MinAvailable: 3}.Pods()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, p := range append(append(pods, succeeded, failed), elsewhere...) {
+		if err := indexer.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, lister := range map[string]PodLister{
+		"namespaced lister": listersv1.NewPodLister(indexer),
+		"plain lister":      &faultyPodLister{stale: append(append(pods, succeeded, failed), elsewhere...)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: lister}
+			if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != framework.Unschedulable {
+				t.Errorf("expected the gang of 2 out of 3 to stay Unschedulable, got %v", status)
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_GroupMembersProfileOnly(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 3, SchedulerName: plugintesting.ProfileName}.Pods()
+	// a member left to the default scheduler, which leaves schedulerName unset.
+	pods = append(pods, fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods()[2])
+	fwk, err := plugintesting.NewFramework(nil, pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		profileMembersOnly bool
+		want               framework.Code
+	}{
+		{profileMembersOnly: false, want: framework.Success},
+		{profileMembersOnly: true, want: framework.Unschedulable},
+	} {
+		cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, profileMembersOnly: tt.profileMembersOnly}
+		if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != tt.want {
+			t.Errorf("profileMembersOnly %t: expected %v, got %v: %s", tt.profileMembersOnly, tt.want, status.Code(), status.Message())
+		}
+	}
+}
+
+func TestCustomScheduler_PreFilterInvalidGroupName(t *testing.T) {
+	podGroups := newFakeUnstructuredLister(t)
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+	}{
+		{
+			name: "empty group label",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{groupNameLabel: "", minAvailableLabel: "1"}}},
+		},
+		{
+			name: "overlong minAvailable",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{groupNameLabel: "g1", minAvailableLabel: "000000000001"}}},
+		},
+		{
+			name: "invalid Volcano PodGroup name",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{volcanoGroupNameAnnotations[0]: strings.Repeat("Bad_", 1000)}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: &faultyPodLister{stale: []*v1.Pod{}}, volcanoPodGroups: podGroups}
+			_, status := cs.PreFilter(context.Background(), framework.NewCycleState(), tt.pod)
+			if status.Code() != framework.UnschedulableAndUnresolvable {
+				t.Errorf("expected UnschedulableAndUnresolvable, got %v", status)
+			}
+			if len(status.Message()) > 1024 {
+				t.Errorf("status message is not bounded: %d bytes", len(status.Message()))
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const groupSnapshotStateKey = framework.StateKey(Name + "/group-snapshot")
+
+// groupSnapshot is the group of the pod being scheduled and its members, by
+// pod key, as PreFilter listed them, so that later phases of the cycle need
+// not list them again.
+type groupSnapshot struct {
+	group   *podGroup
+	members map[string]*v1.Pod
+}
+
+func (s *groupSnapshot) Clone() framework.StateData {
+	members := make(map[string]*v1.Pod, len(s.members))
+	for key, p := range s.members {
+		members[key] = p
+	}
+	return &groupSnapshot{group: s.group, members: members}
+}
+
+// pods returns the members, ordered by name.
+func (s *groupSnapshot) pods() []*v1.Pod {
+	pods := make([]*v1.Pod, 0, len(s.members))
+	for _, p := range s.members {
+		pods = append(pods, p)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods
+}
+
+// count is the number of members.
+func (s *groupSnapshot) count() int {
+	return len(s.members)
+}
+
+// matches reports whether pod belongs to the group of the snapshot.
+func (s *groupSnapshot) matches(pod *v1.Pod) bool {
+	if pod.Namespace != s.group.namespace || !s.group.selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	return s.group.member == nil || s.group.member(pod)
+}
+
+// writeGroupSnapshot records group and its members in state.
+func writeGroupSnapshot(state *framework.CycleState, group *podGroup, members []*v1.Pod) {
+	if state == nil {
+		return
+	}
+	s := &groupSnapshot{group: group, members: make(map[string]*v1.Pod, len(members))}
+	for _, p := range members {
+		s.members[podKey(p)] = p
+	}
+	state.Write(groupSnapshotStateKey, s)
+}
+
+func readGroupSnapshot(state *framework.CycleState) *groupSnapshot {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(groupSnapshotStateKey)
+	if err != nil {
+		return nil
+	}
+	return data.(*groupSnapshot)
+}
+
+// groupAndMembers returns the group of pod and its members from the
+// snapshot PreFilter wrote to state, resolving and listing them only if
+// there is none.
+func (cs *CustomScheduler) groupAndMembers(state *framework.CycleState, pod *v1.Pod) (*podGroup, []*v1.Pod, *framework.Status) {
+	if s := readGroupSnapshot(state); s != nil {
+		return s.group, s.pods(), nil
+	}
+	group, status := cs.groupOf(pod)
+	if !status.IsSuccess() {
+		return nil, nil, status
+	}
+	members, err := cs.groupMembers(group)
+	if err != nil {
+		return nil, nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list pods: %v", err))
+	}
+	return group, members, nil
+}
+
+// addSnapshotMember and removeSnapshotMember keep the group snapshot in
+// state up to date as the framework adds and removes pods while it
+// simulates preemption.
+func addSnapshotMember(state *framework.CycleState, pod *v1.Pod) {
+	if s := readGroupSnapshot(state); s != nil && isLive(pod) && s.matches(pod) {
+		s.members[podKey(pod)] = pod
+	}
+}
+
+func removeSnapshotMember(state *framework.CycleState, pod *v1.Pod) {
+	if s := readGroupSnapshot(state); s != nil {
+		delete(s.members, podKey(pod))
+	}
+}
@@ -0,0 +1,59 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func TestCustomScheduler_GroupSnapshot(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods()
+	for _, p := range pods {
+		p.UID = types.UID(p.Name)
+	}
+	other := fixtures.GroupSpec{Name: "g2", Namespace: "default", Size: 1, MinAvailable: 1}.Pods()[0]
+	lister := &faultyPodLister{stale: append(pods, other)}
+	cs := &CustomScheduler{scoreMode: leastMode, clock: testingclock.NewFakeClock(time.Now()), pods: lister, nodes: fakeframework.NodeInfoLister{makeNodeInfo("n1", 1000, 1000)}}
+
+	state := framework.NewCycleState()
+	if _, status := cs.PreFilter(context.Background(), state, pods[0]); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	s := readGroupSnapshot(state)
+	if s == nil || s.count() != 3 || s.group.minAvailable != 3 {
+		t.Fatalf("expected a snapshot of 3 members with minAvailable 3, got %+v", s)
+	}
+
+	// the framework removes and adds back pods while it simulates preemption.
+	if status := cs.RemovePod(context.Background(), state, pods[0], &framework.PodInfo{Pod: pods[1]}, nil); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if status := cs.AddPod(context.Background(), state, pods[0], &framework.PodInfo{Pod: other}, nil); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if got := s.count(); got != 2 {
+		t.Errorf("expected 2 members after removing one and adding a pod of another group, got %d", got)
+	}
+	if status := cs.AddPod(context.Background(), state, pods[0], &framework.PodInfo{Pod: pods[1]}, nil); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if got := s.count(); got != 3 {
+		t.Errorf("expected 3 members after adding one back, got %d", got)
+	}
+
+	// later phases reuse the snapshot rather than listing pods again.
+	lister.err = errors.New("lister unavailable")
+	if status, _ := cs.Permit(context.Background(), state, pods[0], "n1"); !status.IsWait() {
+		t.Errorf("expected Permit to wait for the rest of the snapshot, got %v", status)
+	}
+	if status, _ := cs.Permit(context.Background(), framework.NewCycleState(), pods[0], "n1"); status.Code() != framework.Unschedulable {
+		t.Errorf("expected Permit to list pods without a snapshot, got %v", status)
+	}
+}
@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// scheduleTimeoutLabel is how long, in seconds, a labelled gang may take
+	// to reach minAvailable members, like spec.scheduleTimeoutSeconds of a
+	// PodGroup.
+	scheduleTimeoutLabel string = "scheduleTimeoutSeconds"
+	// groupTimedOutAnnotation is when the group of a pod timed out, so that
+	// controllers can recreate or fail the job.
+	groupTimedOutAnnotation string = "nthu.scheduler/pod-group-timed-out"
+	// groupTimedOutReason is the event reason for members of a timed out group.
+	groupTimedOutReason string = "PodGroupTimedOut"
+)
+
+// scheduleTimeoutOf returns the scheduleTimeoutLabel of pod, 0 if unset.
+func scheduleTimeoutOf(pod *v1.Pod, group string) (time.Duration, *framework.Status) {
+	value, ok := pod.Labels[scheduleTimeoutLabel]
+	if !ok {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 || len(value) > maxMinAvailableDigits {
+		return 0, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("invalid %s label %q of pod group %s: must be a non-negative integer", scheduleTimeoutLabel, truncate(value), group))
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// groupTimedOut returns when group timed out if it has a schedule timeout
+// and has been short of minAvailable members for longer than that since
+// its oldest member was created. Recreated members start the clock anew.
+func (cs *CustomScheduler) groupTimedOut(group *podGroup, members []*v1.Pod) (time.Time, bool) {
+	if group.scheduleTimeout <= 0 || len(members) >= group.minAvailable || len(members) == 0 {
+		return time.Time{}, false
+	}
+	oldest := members[0].CreationTimestamp.Time
+	for _, p := range members[1:] {
+		if p.CreationTimestamp.Time.Before(oldest) {
+			oldest = p.CreationTimestamp.Time
+		}
+	}
+	timedOut := oldest.Add(group.scheduleTimeout)
+	return timedOut, !cs.clock.Now().Before(timedOut)
+}
+
+// rejectTimedOutGroup keeps the members of group, which timed out at
+// timedOut, unschedulable with a distinct reason and, if enabled,
+// annotates those not annotated yet.
+func (cs *CustomScheduler) rejectTimedOutGroup(ctx context.Context, pod *v1.Pod, group *podGroup, members []*v1.Pod, timedOut time.Time) *framework.Status {
+	msg := fmt.Sprintf("pod group %s timed out at %s with %d of the %d members it needs (minAvailable)",
+		group.name, timedOut.UTC().Format(time.RFC3339), len(members), group.minAvailable)
+	if pod.Annotations[groupTimedOutAnnotation] == "" {
+		cs.recordGangEvent(pod, group, groupTimedOutReason, "PreFilter", msg)
+	}
+	if cs.timeoutAnnotation {
+		for _, p := range members {
+			if p.Spec.NodeName != "" || p.Annotations[groupTimedOutAnnotation] != "" {
+				continue
+			}
+			if err := cs.annotateTimedOut(ctx, p, timedOut); err != nil {
+				cs.logger().Error(err, "Failed to annotate pod of timed out pod group", "pod", klog.KObj(p), "group", group.name)
+			}
+		}
+	}
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable, msg)
+}
+
+func (cs *CustomScheduler) annotateTimedOut(ctx context.Context, pod *v1.Pod, timedOut time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{groupTimedOutAnnotation: timedOut.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cs.handle.ClientSet().CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
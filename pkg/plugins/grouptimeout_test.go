@@ -0,0 +1,63 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func TestCustomScheduler_PreFilterGroupTimeout(t *testing.T) {
+	created := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 3, Labels: map[string]string{scheduleTimeoutLabel: "300"}}.Pods()
+	for _, p := range pods {
+		p.CreationTimestamp = metav1.NewTime(created)
+	}
+	client := clientsetfake.NewSimpleClientset(pods[0], pods[1])
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	clock := testingclock.NewFakeClock(created.Add(4 * time.Minute))
+	cs := &CustomScheduler{handle: fh, scoreMode: leastMode, clock: clock, pods: &faultyPodLister{stale: pods}, timeoutAnnotation: true}
+
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != framework.Unschedulable {
+		t.Errorf("expected Unschedulable before the timeout, got %v", status)
+	}
+	clock.Step(time.Minute)
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected UnschedulableAndUnresolvable after the timeout, got %v", status)
+	}
+	for _, p := range pods {
+		got, err := client.CoreV1().Pods("default").Get(context.Background(), p.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := got.Annotations[groupTimedOutAnnotation]; v != "2023-06-01T12:05:00Z" {
+			t.Errorf("expected %s to be annotated with the timeout, got %q", p.Name, v)
+		}
+	}
+
+	invalid := fixtures.GroupSpec{Name: "g2", Namespace: "default", Size: 1, MinAvailable: 1, Labels: map[string]string{scheduleTimeoutLabel: "soon"}}.Pods()[0]
+	if _, status := cs.groupOf(invalid); status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected an invalid timeout to be unresolvable, got %v", status)
+	}
+}
@@ -0,0 +1,147 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Policies for pods without gang semantics.
+const (
+	// ungroupedSchedule places ungrouped pods with neutral scores.
+	ungroupedSchedule = "Schedule"
+	// ungroupedHandoff recreates bare ungrouped pods for the default scheduler.
+	ungroupedHandoff = "Handoff"
+	// handedOffLabel marks a recreated pod with the scheduler it was handed off from.
+	handedOffLabel = "nthu.scheduler/handed-off-from"
+)
+
+// UngroupedPodsArgs decides what happens to pods that are assigned to this
+// scheduler but have no gang semantics, so they do not get stuck.
+type UngroupedPodsArgs struct {
+	// Policy is Schedule or Handoff.
+	Policy string `json:"policy"`
+	// GroupSelector, if set, matches the pods that have gang semantics. By
+	// default a pod has them when it carries the podGroup label or belongs to
+	// a Volcano PodGroup or training job of an enabled integration.
+	GroupSelector *metav1.LabelSelector `json:"groupSelector,omitempty"`
+	// DefaultSchedulerName is the scheduler pods are handed off to,
+	// "default-scheduler" by default.
+	DefaultSchedulerName string `json:"defaultSchedulerName,omitempty"`
+}
+
+// ungroupedPolicy is the validated form of UngroupedPodsArgs.
+type ungroupedPolicy struct {
+	policy        string
+	groupSelector labels.Selector
+	schedulerName string
+	// handoffs holds the UIDs of pods being recreated, so requeues do not
+	// start a second handoff.
+	handoffs sync.Map
+}
+
+func newUngroupedPolicy(args UngroupedPodsArgs) (*ungroupedPolicy, error) {
+	if args.Policy != ungroupedSchedule && args.Policy != ungroupedHandoff {
+		return nil, fmt.Errorf("invalid ungrouped pods policy, got %s", args.Policy)
+	}
+	p := &ungroupedPolicy{policy: args.Policy, schedulerName: args.DefaultSchedulerName}
+	if p.schedulerName == "" {
+		p.schedulerName = v1.DefaultSchedulerName
+	}
+	if args.GroupSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(args.GroupSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ungrouped pods group selector: %w", err)
+		}
+		p.groupSelector = selector
+	}
+	return p, nil
+}
+
+// isUngrouped reports whether pod lacks gang semantics.
+func (cs *CustomScheduler) isUngrouped(pod *v1.Pod) bool {
+	if cs.ungrouped != nil && cs.ungrouped.groupSelector != nil {
+		return !cs.ungrouped.groupSelector.Matches(labels.Set(pod.Labels))
+	}
+	if _, ok := pod.Labels[groupNameLabel]; ok {
+		return false
+	}
+	if cs.podGroups != nil && pod.Labels[podGroupLabel] != "" {
+		return false
+	}
+	if cs.volcanoPodGroups != nil && volcanoGroupName(pod) != "" {
+		return false
+	}
+	if cs.trainingJobs != nil {
+		if _, _, ok := trainingJobOwner(pod); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// scheduleUngrouped reports whether pod is scored neutrally.
+func (cs *CustomScheduler) scheduleUngrouped(pod *v1.Pod) bool {
+	return cs.ungrouped != nil && cs.ungrouped.policy == ungroupedSchedule && cs.isUngrouped(pod)
+}
+
+// handoffUngrouped keeps ungrouped pods out of the queue under the Handoff
+// policy and recreates them for the default scheduler. A pod's schedulerName
+// is immutable, so only bare pods can be handed off; pods of a controller
+// must be fixed in its template.
+func (cs *CustomScheduler) handoffUngrouped(pod *v1.Pod) *framework.Status {
+	if cs.ungrouped == nil || cs.ungrouped.policy != ungroupedHandoff || !cs.isUngrouped(pod) {
+		return nil
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		msg := fmt.Sprintf("pod has no gang semantics; set schedulerName %s in its %s %s", cs.ungrouped.schedulerName, owner.Kind, owner.Name)
+		cs.recordEvent(pod, v1.EventTypeWarning, "HandoffFailed", "Handoff", msg)
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, msg)
+	}
+	if _, inFlight := cs.ungrouped.handoffs.LoadOrStore(pod.UID, struct{}{}); !inFlight {
+		go func() {
+			defer cs.ungrouped.handoffs.Delete(pod.UID)
+			if err := cs.handoff(context.Background(), pod); err != nil {
+				cs.logger().Error(err, "Failed to hand off pod", "pod", klog.KObj(pod))
+				return
+			}
+			cs.logger().V(2).Info("Pod was handed off", "pod", klog.KObj(pod), "scheduler", cs.ungrouped.schedulerName)
+		}()
+	}
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("handing off pod without gang semantics to %s", cs.ungrouped.schedulerName))
+}
+
+// handoff replaces pod with a copy for the default scheduler.
+func (cs *CustomScheduler) handoff(ctx context.Context, pod *v1.Pod) error {
+	replacement := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        pod.Name,
+			Namespace:   pod.Namespace,
+			Labels:      map[string]string{},
+			Annotations: pod.Annotations,
+		},
+		Spec: *pod.Spec.DeepCopy(),
+	}
+	for k, v := range pod.Labels {
+		replacement.Labels[k] = v
+	}
+	replacement.Labels[handedOffLabel] = pod.Spec.SchedulerName
+	replacement.Spec.SchedulerName = cs.ungrouped.schedulerName
+
+	pods := cs.handle.ClientSet().CoreV1().Pods(pod.Namespace)
+	uid := pod.UID
+	if err := pods.Delete(ctx, pod.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}); err != nil {
+		return fmt.Errorf("failed to delete pod: %w", err)
+	}
+	if _, err := pods.Create(ctx, replacement, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to recreate pod: %w", err)
+	}
+	cs.recordEvent(replacement, v1.EventTypeNormal, "HandedOff", "Handoff", fmt.Sprintf("recreated for %s", cs.ungrouped.schedulerName))
+	return nil
+}
@@ -0,0 +1,120 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+func TestNewUngroupedPolicy(t *testing.T) {
+	if _, err := newUngroupedPolicy(UngroupedPodsArgs{Policy: "Bogus"}); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+	p, err := newUngroupedPolicy(UngroupedPodsArgs{Policy: ungroupedHandoff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.schedulerName != v1.DefaultSchedulerName {
+		t.Errorf("expected %s, got %s", v1.DefaultSchedulerName, p.schedulerName)
+	}
+}
+
+func TestCustomScheduler_ScheduleUngrouped(t *testing.T) {
+	policy, err := newUngroupedPolicy(UngroupedPodsArgs{Policy: ungroupedSchedule, GroupSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gang": "true"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := makeGroupPods("g1", 3, 1)[0]
+	cs := &CustomScheduler{
+		scoreMode: mostMode,
+		ungrouped: policy,
+		pods:      &faultyPodLister{stale: []*v1.Pod{pod}},
+		nodes:     fakeframework.NodeInfoLister{makeNodeInfo("n1", 1000, 100)},
+	}
+
+	if _, status := cs.PreFilter(context.Background(), nil, pod); !status.IsSuccess() {
+		t.Errorf("expected an ungrouped pod to pass PreFilter, got %v", status)
+	}
+	if score, _ := cs.Score(context.Background(), nil, pod, "n1"); score != 0 {
+		t.Errorf("expected a neutral score, got %d", score)
+	}
+
+	pod.Labels["gang"] = "true"
+	if _, status := cs.PreFilter(context.Background(), nil, pod); status.Code() != framework.Unschedulable {
+		t.Errorf("expected a grouped pod to be gang checked, got %v", status)
+	}
+}
+
+func TestCustomScheduler_HandoffUngrouped(t *testing.T) {
+	bare := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bare", Namespace: "default", UID: "u1"}, Spec: v1.PodSpec{SchedulerName: "custom-scheduler"}}
+	isController := true
+	owned := bare.DeepCopy()
+	owned.Name, owned.UID = "owned", "u2"
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "rs", Controller: &isController}}
+	client := clientsetfake.NewSimpleClientset(bare, owned)
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"custom-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	policy, _ := newUngroupedPolicy(UngroupedPodsArgs{Policy: ungroupedHandoff})
+	cs := &CustomScheduler{handle: fh, scoreMode: leastMode, ungrouped: policy}
+
+	if status := cs.PreEnqueue(context.Background(), makeGroupPods("g1", 1, 1)[0]); !status.IsSuccess() {
+		t.Errorf("expected a grouped pod to be enqueued, got %v", status)
+	}
+	if status := cs.PreEnqueue(context.Background(), owned); status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected an owned pod to be gated, got %v", status)
+	}
+	if status := cs.PreEnqueue(context.Background(), bare); status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected a bare pod to be gated, got %v", status)
+	}
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		got, err := client.CoreV1().Pods("default").Get(context.Background(), "bare", metav1.GetOptions{})
+		return err == nil && got.Spec.SchedulerName == v1.DefaultSchedulerName, nil
+	})
+	if err != nil {
+		t.Fatalf("pod was not handed off: %v", err)
+	}
+	got, _ := client.CoreV1().Pods("default").Get(context.Background(), "bare", metav1.GetOptions{})
+	if got.Labels[handedOffLabel] != "custom-scheduler" {
+		t.Errorf("unexpected labels %v", got.Labels)
+	}
+	if got, _ := client.CoreV1().Pods("default").Get(context.Background(), "owned", metav1.GetOptions{}); got.Spec.SchedulerName != "custom-scheduler" {
+		t.Errorf("owned pod must not be recreated")
+	}
+}
+
+func TestCustomScheduler_RequireGroupLabels(t *testing.T) {
+	plain := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	for _, tt := range []struct {
+		require bool
+		want    framework.Code
+	}{
+		{require: false, want: framework.Success},
+		{require: true, want: framework.UnschedulableAndUnresolvable},
+	} {
+		cs := &CustomScheduler{scoreMode: leastMode, requireGroupLabels: tt.require}
+		if _, status := cs.PreFilter(context.Background(), nil, plain); status.Code() != tt.want {
+			t.Errorf("requireGroupLabels %v: expected %v for a pod without group labels, got %v", tt.require, tt.want, status)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestCustomScheduler_PreFilterGroupCounting(t *testing.T) {
+	withPhase := func(pods []*v1.Pod, phase v1.PodPhase, n int) []*v1.Pod {
+		for _, p := range pods[:n] {
+			p.Status.Phase = phase
+		}
+		return pods
+	}
+	assigned := func(pods []*v1.Pod, n int) []*v1.Pod {
+		for _, p := range pods[:n] {
+			p.Spec.NodeName = "n1"
+		}
+		return pods
+	}
+	tests := []struct {
+		name string
+		pods []*v1.Pod
+		want framework.Code
+	}{
+		{
+			name: "exactly minAvailable members",
+			pods: fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods(),
+			want: framework.Success,
+		},
+		{
+			name: "more than minAvailable members",
+			pods: fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 5, MinAvailable: 3}.Pods(),
+			want: framework.Success,
+		},
+		{
+			name: "one member short",
+			pods: fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 3}.Pods(),
+			want: framework.Unschedulable,
+		},
+		{
+			name: "assigned members count",
+			pods: assigned(fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods(), 2),
+			want: framework.Success,
+		},
+		{
+			name: "finished members do not count",
+			pods: withPhase(fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods(), v1.PodSucceeded, 1),
+			want: framework.Unschedulable,
+		},
+		{
+			name: "namesakes in another namespace do not count",
+			pods: append(
+				fixtures.GroupSpec{Name: "g1", Namespace: "other", Size: 2, MinAvailable: 3}.Pods(),
+				fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 1, MinAvailable: 3}.Pods()...,
+			),
+			want: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fwk, err := plugintesting.NewFramework([]*framework.NodeInfo{plugintesting.MakeNodeInfo("n1", 4000, 8<<30)}, tt.pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode}
+			// the last pod is always a pending member in the default namespace.
+			pod := tt.pods[len(tt.pods)-1]
+			if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pod); status.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %s", tt.want, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_ScoreEdgeCases(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		nodes []*framework.NodeInfo
+		want  map[string]int64
+	}{
+		{
+			// the least mode counts down from the largest node, so the
+			// largest node gets a raw score of 0, never a negative one.
+			name: "least mode never scores negative",
+			mode: leastMode,
+			nodes: []*framework.NodeInfo{
+				plugintesting.MakeNodeInfo("small", 1000, 1<<30),
+				plugintesting.MakeNodeInfo("large", 1000, 4<<30),
+			},
+			want: map[string]int64{"small": framework.MaxNodeScore, "large": framework.MinNodeScore},
+		},
+		{
+			name: "equal nodes tie at the neutral score",
+			mode: mostMode,
+			nodes: []*framework.NodeInfo{
+				plugintesting.MakeNodeInfo("a", 1000, 1<<30),
+				plugintesting.MakeNodeInfo("b", 1000, 1<<30),
+			},
+			want: map[string]int64{"a": framework.MaxNodeScore / 2, "b": framework.MaxNodeScore / 2},
+		},
+		{
+			name:  "a single node ties with itself",
+			mode:  leastMode,
+			nodes: []*framework.NodeInfo{plugintesting.MakeNodeInfo("only", 1000, 1<<30)},
+			want:  map[string]int64{"only": framework.MaxNodeScore / 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fwk, err := plugintesting.NewFramework(tt.nodes, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: tt.mode}
+			pod := &v1.Pod{}
+			state := framework.NewCycleState()
+			var nodes []*v1.Node
+			for _, ni := range tt.nodes {
+				nodes = append(nodes, ni.Node())
+			}
+			if status := cs.PreScore(context.Background(), state, pod, nodes); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			var scores framework.NodeScoreList
+			for _, n := range nodes {
+				score, status := cs.Score(context.Background(), state, pod, n.Name)
+				if !status.IsSuccess() {
+					t.Fatal(status)
+				}
+				if score < 0 {
+					t.Errorf("node %s: negative raw score %d", n.Name, score)
+				}
+				scores = append(scores, framework.NodeScore{Name: n.Name, Score: score})
+			}
+			if status := cs.NormalizeScore(context.Background(), state, pod, scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for _, s := range scores {
+				if s.Score != tt.want[s.Name] {
+					t.Errorf("expected %s to score %d, got %d", s.Name, tt.want[s.Name], s.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_NormalizeScoreEdgeCases(t *testing.T) {
+	tieScore := int64(80)
+	tests := []struct {
+		name   string
+		scores []int64
+		want   []int64
+		// scoreRange, tieScore and invert configure the normalization.
+		scoreRange *ScoreRange
+		tieScore   *int64
+		invert     bool
+	}{
+		{name: "negative raw scores", scores: []int64{-50, 0, 50}, want: []int64{0, 50, 100}},
+		{name: "all negative", scores: []int64{-300, -200, -100}, want: []int64{0, 50, 100}},
+		{name: "equal raw scores", scores: []int64{7, 7, 7}, want: []int64{50, 50, 50}},
+		{name: "equal negative raw scores", scores: []int64{-7, -7}, want: []int64{50, 50}},
+		{name: "narrower range", scores: []int64{0, 5, 10}, scoreRange: &ScoreRange{Min: 20, Max: 60}, want: []int64{20, 40, 60}},
+		{name: "ties at the middle of the range", scores: []int64{3, 3}, scoreRange: &ScoreRange{Min: 20, Max: 60}, want: []int64{40, 40}},
+		{name: "configured tie score", scores: []int64{3, 3}, tieScore: &tieScore, want: []int64{80, 80}},
+		{name: "inverted", scores: []int64{0, 25, 100}, invert: true, want: []int64{100, 75, 0}},
+		{name: "inverted range", scores: []int64{0, 10}, scoreRange: &ScoreRange{Min: 10, Max: 30}, invert: true, want: []int64{30, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fwk, err := plugintesting.NewFramework(nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, scoreRange: tt.scoreRange, tieScore: tt.tieScore, invertScores: tt.invert}
+			var scores framework.NodeScoreList
+			for i, s := range tt.scores {
+				scores = append(scores, framework.NodeScore{Name: string(rune('a' + i)), Score: s})
+			}
+			if status := cs.NormalizeScore(context.Background(), framework.NewCycleState(), &v1.Pod{}, scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for i, s := range scores {
+				if s.Score != tt.want[i] {
+					t.Errorf("expected %s to score %d, got %d", s.Name, tt.want[i], s.Score)
+				}
+			}
+		})
+	}
+}
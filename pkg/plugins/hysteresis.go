@@ -0,0 +1,141 @@
+package plugins
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
+)
+
+// placementTTL bounds how long a placement is subtracted from the free
+// resources of its node if the snapshot never reflects it, e.g. because the
+// pod was deleted while binding.
+const placementTTL = time.Minute
+
+// placements are the pods reserved a node while scoring free resources, by
+// pod key, until the snapshot shows them on that node. Until then, the free
+// resources of the node still include what they request. The framework's
+// snapshot holds assumed pods, so this matters only while it lags behind
+// Reserve.
+type placements struct {
+	mu   sync.Mutex
+	pods map[string]placement
+}
+
+// placement is the node a pod was reserved, what it requests and when.
+type placement struct {
+	node     string
+	requests v1.ResourceList
+	at       time.Time
+}
+
+// add records that the pod podKey, requesting requests, was reserved nodeName.
+func (p *placements) add(podKey, nodeName string, requests v1.ResourceList, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pods == nil {
+		p.pods = map[string]placement{}
+	}
+	p.pods[podKey] = placement{node: nodeName, requests: requests, at: now}
+}
+
+// remove forgets the placement of the pod podKey.
+func (p *placements) remove(podKey string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pods, podKey)
+}
+
+// pending sums, by node, the requests of the placements the snapshot does
+// not reflect yet, as get reports it. Placements it reflects, on missing
+// nodes or older than placementTTL by c are forgotten.
+func (p *placements) pending(get func(string) (*framework.NodeInfo, error), c clock.PassiveClock) pendingPlacements {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pods) == 0 {
+		return nil
+	}
+	now := c.Now()
+	pending := pendingPlacements{}
+	for key, pl := range p.pods {
+		nodeInfo, err := get(pl.node)
+		if err != nil || now.Sub(pl.at) > placementTTL || hasPod(nodeInfo, key) {
+			delete(p.pods, key)
+			continue
+		}
+		if pending[pl.node] == nil {
+			pending[pl.node] = &framework.Resource{}
+		}
+		pending[pl.node].Add(pl.requests)
+	}
+	return pending
+}
+
+// hasPod reports whether the pod key is on nodeInfo, bound or assumed.
+func hasPod(nodeInfo *framework.NodeInfo, key string) bool {
+	for _, pi := range nodeInfo.Pods {
+		if podKey(pi.Pod) == key {
+			return true
+		}
+	}
+	return false
+}
+
+const pendingPlacementsStateKey = framework.StateKey(Name + "/pending-placements")
+
+// pendingPlacements are the requests of the placements the snapshot of a
+// cycle does not reflect yet, by node.
+type pendingPlacements map[string]*framework.Resource
+
+func (p pendingPlacements) Clone() framework.StateData {
+	return p
+}
+
+// pendingPlacements returns the placements the snapshot does not reflect
+// yet, from state if PreScore stored them, so every node of a cycle is
+// scored against the same placements.
+func (cs *CustomScheduler) pendingPlacements(state *framework.CycleState) pendingPlacements {
+	if !cs.scoreFreeResources {
+		return nil
+	}
+	if state != nil {
+		if data, err := state.Read(pendingPlacementsStateKey); err == nil {
+			return data.(pendingPlacements)
+		}
+	}
+	return cs.placements.pending(cs.nodeInfos().Get, cs.clock)
+}
+
+// recordPlacement records that pod was reserved nodeName, when scoring free
+// resources.
+func (cs *CustomScheduler) recordPlacement(pod *v1.Pod, nodeName string) {
+	if !cs.scoreFreeResources {
+		return
+	}
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	// the pod takes up one of the pods the node allows, too.
+	requests[v1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+	cs.placements.add(podKey(pod), nodeName, requests, cs.clock.Now())
+}
+
+// adjustedResources returns the scored resources of nodeInfo less the
+// requests of the placements on it the snapshot does not reflect yet.
+func (cs *CustomScheduler) adjustedResources(pending pendingPlacements, nodeInfo *framework.NodeInfo) (amount, milliCPU int64) {
+	amount, milliCPU = cs.scoredResources(nodeInfo)
+	if nodeInfo.Node() == nil {
+		return amount, milliCPU
+	}
+	requests, ok := pending[nodeInfo.Node().Name]
+	if !ok {
+		return amount, milliCPU
+	}
+	name := cs.resource
+	if name == "" {
+		name = v1.ResourceMemory
+	}
+	return nonNegative(amount - resourceAmount(requests, name)), nonNegative(milliCPU - requests.MilliCPU)
+}
@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestCustomScheduler_ScorePendingPlacements(t *testing.T) {
+	n1 := makeNodeInfo("n1", 4000, 800)
+	n2 := makeNodeInfo("n2", 4000, 600)
+	clock := testingclock.NewFakeClock(time.Now())
+	cs := &CustomScheduler{scoreMode: mostMode, scoreFreeResources: true, nodes: fakeframework.NodeInfoLister{n1, n2}, clock: clock}
+	// bestNode returns the node scoring highest in a cycle going through PreScore.
+	bestNode := func() string {
+		state := framework.NewCycleState()
+		if status := cs.PreScore(context.Background(), state, &v1.Pod{}, nil); !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		best, bestScore := "", int64(-1)
+		for _, name := range []string{"n1", "n2"} {
+			score, status := cs.Score(context.Background(), state, &v1.Pod{}, name)
+			if !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			if score > bestScore {
+				best, bestScore = name, score
+			}
+		}
+		return best
+	}
+	if got := bestNode(); got != "n1" {
+		t.Fatalf("expected n1, with the most memory free, to score highest, got %s", got)
+	}
+
+	pod := makeRequestingPod("default", "first", "", v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(400, resource.BinarySI)})
+	pod.UID = "first"
+	if status := cs.Reserve(context.Background(), framework.NewCycleState(), pod, "n1"); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if got := bestNode(); got != "n2" {
+		t.Errorf("expected the placement on n1 not shown in the snapshot yet to count, got %s scoring highest", got)
+	}
+
+	// once the snapshot shows the pod on n1, its requests are not subtracted twice.
+	placed := pod.DeepCopy()
+	placed.Spec.NodeName = "n1"
+	n1.AddPod(placed)
+	if got := bestNode(); got != "n2" {
+		t.Errorf("expected n2 to score highest, got %s", got)
+	}
+	if err := n1.RemovePod(placed); err != nil {
+		t.Fatal(err)
+	}
+	if got := bestNode(); got != "n1" {
+		t.Errorf("expected the placement to be forgotten once the snapshot showed it, got %s scoring highest", got)
+	}
+
+	if status := cs.Reserve(context.Background(), framework.NewCycleState(), pod, "n1"); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	cs.Unreserve(context.Background(), framework.NewCycleState(), pod, "n1")
+	if got := bestNode(); got != "n1" {
+		t.Errorf("expected an unreserved placement not to count, got %s scoring highest", got)
+	}
+
+	if status := cs.Reserve(context.Background(), framework.NewCycleState(), pod, "n1"); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	clock.Step(placementTTL + time.Second)
+	if got := bestNode(); got != "n1" {
+		t.Errorf("expected a placement older than %v not to count, got %s scoring highest", placementTTL, got)
+	}
+}
+
+func TestCustomScheduler_PlacementsNotRecorded(t *testing.T) {
+	cs := &CustomScheduler{scoreMode: mostMode, nodes: fakeframework.NodeInfoLister{makeNodeInfo("n1", 4000, 800)}}
+	pod := makeRequestingPod("default", "first", "", v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(400, resource.BinarySI)})
+	if status := cs.Reserve(context.Background(), framework.NewCycleState(), pod, "n1"); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if len(cs.placements.pods) != 0 {
+		t.Errorf("expected no placements unless scoring free resources, got %v", cs.placements.pods)
+	}
+}
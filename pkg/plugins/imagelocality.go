@@ -0,0 +1,53 @@
+package plugins
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Image sizes bounding the image locality bonus, as in the default
+// ImageLocality plugin: nodes with less than minImageThreshold of the pod's
+// images get no bonus, and nodes with maxContainerImageThreshold per
+// container get all of it.
+const (
+	minImageThreshold          int64 = 23 * 1024 * 1024
+	maxContainerImageThreshold int64 = 1000 * 1024 * 1024
+)
+
+// imageLocalityBonus is the score bonus of nodeInfo, up to
+// CustomSchedulerArgs.ImageLocalityWeight, by the size of the images of pod
+// it already has. Each image counts by the share of the totalNodes nodes
+// having it, so that a pod is not drawn to the one node that happened to
+// pull a large image first.
+func (cs *CustomScheduler) imageLocalityBonus(pod *v1.Pod, nodeInfo *framework.NodeInfo, totalNodes int) int64 {
+	if totalNodes == 0 || len(pod.Spec.Containers) == 0 {
+		return 0
+	}
+	var sum int64
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range containers {
+			if state, ok := nodeInfo.ImageStates[normalizedImageName(c.Image)]; ok {
+				sum += int64(float64(state.Size) * float64(state.NumNodes) / float64(totalNodes))
+			}
+		}
+	}
+	maxThreshold := maxContainerImageThreshold * int64(len(pod.Spec.Containers))
+	if sum < minImageThreshold {
+		return 0
+	}
+	if sum > maxThreshold {
+		sum = maxThreshold
+	}
+	return cs.config().imageLocalityWeight * (sum - minImageThreshold) / (maxThreshold - minImageThreshold)
+}
+
+// normalizedImageName returns the name nodes report image under: with the
+// latest tag if it has neither a tag nor a digest.
+func normalizedImageName(name string) string {
+	if strings.LastIndex(name, ":") <= strings.LastIndex(name, "/") {
+		name = name + ":latest"
+	}
+	return name
+}
@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestCustomScheduler_ImageLocalityBonus(t *testing.T) {
+	const mb = 1024 * 1024
+	withImages := func(images map[string]*framework.ImageStateSummary) *framework.NodeInfo {
+		ni := makeNodeInfo("n1", 1000, 1000)
+		ni.ImageStates = images
+		return ni
+	}
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Image: "pytorch/pytorch"}}}}
+	cs := &CustomScheduler{imageLocalityWeight: 40}
+	for _, tt := range []struct {
+		name       string
+		nodeInfo   *framework.NodeInfo
+		totalNodes int
+		want       int64
+	}{
+		{name: "without the image", nodeInfo: withImages(nil), totalNodes: 1, want: 0},
+		{name: "with the image on every node", nodeInfo: withImages(map[string]*framework.ImageStateSummary{"pytorch/pytorch:latest": {Size: 2000 * mb, NumNodes: 2}}), totalNodes: 2, want: 40},
+		{name: "with the image on half the nodes", nodeInfo: withImages(map[string]*framework.ImageStateSummary{"pytorch/pytorch:latest": {Size: 1000 * mb, NumNodes: 1}}), totalNodes: 2, want: 40 * (500 - 23) / (1000 - 23)},
+		{name: "with a small image", nodeInfo: withImages(map[string]*framework.ImageStateSummary{"pytorch/pytorch:latest": {Size: 10 * mb, NumNodes: 1}}), totalNodes: 1, want: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cs.imageLocalityBonus(pod, tt.nodeInfo, tt.totalNodes); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizedImageName(t *testing.T) {
+	for name, want := range map[string]string{
+		"nginx":                          "nginx:latest",
+		"nginx:1.25":                     "nginx:1.25",
+		"registry.local:5000/ml/train":   "registry.local:5000/ml/train:latest",
+		"registry.local:5000/ml/train:1": "registry.local:5000/ml/train:1",
+	} {
+		if got := normalizedImageName(name); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
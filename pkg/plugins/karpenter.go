@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// Karpenter labels its nodes with the provisioner (v1alpha5) or the
+	// node pool (v1beta1) that launched them.
+	karpenterProvisionerLabel string = "karpenter.sh/provisioner-name"
+	karpenterNodePoolLabel    string = "karpenter.sh/nodepool"
+	// karpenterDoNotConsolidateAnnotation opts a node out of consolidation.
+	karpenterDoNotConsolidateAnnotation string = "karpenter.sh/do-not-consolidate"
+	// provisioningHintAnnotation holds what a blocked gang needs provisioned, as JSON.
+	provisioningHintAnnotation string = "nthu.scheduler/provisioning-hint"
+)
+
+// KarpenterArgs configures Karpenter awareness.
+type KarpenterArgs struct {
+	// ConsolidationWeight is the score bonus, 0 to 100, given to a fully
+	// requested Karpenter node; emptier nodes get proportionally less, so
+	// gangs pack onto busy nodes and empty ones can be consolidated away.
+	// 20 by default.
+	ConsolidationWeight int64 `json:"consolidationWeight,omitempty"`
+}
+
+// provisioningHint tells a provisioner such as Karpenter what a blocked gang needs.
+type provisioningHint struct {
+	PodGroup string `json:"podGroup"`
+	// PendingPods is the number of unbound members.
+	PendingPods int `json:"pendingPods"`
+	// PodRequests are the requests of the largest pending member.
+	PodRequests v1.ResourceList `json:"podRequests"`
+	// Shortfall is the aggregate capacity missing from the cluster.
+	Shortfall v1.ResourceList `json:"shortfall"`
+	// NodeSelector is the node selector of pod, which constrains instance
+	// types, capacity types and zones.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+func newKarpenterArgs(args KarpenterArgs) (*KarpenterArgs, error) {
+	if args.ConsolidationWeight == 0 {
+		args.ConsolidationWeight = 20
+	}
+	if args.ConsolidationWeight < 0 || args.ConsolidationWeight > framework.MaxNodeScore {
+		return nil, fmt.Errorf("karpenter consolidation weight must be between 0 and %d, got %d", framework.MaxNodeScore, args.ConsolidationWeight)
+	}
+	return &args, nil
+}
+
+func isKarpenterNode(node *v1.Node) bool {
+	_, provisioner := node.Labels[karpenterProvisionerLabel]
+	_, nodePool := node.Labels[karpenterNodePoolLabel]
+	return provisioner || nodePool
+}
+
+// karpenterBonus scales the consolidation weight by how much of the node is
+// already requested.
+func (cs *CustomScheduler) karpenterBonus(nodeInfo *framework.NodeInfo) int64 {
+	node := nodeInfo.Node()
+	if !isKarpenterNode(node) || node.Annotations[karpenterDoNotConsolidateAnnotation] == "true" {
+		return 0
+	}
+	return int64(requestedFraction(nodeInfo) * float64(cs.karpenter.ConsolidationWeight))
+}
+
+// annotateProvisioningHint records on pod what its gang needs provisioned.
+func (cs *CustomScheduler) annotateProvisioningHint(ctx context.Context, pod *v1.Pod, group *podGroup, pending []*v1.Pod, shortfall v1.ResourceList) error {
+	hint := provisioningHint{
+		PodGroup:     group.name,
+		PendingPods:  len(pending),
+		PodRequests:  v1.ResourceList{},
+		Shortfall:    shortfall,
+		NodeSelector: pod.Spec.NodeSelector,
+	}
+	for _, p := range pending {
+		for name, quantity := range resourcehelper.PodRequests(p, resourcehelper.PodResourcesOptions{}) {
+			if current, ok := hint.PodRequests[name]; !ok || quantity.Cmp(current) > 0 {
+				hint.PodRequests[name] = quantity
+			}
+		}
+	}
+	value, err := json.Marshal(hint)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{provisioningHintAnnotation: string(value)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cs.handle.ClientSet().CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
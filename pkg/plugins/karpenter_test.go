@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func makeKarpenterNodeInfo(name string, requestedMemory int64) *framework.NodeInfo {
+	ni := makeLabeledNodeInfo(name, 100, map[string]string{karpenterNodePoolLabel: "default"})
+	if requestedMemory > 0 {
+		ni.AddPod(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-pod", UID: types.UID("uid-" + name)},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(requestedMemory, resource.BinarySI)},
+			}}}},
+		})
+	}
+	return ni
+}
+
+func TestNewKarpenterArgs(t *testing.T) {
+	if args, err := newKarpenterArgs(KarpenterArgs{}); err != nil || args.ConsolidationWeight != 20 {
+		t.Errorf("expected the default weight, got %+v, %v", args, err)
+	}
+	if _, err := newKarpenterArgs(KarpenterArgs{ConsolidationWeight: 101}); err == nil {
+		t.Error("expected an error for an out-of-range weight")
+	}
+}
+
+func TestCustomScheduler_NormalizeScoreKarpenter(t *testing.T) {
+	busy := makeKarpenterNodeInfo("busy", 50)
+	empty := makeKarpenterNodeInfo("empty", 0)
+	static := makeLabeledNodeInfo("static", 100, nil)
+	static.AddPod(busy.Pods[0].Pod)
+	cs := &CustomScheduler{scoreMode: mostMode, karpenter: &KarpenterArgs{ConsolidationWeight: 40}, nodes: fakeframework.NodeInfoLister{busy, empty, static}}
+
+	scores := framework.NodeScoreList{{Name: "busy"}, {Name: "empty"}, {Name: "static"}}
+	if status := cs.NormalizeScore(context.Background(), nil, &v1.Pod{}, scores); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	want := map[string]int64{"busy": 70, "empty": 50, "static": 50}
+	for _, s := range scores {
+		if s.Score != want[s.Name] {
+			t.Errorf("node %s: expected %d, got %d", s.Name, want[s.Name], s.Score)
+		}
+	}
+}
+
+func TestCustomScheduler_PostFilterProvisioningHint(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3, Shape: fixtures.Shapes["small"]}.Pods()
+	pods[0].Spec.NodeSelector = map[string]string{"karpenter.sh/capacity-type": "spot"}
+	client := clientsetfake.NewSimpleClientset(pods[0])
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: []*framework.NodeInfo{makeNodeInfo("n1", 1000, 256*1024*1024)}}),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	for _, p := range pods {
+		informerFactory.Core().V1().Pods().Informer().GetStore().Add(p)
+	}
+	cs := &CustomScheduler{handle: fh, scoreMode: leastMode, capacityHints: true, karpenter: &KarpenterArgs{ConsolidationWeight: 20}}
+
+	cs.PostFilter(context.Background(), framework.NewCycleState(), pods[0], nil)
+	got, err := client.CoreV1().Pods("default").Get(context.Background(), pods[0].Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hint provisioningHint
+	if err := json.Unmarshal([]byte(got.Annotations[provisioningHintAnnotation]), &hint); err != nil {
+		t.Fatalf("invalid provisioning hint %q: %v", got.Annotations[provisioningHintAnnotation], err)
+	}
+	memory := hint.Shortfall[v1.ResourceMemory]
+	if hint.PodGroup != "g1" || hint.PendingPods != 3 || memory.String() != "128Mi" || hint.NodeSelector["karpenter.sh/capacity-type"] != "spot" {
+		t.Errorf("unexpected provisioning hint %+v", hint)
+	}
+}
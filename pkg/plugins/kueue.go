@@ -0,0 +1,83 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// kueueQueueNameLabel marks pods whose admission is decided by Kueue.
+	kueueQueueNameLabel string = "kueue.x-k8s.io/queue-name"
+)
+
+var workloadGVR = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "workloads"}
+
+// WorkloadLister finds the Kueue Workloads owned by an object.
+type WorkloadLister interface {
+	ByOwnerUID(namespace string, uid types.UID) ([]*unstructured.Unstructured, error)
+}
+
+// kueueGate keeps Kueue-managed pods out of the active queue until their
+// Workload is admitted, so Kueue decides quota before this plugin places gangs.
+func (cs *CustomScheduler) kueueGate(pod *v1.Pod) *framework.Status {
+	if cs.workloads == nil {
+		return nil
+	}
+	if _, ok := pod.GetLabels()[kueueQueueNameLabel]; !ok {
+		return nil
+	}
+
+	if !cs.integrationAvailable(integrationKueue) {
+		return cs.integrationNotSynced(integrationKueue, pod)
+	}
+	admitted, err := cs.workloadAdmitted(pod)
+	if err != nil {
+		return cs.integrationUnavailable(integrationKueue, pod, fmt.Errorf("failed to look up Kueue workload: %w", err))
+	}
+	if !admitted {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "waiting for the Kueue workload to be admitted")
+	}
+	return nil
+}
+
+// workloadAdmitted reports whether the Workload of pod, owned either by the
+// pod itself or by the pod's controller (e.g. a Job), is admitted.
+func (cs *CustomScheduler) workloadAdmitted(pod *v1.Pod) (bool, error) {
+	owners := []types.UID{pod.UID}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			owners = append(owners, ref.UID)
+		}
+	}
+	for _, uid := range owners {
+		workloads, err := cs.workloads.ByOwnerUID(pod.Namespace, uid)
+		if err != nil {
+			return false, err
+		}
+		for _, wl := range workloads {
+			if isWorkloadAdmitted(wl) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isWorkloadAdmitted checks the Admitted condition, falling back to the
+// status.admission field set by older Kueue versions.
+func isWorkloadAdmitted(wl *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(wl.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == "Admitted" {
+			return cond["status"] == "True"
+		}
+	}
+	admission, found, _ := unstructured.NestedMap(wl.Object, "status", "admission")
+	return found && admission != nil
+}
@@ -0,0 +1,78 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makeWorkload(name string, owner types.UID, admitted bool) *unstructured.Unstructured {
+	status := "False"
+	if admitted {
+		status = "True"
+	}
+	wl := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kueue.x-k8s.io/v1beta1",
+		"kind":       "Workload",
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Admitted", "status": status},
+			},
+		},
+	}}
+	wl.SetNamespace("default")
+	wl.SetName(name)
+	wl.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "job", UID: owner}})
+	return wl
+}
+
+func newFakeUnstructuredLister(t *testing.T, workloads ...*unstructured.Unstructured) *unstructuredLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{ownerUIDIndex: indexByOwnerUID})
+	for _, wl := range workloads {
+		if err := indexer.Add(wl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &unstructuredLister{indexer: indexer, synced: func() bool { return true }}
+}
+
+func TestCustomScheduler_PreEnqueueKueue(t *testing.T) {
+	isController := true
+	jobPod := func(job types.UID, labels map[string]string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "p",
+			Namespace:       "default",
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: "job", UID: job, Controller: &isController}},
+		}}
+	}
+	queued := map[string]string{kueueQueueNameLabel: "team-a"}
+	workloads := newFakeUnstructuredLister(t, makeWorkload("admitted", "job-1", true), makeWorkload("pending", "job-2", false))
+
+	tests := []struct {
+		name      string
+		workloads WorkloadLister
+		pod       *v1.Pod
+		want      framework.Code
+	}{
+		{name: "integration disabled", pod: jobPod("job-2", queued), want: framework.Success},
+		{name: "pod not managed by Kueue", workloads: workloads, pod: jobPod("job-2", nil), want: framework.Success},
+		{name: "workload admitted", workloads: workloads, pod: jobPod("job-1", queued), want: framework.Success},
+		{name: "workload pending", workloads: workloads, pod: jobPod("job-2", queued), want: framework.UnschedulableAndUnresolvable},
+		{name: "workload missing", workloads: workloads, pod: jobPod("job-3", queued), want: framework.UnschedulableAndUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, workloads: tt.workloads}
+			if got := cs.PreEnqueue(context.Background(), tt.pod); got.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got.Code())
+			}
+		})
+	}
+}
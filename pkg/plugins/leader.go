@@ -0,0 +1,99 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"my-scheduler-plugins/pkg/leader"
+)
+
+// LeaderElectionArgs runs the background loops publishing gang state, the
+// rebalancer and the state exporter, in the replica holding a Lease only,
+// and adds the gangs bound in the API server to the gang state when a
+// replica takes over. kube-scheduler's own leader election decides which
+// replica schedules; give both the same durations so they fail over
+// together.
+type LeaderElectionArgs struct {
+	// LeaseName is "custom-scheduler-" and the profile name by default, and
+	// LeaseNamespace kube-system.
+	LeaseName      string `json:"leaseName,omitempty"`
+	LeaseNamespace string `json:"leaseNamespace,omitempty"`
+	// LeaseDurationSeconds, RenewDeadlineSeconds and RetryPeriodSeconds are
+	// 15, 10 and 2 by default, as in kube-scheduler.
+	LeaseDurationSeconds int64 `json:"leaseDurationSeconds,omitempty"`
+	RenewDeadlineSeconds int64 `json:"renewDeadlineSeconds,omitempty"`
+	RetryPeriodSeconds   int64 `json:"retryPeriodSeconds,omitempty"`
+}
+
+func newLeaderConfig(args LeaderElectionArgs, profile string) (leader.Config, error) {
+	if args.LeaseDurationSeconds < 0 || args.RenewDeadlineSeconds < 0 || args.RetryPeriodSeconds < 0 {
+		return leader.Config{}, fmt.Errorf("leader election durations must not be negative")
+	}
+	config := leader.Config{
+		LeaseName:      args.LeaseName,
+		LeaseNamespace: args.LeaseNamespace,
+		LeaseDuration:  time.Duration(args.LeaseDurationSeconds) * time.Second,
+		RenewDeadline:  time.Duration(args.RenewDeadlineSeconds) * time.Second,
+		RetryPeriod:    time.Duration(args.RetryPeriodSeconds) * time.Second,
+	}
+	if config.LeaseName == "" {
+		config.LeaseName = "custom-scheduler"
+		if profile != "" {
+			config.LeaseName += "-" + profile
+		}
+	}
+	return config, nil
+}
+
+// takeOver rebuilds the gang state once the informer caches have synced,
+// then runs the loops until ctx is done, when this replica loses the Lease.
+func (cs *CustomScheduler) takeOver(ctx context.Context, loops []func(context.Context)) {
+	if !cache.WaitForCacheSync(ctx.Done(), cs.informersSynced...) {
+		return
+	}
+	if err := cs.rebuildGangState(); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to rebuild the gang state")
+	}
+	for _, loop := range loops {
+		go loop(ctx)
+	}
+	<-ctx.Done()
+}
+
+// rebuildGangState tracks the members bound to a node of every gang as
+// permitted, unless already tracked. The plugin Lease is not kube-scheduler's
+// own, so this replica may have been scheduling before it took the Lease
+// over: the reservations, the admission gate and the gangs it holds in
+// memory are live, and kept.
+func (cs *CustomScheduler) rebuildGangState() error {
+	pods, err := cs.podLister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	now := cs.clock.Now()
+	m := &cs.podGroupManager
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || !isLive(p) || cs.isUngrouped(p) {
+			continue
+		}
+		group, status := cs.groupOf(p)
+		if !status.IsSuccess() {
+			continue
+		}
+		s := m.state(group)
+		if _, ok := s.permitted[podKey(p)]; !ok {
+			s.permitted[podKey(p)] = gangMember{name: p.Name, since: now}
+		}
+		if s.released.IsZero() {
+			s.released = now
+		}
+	}
+	cs.logger().Info("Rebuilt the gang state", "groups", len(m.groups))
+	return nil
+}
@@ -0,0 +1,55 @@
+package plugins
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestCustomScheduler_TakeOver(t *testing.T) {
+	pods := makeGroupPods("g1", 3, 3)
+	pods[0].Spec.NodeName, pods[1].Spec.NodeName = "n1", "n2"
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now()), pods: &faultyPodLister{stale: pods}}
+	// this replica was already scheduling before it took the Lease over.
+	cs.gangAdmission.holder = "default/live"
+	cs.gangReservations.reserve("default/live", "default/live-0", "n1")
+	live := cs.podGroupManager.state(&podGroup{namespace: "default", name: "live", minAvailable: 2})
+	live.waiting["default/live-1"] = gangMember{name: "live-1", since: time.Now()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan struct{})
+	go cs.takeOver(ctx, []func(context.Context){func(context.Context) { close(ran) }})
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the loops to run")
+	}
+	cancel()
+
+	if cs.gangAdmission.holder != "default/live" || cs.gangReservations.count("default/live") != 1 {
+		t.Error("expected the admission gate and the reservations to be kept")
+	}
+	if got, ok := cs.PodGroupManager().GetGroupStatus("default", "live"); !ok || !reflect.DeepEqual(got.Waiting, []string{"live-1"}) {
+		t.Errorf("expected the live gang to be kept, got %+v", got)
+	}
+	want := GroupStatus{Namespace: pods[0].Namespace, Name: "g1", MinAvailable: 3, Waiting: []string{}, Permitted: []string{pods[0].Name, pods[1].Name}}
+	if got, ok := cs.PodGroupManager().GetGroupStatus(pods[0].Namespace, "g1"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the bound members of g1 to be permitted, %+v, got %+v", want, got)
+	}
+}
+
+func TestNewLeaderConfig(t *testing.T) {
+	config, err := newLeaderConfig(LeaderElectionArgs{LeaseDurationSeconds: 30}, "custom-least")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.LeaseName != "custom-scheduler-custom-least" || config.LeaseDuration != 30*time.Second {
+		t.Errorf("expected the lease custom-scheduler-custom-least for 30s, got %+v", config)
+	}
+	if _, err := newLeaderConfig(LeaderElectionArgs{RetryPeriodSeconds: -1}, ""); err == nil {
+		t.Error("expected an error for a negative duration")
+	}
+}
@@ -0,0 +1,154 @@
+package plugins
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Results of a wait in Permit.
+const (
+	permitAllowed  = "allowed"
+	permitRejected = "rejected"
+)
+
+// scoreModes are the modes the score mode metric reports on.
+var scoreModes = []string{leastMode, mostMode, leastCPUMode, mostCPUMode, consolidationMode, balancedMode, numaMode, weightedMode, realLeastMode, realMostMode}
+
+var (
+	preFilterRejections = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "prefilter_rejections_total",
+		Help:           "Number of pods of a pod group rejected in PreFilter, by status code. Groups beyond the first thousand are counted as _other.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "namespace", "group", "code"})
+	permitWaitDuration = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "permit_wait_duration_seconds",
+		Help:           "How long gang members waited in Permit for the rest of their gang, by result: allowed or rejected.",
+		Buckets:        metrics.ExponentialBuckets(0.1, 2, 14),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "result"})
+	nodeScores = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "node_score",
+		Help:           "Normalized scores the plugin gave a node.",
+		Buckets:        metrics.LinearBuckets(0, 10, 11),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "node"})
+	scoreModeGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "nthu_scheduler",
+		Name:           "score_mode",
+		Help:           "1 for the score mode in use, 0 for the others.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"profile", "mode"})
+
+	registerPluginMetrics sync.Once
+
+	// rejectedGroups bounds the groups preFilterRejections has series of,
+	// like the exporter bounds its per-group metrics.
+	rejectedGroups = &boundedGroups{max: maxExportedGroups}
+)
+
+// otherGroup labels the series of the groups beyond the bound of a
+// per-group counter, which cannot drop series the way the exporter
+// republishes its gauges.
+const otherGroup = "_other"
+
+// boundedGroups are the groups a per-group counter has series of, at most
+// max; series are never deleted, so neither are the groups.
+type boundedGroups struct {
+	max int
+
+	mu     sync.Mutex
+	groups map[string]bool
+}
+
+// label returns the group label of group: its name if it has, or may have,
+// its own series, and otherGroup once max other groups have.
+func (b *boundedGroups) label(group *podGroup) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := group.key()
+	if b.groups[key] {
+		return group.name
+	}
+	if len(b.groups) >= b.max {
+		return otherGroup
+	}
+	if b.groups == nil {
+		b.groups = map[string]bool{}
+	}
+	b.groups[key] = true
+	return group.name
+}
+
+// registerMetrics registers the plugin's metrics with the scheduler's
+// registry, served on its /metrics endpoint.
+func registerMetrics() {
+	registerPluginMetrics.Do(func() {
+		legacyregistry.MustRegister(preFilterRejections, permitWaitDuration, nodeScores, scoreModeGauge)
+	})
+}
+
+// recordPreFilterRejection counts and logs pod of group being rejected with status.
+func (cs *CustomScheduler) recordPreFilterRejection(pod *v1.Pod, group *podGroup, status *framework.Status) {
+	cs.logger().V(2).Info("Rejected pod in PreFilter", "pod", klog.KObj(pod), "group", group.name, "code", status.Code().String(), "reason", status.Message())
+	preFilterRejections.WithLabelValues(cs.profileName(), group.namespace, rejectedGroups.label(group), status.Code().String()).Inc()
+}
+
+// recordScoreMode reports mode as the score mode in use.
+func (cs *CustomScheduler) recordScoreMode(mode string) {
+	for _, m := range scoreModes {
+		value := 0.0
+		if m == mode {
+			value = 1
+		}
+		scoreModeGauge.WithLabelValues(cs.profileName(), m).Set(value)
+	}
+}
+
+// recordNodeScores reports the normalized scores of a cycle.
+func (cs *CustomScheduler) recordNodeScores(scores framework.NodeScoreList) {
+	profile := cs.profileName()
+	for _, s := range scores {
+		nodeScores.WithLabelValues(profile, s.Name).Observe(float64(s.Score))
+	}
+}
+
+// permitWaits are the times the pods waiting in Permit, by pod key, started
+// to wait.
+type permitWaits struct {
+	mu     sync.Mutex
+	starts map[string]time.Time
+}
+
+// start records that the pod key started to wait at now, unless it already waits.
+func (w *permitWaits) start(key string, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.starts == nil {
+		w.starts = map[string]time.Time{}
+	}
+	if _, ok := w.starts[key]; !ok {
+		w.starts[key] = now
+	}
+}
+
+// endPermitWait reports how long the pod key waited in Permit with result,
+// if it waited, and whether it did.
+func (cs *CustomScheduler) endPermitWait(key, result string) bool {
+	cs.permitWaits.mu.Lock()
+	start, ok := cs.permitWaits.starts[key]
+	delete(cs.permitWaits.starts, key)
+	cs.permitWaits.mu.Unlock()
+	if ok {
+		permitWaitDuration.WithLabelValues(cs.profileName(), result).Observe(cs.clock.Since(start).Seconds())
+	}
+	return ok
+}
@@ -0,0 +1,73 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func TestCustomScheduler_Metrics(t *testing.T) {
+	registerMetrics()
+	clock := testingclock.NewFakeClock(time.Now())
+	pods := fixtures.GroupSpec{Name: "metrics", Namespace: "default", Size: 2, MinAvailable: 3}.Pods()
+	cs := &CustomScheduler{scoreMode: leastMode, clock: clock, pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{makeNodeInfo("n1", 1000, 1000)}}
+
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.IsSuccess() {
+		t.Fatal("expected the incomplete group to be rejected")
+	}
+	if v, err := testutil.GetCounterMetricValue(preFilterRejections.WithLabelValues("", "default", "metrics", framework.Unschedulable.String())); err != nil || v != 1 {
+		t.Errorf("expected 1 PreFilter rejection, got %v (%v)", v, err)
+	}
+
+	cs.updateConfig(func(c *schedulerConfig) { c.scoreMode = mostMode })
+	for mode, want := range map[string]float64{mostMode: 1, leastMode: 0} {
+		if v, err := testutil.GetGaugeMetricValue(scoreModeGauge.WithLabelValues("", mode)); err != nil || v != want {
+			t.Errorf("expected the %s mode gauge to be %v, got %v (%v)", mode, want, v, err)
+		}
+	}
+
+	scores := framework.NodeScoreList{{Name: "metrics-n1", Score: 10}, {Name: "metrics-n2", Score: 20}}
+	if status := cs.NormalizeScore(context.Background(), framework.NewCycleState(), pods[0], scores); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if n, err := testutil.GetHistogramMetricCount(nodeScores.WithLabelValues("", "metrics-n2")); err != nil || n != 1 {
+		t.Errorf("expected 1 observed score of metrics-n2, got %v (%v)", n, err)
+	}
+
+	newPermitFramework(t, cs)
+	before, _ := testutil.GetHistogramMetricCount(permitWaitDuration.WithLabelValues(cs.profileName(), permitRejected))
+	cs.permitWaits.start(podKey(pods[1]), clock.Now())
+	clock.Step(3 * time.Second)
+	cs.Unreserve(context.Background(), nil, pods[1], "n1")
+	if n, err := testutil.GetHistogramMetricCount(permitWaitDuration.WithLabelValues(cs.profileName(), permitRejected)); err != nil || n != before+1 {
+		t.Errorf("expected the rejected wait to be observed, got %v (%v)", n-before, err)
+	}
+}
+
+func TestBoundedGroups(t *testing.T) {
+	b := &boundedGroups{max: 2}
+	g1 := &podGroup{namespace: "default", name: "g1"}
+	g2 := &podGroup{namespace: "other", name: "g1"}
+	g3 := &podGroup{namespace: "default", name: "g3"}
+	for _, tc := range []struct {
+		group *podGroup
+		want  string
+	}{
+		{g1, "g1"},
+		{g2, "g1"},
+		{g3, otherGroup},
+		// groups with a series keep it.
+		{g1, "g1"},
+		{g2, "g1"},
+	} {
+		if got := b.label(tc.group); got != tc.want {
+			t.Errorf("%s: expected the label %s, got %s", tc.group.key(), tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// groupSizeLabel is the total number of members of a labelled gang, which
+// a minAvailable percentage is a percentage of.
+const groupSizeLabel string = "groupSize"
+
+// parseMinAvailable parses a minAvailable that is either an integer or a
+// percentage, such as "80%", of size members, rounded up so that a gang is
+// never admitted short of the percentage.
+func parseMinAvailable(value string, size int) (int, error) {
+	if len(value) > maxMinAvailableDigits {
+		return 0, fmt.Errorf("must be an integer or a percentage")
+	}
+	percent, isPercent := strings.CutSuffix(value, "%")
+	if !isPercent {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("must be an integer or a percentage")
+		}
+		return n, nil
+	}
+	p, err := strconv.Atoi(percent)
+	if err != nil || p <= 0 || p > 100 {
+		return 0, fmt.Errorf("percentage must be between 1%% and 100%%")
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("a percentage needs the size of the group")
+	}
+	return int(math.Ceil(float64(size) * float64(p) / 100)), nil
+}
+
+// labelMinAvailable returns the minAvailable of the labelled gang name that
+// pod declares: its minAvailable label or, since label values cannot hold
+// a percent sign, the annotation of the same name. Percentages are of the
+// groupSize label.
+func labelMinAvailable(pod *v1.Pod, name string) (int, *framework.Status) {
+	value, ok := pod.Labels[minAvailableLabel]
+	if !ok {
+		value = pod.Annotations[minAvailableLabel]
+	}
+	size := 0
+	if s, ok := pod.Labels[groupSizeLabel]; ok {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || len(s) > maxMinAvailableDigits {
+			return 0, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				fmt.Sprintf("invalid %s label %q of pod group %s: must be a positive integer", groupSizeLabel, truncate(s), name))
+		}
+		size = n
+	}
+	minAvailable, err := parseMinAvailable(value, size)
+	if err != nil {
+		return 0, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("invalid %s %q of pod group %s: %v", minAvailableLabel, truncate(value), name, err))
+	}
+	return minAvailable, nil
+}
@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestParseMinAvailable(t *testing.T) {
+	for _, tt := range []struct {
+		value   string
+		size    int
+		want    int
+		wantErr bool
+	}{
+		{value: "3", want: 3},
+		{value: "3", size: 10, want: 3},
+		{value: "80%", size: 10, want: 8},
+		{value: "75%", size: 10, want: 8},
+		{value: "1%", size: 10, want: 1},
+		{value: "100%", size: 7, want: 7},
+		{value: "80%", wantErr: true},
+		{value: "0%", size: 10, wantErr: true},
+		{value: "101%", size: 10, wantErr: true},
+		{value: "%", size: 10, wantErr: true},
+		{value: "eighty%", size: 10, wantErr: true},
+		{value: "", wantErr: true},
+	} {
+		got, err := parseMinAvailable(tt.value, tt.size)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseMinAvailable(%q, %d): expected %d (error %v), got %d (%v)", tt.value, tt.size, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestCustomScheduler_PreFilterPercentMinAvailable(t *testing.T) {
+	// 80% of a group of 5 is 4 members.
+	percentPods := func(n int, size string) []*v1.Pod {
+		pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: n, Labels: map[string]string{groupSizeLabel: size}}.Pods()
+		for _, p := range pods {
+			delete(p.Labels, minAvailableLabel)
+			p.Annotations = map[string]string{minAvailableLabel: "80%"}
+		}
+		return pods
+	}
+	for _, tt := range []struct {
+		name string
+		pods []*v1.Pod
+		want framework.Code
+	}{
+		{name: "enough members", pods: percentPods(4, "5"), want: framework.Success},
+		{name: "one member short", pods: percentPods(3, "5"), want: framework.Unschedulable},
+		{name: "invalid group size", pods: percentPods(4, "five"), want: framework.UnschedulableAndUnresolvable},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fwk, err := plugintesting.NewFramework(nil, tt.pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode}
+			if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), tt.pods[0]); status.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %s", tt.want, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestCrdMinMember(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		spec    map[string]interface{}
+		want    int64
+		wantErr bool
+	}{
+		{name: "integer", spec: map[string]interface{}{"minMember": int64(3)}, want: 3},
+		{name: "percentage of replicas", spec: map[string]interface{}{"minMember": "50%", "replicas": int64(5)}, want: 3},
+		{name: "percentage without replicas", spec: map[string]interface{}{"minMember": "50%"}, wantErr: true},
+		{name: "missing", spec: map[string]interface{}{}, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := crdMinMember(makePodGroup("pg", tt.spec))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("expected %d (error %v), got %d (%v)", tt.want, tt.wantErr, got, err)
+			}
+		})
+	}
+}
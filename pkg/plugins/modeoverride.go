@@ -0,0 +1,34 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// scoreModeAnnotation overrides the score mode of the profile for one
+	// pod, so one profile can pack some pods and spread others.
+	scoreModeAnnotation string = "nthu.scheduler/score-mode"
+	// invalidScoreModeReason is the event reason for pods whose
+	// scoreModeAnnotation names no score mode.
+	invalidScoreModeReason string = "InvalidScoreMode"
+)
+
+// scoreModeOf returns the score mode of pod: its scoreModeAnnotation if it
+// names a valid mode, and the configured mode otherwise.
+func (cs *CustomScheduler) scoreModeOf(pod *v1.Pod) string {
+	if mode, ok := pod.Annotations[scoreModeAnnotation]; ok && validMode(mode) {
+		return mode
+	}
+	return cs.config().scoreMode
+}
+
+// checkScoreModeAnnotation tells the owner of pod, once per cycle, that its
+// scoreModeAnnotation is ignored because it names no score mode.
+func (cs *CustomScheduler) checkScoreModeAnnotation(pod *v1.Pod) {
+	if mode, ok := pod.Annotations[scoreModeAnnotation]; ok && !validMode(mode) {
+		cs.recordEvent(pod, v1.EventTypeWarning, invalidScoreModeReason, "Scoring",
+			fmt.Sprintf("invalid %s annotation %q, using the %s mode", scoreModeAnnotation, truncate(mode), cs.config().scoreMode))
+	}
+}
@@ -0,0 +1,97 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// nfdLabelPrefix is the prefix of the labels Node Feature Discovery publishes.
+const nfdLabelPrefix string = "feature.node.kubernetes.io/"
+
+// NodeFeatureRule steers the pods it selects by a Node Feature Discovery label.
+type NodeFeatureRule struct {
+	// Feature is the NFD label without its feature.node.kubernetes.io/ prefix,
+	// e.g. cpu-cpuid.AVX512F, pci-10de.present or kernel-config.NO_HZ.
+	Feature string `json:"feature"`
+	// Value is the label value the node must have, "true" by default.
+	Value string `json:"value,omitempty"`
+	// PodSelector selects the pods the rule applies to; all pods if unset.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Required filters out nodes without the feature. Otherwise nodes with the
+	// feature get Weight added to their normalized score.
+	Required bool  `json:"required,omitempty"`
+	Weight   int64 `json:"weight,omitempty"`
+}
+
+// nodeFeatureRule is a validated NodeFeatureRule.
+type nodeFeatureRule struct {
+	label    string
+	value    string
+	selector labels.Selector
+	required bool
+	weight   int64
+}
+
+func newNodeFeatureRules(rules []NodeFeatureRule) ([]nodeFeatureRule, error) {
+	var result []nodeFeatureRule
+	for i, r := range rules {
+		if r.Feature == "" {
+			return nil, fmt.Errorf("nodeFeatures[%d]: feature must not be empty", i)
+		}
+		if r.Weight < 0 || r.Weight > framework.MaxNodeScore {
+			return nil, fmt.Errorf("nodeFeatures[%d]: weight must be within [0, %d], got %d", i, framework.MaxNodeScore, r.Weight)
+		}
+		selector := labels.Everything()
+		if r.PodSelector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(r.PodSelector); err != nil {
+				return nil, fmt.Errorf("nodeFeatures[%d]: invalid podSelector: %w", i, err)
+			}
+		}
+		value := r.Value
+		if value == "" {
+			value = "true"
+		}
+		result = append(result, nodeFeatureRule{
+			label:    nfdLabelPrefix + r.Feature,
+			value:    value,
+			selector: selector,
+			required: r.Required,
+			weight:   r.Weight,
+		})
+	}
+	return result, nil
+}
+
+func (r nodeFeatureRule) appliesTo(pod *v1.Pod) bool {
+	return r.selector.Matches(labels.Set(pod.Labels))
+}
+
+func (r nodeFeatureRule) matches(node *v1.Node) bool {
+	return node != nil && node.Labels[r.label] == r.value
+}
+
+// filterNodeFeatures rejects nodes lacking a feature a required rule demands of pod.
+func (cs *CustomScheduler) filterNodeFeatures(pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	for _, r := range cs.nodeFeatures {
+		if r.required && r.appliesTo(pod) && !r.matches(nodeInfo.Node()) {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node lacks feature %s=%s", r.label, r.value))
+		}
+	}
+	return nil
+}
+
+// nodeFeatureBonus is the summed weight of the preferred rules for pod the node satisfies.
+func (cs *CustomScheduler) nodeFeatureBonus(pod *v1.Pod, node *v1.Node) int64 {
+	var bonus int64
+	for _, r := range cs.nodeFeatures {
+		if !r.required && r.appliesTo(pod) && r.matches(node) {
+			bonus += r.weight
+		}
+	}
+	return bonus
+}
@@ -0,0 +1,74 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func makeLabeledNodeInfo(name string, memory int64, nodeLabels map[string]string) *framework.NodeInfo {
+	ni := makeNodeInfo(name, 1000, memory)
+	node := ni.Node().DeepCopy()
+	node.Labels = nodeLabels
+	ni.SetNode(node)
+	return ni
+}
+
+func TestNewNodeFeatureRules(t *testing.T) {
+	invalid := [][]NodeFeatureRule{
+		{{Feature: ""}},
+		{{Feature: "cpu-cpuid.AVX512F", Weight: 101}},
+		{{Feature: "cpu-cpuid.AVX512F", PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: "Bogus"}}}}},
+	}
+	for _, rules := range invalid {
+		if _, err := newNodeFeatureRules(rules); err == nil {
+			t.Errorf("expected an error for %+v", rules)
+		}
+	}
+}
+
+func TestCustomScheduler_NodeFeatures(t *testing.T) {
+	rules, err := newNodeFeatureRules([]NodeFeatureRule{
+		{Feature: "pci-10de.present", Required: true, PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"accelerator": "gpu"}}},
+		{Feature: "cpu-cpuid.AVX512F", Weight: 50},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := makeLabeledNodeInfo("plain", 300, nil)
+	avx := makeLabeledNodeInfo("avx", 100, map[string]string{nfdLabelPrefix + "cpu-cpuid.AVX512F": "true"})
+	gpu := makeLabeledNodeInfo("gpu", 200, map[string]string{nfdLabelPrefix + "pci-10de.present": "true"})
+	cs := &CustomScheduler{scoreMode: mostMode, nodeFeatures: rules, nodes: fakeframework.NodeInfoLister{plain, avx, gpu}}
+
+	gpuPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"accelerator": "gpu"}}}
+	cpuPod := &v1.Pod{}
+	for _, tt := range []struct {
+		pod  *v1.Pod
+		node *framework.NodeInfo
+		want framework.Code
+	}{
+		{pod: gpuPod, node: gpu, want: framework.Success},
+		{pod: gpuPod, node: plain, want: framework.UnschedulableAndUnresolvable},
+		{pod: cpuPod, node: plain, want: framework.Success},
+	} {
+		if got := cs.Filter(context.Background(), nil, tt.pod, tt.node); got.Code() != tt.want {
+			t.Errorf("pod %v on node %s: expected %v, got %v", tt.pod.Labels, tt.node.Node().Name, tt.want, got.Code())
+		}
+	}
+
+	// Most mode ranks plain (300) > gpu (200) > avx (100); AVX512F adds 50.
+	scores := framework.NodeScoreList{{Name: "plain", Score: 300}, {Name: "avx", Score: 100}, {Name: "gpu", Score: 200}}
+	if status := cs.NormalizeScore(context.Background(), nil, cpuPod, scores); !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	want := map[string]int64{"plain": 100, "avx": 50, "gpu": 50}
+	for _, s := range scores {
+		if s.Score != want[s.Name] {
+			t.Errorf("node %s: expected %d, got %d", s.Name, want[s.Name], s.Score)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// nodePoolLabel names the pool of nodes the members of a labelled gang
+// are restricted to.
+const nodePoolLabel string = "nodePool"
+
+// defaultNodePoolKey is the node label, and taint, naming the pool of a
+// node unless CustomSchedulerArgs.NodePoolKey is set.
+const defaultNodePoolKey string = "nthu.scheduler/node-pool"
+
+// labelNodePool returns the nodePool label of pod in the labelled gang
+// name, "" if it has none.
+func labelNodePool(pod *v1.Pod, name string) (string, *framework.Status) {
+	pool, ok := pod.Labels[nodePoolLabel]
+	if !ok {
+		return "", nil
+	}
+	if err := validateNodePool(pool); err != nil {
+		return "", framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("invalid %s label %q of pod group %s: %v", nodePoolLabel, truncate(pool), name, err))
+	}
+	return pool, nil
+}
+
+// validateNodePool checks that pool can be matched against node labels.
+func validateNodePool(pool string) error {
+	if pool == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if errs := validation.IsValidLabelValue(pool); len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// poolKey is the node label and taint key naming the pool of a node.
+func (cs *CustomScheduler) poolKey() string {
+	if cs.nodePoolKey != "" {
+		return cs.nodePoolKey
+	}
+	return defaultNodePoolKey
+}
+
+// inNodePool reports whether node is in pool: labelled with it, or tainted
+// with it so that only pods tolerating the taint run there.
+func (cs *CustomScheduler) inNodePool(node *v1.Node, pool string) bool {
+	key := cs.poolKey()
+	if node.Labels[key] == pool {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == key && taint.Value == pool {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNodePool fails fast, before Filter runs on every node, if the node
+// pool of group cannot hold the members it is missing to reach
+// minAvailable, counting how many more pods like pod fit on each node of
+// the pool. Members the snapshot has on pool nodes already count.
+func (cs *CustomScheduler) checkNodePool(pod *v1.Pod, group *podGroup, members []*v1.Pod) *framework.Status {
+	if group.nodePool == "" {
+		return nil
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list node infos: %v", err))
+	}
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	nodes, placed, fit := 0, 0, 0
+	for _, ni := range nodeInfos {
+		if ni.Node() == nil || !cs.inNodePool(ni.Node(), group.nodePool) {
+			continue
+		}
+		nodes++
+		for _, pi := range ni.Pods {
+			if isMember[podKey(pi.Pod)] {
+				placed++
+			}
+		}
+		fit += podsThatFit(requests, ni)
+	}
+	if nodes == 0 {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node pool %s of pod group %s has no nodes", group.nodePool, group.name))
+	}
+	if missing := group.minAvailable - placed; fit < missing {
+		return framework.NewStatus(framework.Unschedulable,
+			fmt.Sprintf("node pool %s has room for %d more members of pod group %s, which is missing %d", group.nodePool, fit, group.name, missing))
+	}
+	return nil
+}
+
+// podsThatFit returns how many pods with requests fit in what nodeInfo has
+// left, bounded by the pods it allows.
+func podsThatFit(requests v1.ResourceList, nodeInfo *framework.NodeInfo) int {
+	fit := -1
+	if n := nodeInfo.Allocatable.AllowedPodNumber; n > 0 {
+		fit = int(nonNegative(int64(n - len(nodeInfo.Pods))))
+	}
+	for name := range requests {
+		requested := requestAmount(requests, name)
+		if requested <= 0 {
+			continue
+		}
+		n := int(nonNegative(resourceAmount(nodeInfo.Allocatable, name)-resourceAmount(nodeInfo.Requested, name)) / requested)
+		if fit < 0 || n < fit {
+			fit = n
+		}
+	}
+	if fit < 0 {
+		// nothing bounds pods that request nothing on a node without a pod limit.
+		return defaultMaxMinAvailable
+	}
+	return fit
+}
+
+// filterNodePool rejects nodes outside the node pool of the gang of pod.
+func (cs *CustomScheduler) filterNodePool(state *framework.CycleState, nodeInfo *framework.NodeInfo) *framework.Status {
+	s := readGroupSnapshot(state)
+	if s == nil || s.group.nodePool == "" || nodeInfo.Node() == nil {
+		return nil
+	}
+	if !cs.inNodePool(nodeInfo.Node(), s.group.nodePool) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node is outside node pool %s of its pod group", s.group.nodePool))
+	}
+	return nil
+}
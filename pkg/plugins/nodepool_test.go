@@ -0,0 +1,88 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+// makePoolNode returns a node of 2 CPUs in pool, by label, or by taint if tainted.
+func makePoolNode(name, pool string, tainted bool) *framework.NodeInfo {
+	ni := plugintesting.MakeNodeInfo(name, 2000, 8<<30)
+	node := ni.Node()
+	if tainted {
+		node.Spec.Taints = []v1.Taint{{Key: defaultNodePoolKey, Value: pool, Effect: v1.TaintEffectNoSchedule}}
+	} else {
+		node.Labels = map[string]string{defaultNodePoolKey: pool}
+	}
+	ni.SetNode(node)
+	return ni
+}
+
+func TestCustomScheduler_NodePool(t *testing.T) {
+	gpu := []*framework.NodeInfo{makePoolNode("gpu-1", "gpu", false), makePoolNode("gpu-2", "gpu", true)}
+	cpu := makePoolNode("cpu-1", "cpu", false)
+	nodes := append(gpu, cpu)
+	gang := func(minAvailable int, pool string) []*v1.Pod {
+		return fixtures.GroupSpec{
+			Name: "g1", Namespace: "default", Size: minAvailable, MinAvailable: minAvailable,
+			Shape:  fixtures.Shape{CPU: "1", Memory: "1Gi"},
+			Labels: map[string]string{nodePoolLabel: pool},
+		}.Pods()
+	}
+	for _, tt := range []struct {
+		name       string
+		pods       []*v1.Pod
+		want       framework.Code
+		wantFilter bool
+	}{
+		{name: "pool holds the gang", pods: gang(4, "gpu"), want: framework.Success, wantFilter: true},
+		{name: "pool too small", pods: gang(5, "gpu"), want: framework.Unschedulable},
+		{name: "pool without nodes", pods: gang(1, "tpu"), want: framework.UnschedulableAndUnresolvable},
+		{name: "invalid pool", pods: gang(1, "gpu pool"), want: framework.UnschedulableAndUnresolvable},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fwk, err := plugintesting.NewFramework(nodes, tt.pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode}
+			state := framework.NewCycleState()
+			if _, status := cs.PreFilter(context.Background(), state, tt.pods[0]); status.Code() != tt.want {
+				t.Fatalf("expected %v, got %v: %s", tt.want, status.Code(), status.Message())
+			}
+			if !tt.wantFilter {
+				return
+			}
+			for _, ni := range nodes {
+				status := cs.Filter(context.Background(), state, tt.pods[0], ni)
+				if inPool := ni != cpu; status.IsSuccess() != inPool {
+					t.Errorf("node %s: expected admitted %v, got %v", ni.Node().Name, inPool, status)
+				}
+			}
+		})
+	}
+}
+
+func TestPodsThatFit(t *testing.T) {
+	ni := plugintesting.MakeNodeInfo("n1", 2000, 4<<30)
+	for _, tt := range []struct {
+		name     string
+		requests v1.ResourceList
+		want     int
+	}{
+		{name: "bounded by CPU", requests: fixtures.GroupSpec{Size: 1, Shape: fixtures.Shape{CPU: "500m", Memory: "128Mi"}}.Pods()[0].Spec.Containers[0].Resources.Requests, want: 4},
+		{name: "bounded by memory", requests: fixtures.GroupSpec{Size: 1, Shape: fixtures.Shape{CPU: "100m", Memory: "3Gi"}}.Pods()[0].Spec.Containers[0].Resources.Requests, want: 1},
+		{name: "bounded by the pod limit", requests: v1.ResourceList{}, want: 110},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podsThatFit(tt.requests, ni); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package plugins
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ScoreRange is the range NormalizeScore maps raw scores to.
+type ScoreRange struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+func validateScoreRange(r *ScoreRange) error {
+	if r == nil {
+		return nil
+	}
+	if r.Min < framework.MinNodeScore || r.Max > framework.MaxNodeScore || r.Min >= r.Max {
+		return fmt.Errorf("min and max must be increasing, between %d and %d", framework.MinNodeScore, framework.MaxNodeScore)
+	}
+	return nil
+}
+
+// normalizedRange returns the range raw scores are mapped to, the full
+// range the framework accepts by default.
+func (cs *CustomScheduler) normalizedRange() (lo, hi int64) {
+	if r := cs.config().scoreRange; r != nil {
+		return r.Min, r.Max
+	}
+	return framework.MinNodeScore, framework.MaxNodeScore
+}
+
+// normalize maps scores linearly from their own range onto the normalized
+// range, the lowest raw score to its bottom, or, inverting scores, to its
+// top. If all raw scores tie, none of the nodes is better or worse than the
+// others, so they all get the neutral score.
+func (cs *CustomScheduler) normalize(scores framework.NodeScoreList) {
+	if len(scores) == 0 {
+		return
+	}
+	minScore, maxScore := scores[0].Score, scores[0].Score
+	for _, score := range scores {
+		if score.Score < minScore {
+			minScore = score.Score
+		}
+		if score.Score > maxScore {
+			maxScore = score.Score
+		}
+	}
+	lo, hi := cs.normalizedRange()
+	// raw scores may span the whole int64 range, e.g. bytes of memory on
+	// nodes with many TiB, or scores from a score policy, so differences
+	// are taken as uint64, which holds any of them.
+	scoreRange := uint64(maxScore) - uint64(minScore)
+	invert := cs.config().invertScores
+	for i := range scores {
+		switch {
+		case scoreRange == 0:
+			scores[i].Score = cs.neutralScore()
+		case invert:
+			scores[i].Score = hi - scaleScore(uint64(scores[i].Score)-uint64(minScore), scoreRange, hi-lo)
+		default:
+			scores[i].Score = lo + scaleScore(uint64(scores[i].Score)-uint64(minScore), scoreRange, hi-lo)
+		}
+	}
+}
+
+// scaleScore returns offset*span/total, rounded down, for offset at most
+// total and a non-negative span, without overflowing: the product is
+// computed on 128 bits.
+func scaleScore(offset, total uint64, span int64) int64 {
+	hi, lo := bits.Mul64(offset, uint64(span))
+	// hi < total since offset <= total and span < 2^64, as Div64 requires.
+	quo, _ := bits.Div64(hi, lo, total)
+	return int64(quo)
+}
+
+// neutralScore is the normalized score of nodes whose raw scores all tie:
+// the configured tie score, or else the middle of the normalized range.
+func (cs *CustomScheduler) neutralScore() int64 {
+	if tieScore := cs.config().tieScore; tieScore != nil {
+		return *tieScore
+	}
+	lo, hi := cs.normalizedRange()
+	return lo + (hi-lo)/2
+}
+
+const missingNodesStateKey = framework.StateKey(Name + "/missing-nodes")
+
+// missingNodes are the nodes Score found missing from the snapshot in a
+// cycle, most likely deleted after Filter. Nodes are scored concurrently.
+type missingNodes struct {
+	mu    sync.Mutex
+	nodes map[string]bool
+}
+
+func (m *missingNodes) Clone() framework.StateData {
+	return m
+}
+
+// missingNodesOf returns the missing nodes PreScore set up in state, or nil.
+func missingNodesOf(state *framework.CycleState) *missingNodes {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(missingNodesStateKey)
+	if err != nil {
+		return nil
+	}
+	return data.(*missingNodes)
+}
+
+func (m *missingNodes) add(nodeName string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.nodes == nil {
+		m.nodes = map[string]bool{}
+	}
+	m.nodes[nodeName] = true
+}
+
+func (m *missingNodes) has(nodeName string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nodes[nodeName]
+}
+
+// rankLowest sets the raw scores of the missing nodes to the worst raw
+// score of the others, so that they neither stretch the range normalize
+// maps from nor, inverting scores, end up on top.
+func (m *missingNodes) rankLowest(scores framework.NodeScoreList, invert bool) {
+	if m == nil {
+		return
+	}
+	worst, found := int64(0), false
+	for _, score := range scores {
+		if m.has(score.Name) {
+			continue
+		}
+		if !found || (invert && score.Score > worst) || (!invert && score.Score < worst) {
+			worst, found = score.Score, true
+		}
+	}
+	for i := range scores {
+		if m.has(scores[i].Name) {
+			scores[i].Score = worst
+		}
+	}
+}
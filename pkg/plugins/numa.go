@@ -0,0 +1,97 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// numaMode prefers nodes with the most free memory in a single NUMA zone,
+// as reported by NodeResourceTopology custom resources, so that
+// latency-sensitive pods get memory local to the CPUs they run on.
+const numaMode string = "NUMA"
+
+// integrationNodeResourceTopology names the informer of NodeResourceTopology
+// objects. It has no failure policy: it only ranks nodes, so pods never
+// wait for it.
+const integrationNodeResourceTopology string = "nodeResourceTopology"
+
+// nodeResourceTopologyGVR is the resource of the NodeResourceTopology objects
+// the node feature exporter publishes, one per node and named after it.
+var nodeResourceTopologyGVR = schema.GroupVersionResource{Group: "topology.node.k8s.io", Version: "v1alpha2", Resource: "noderesourcetopologies"}
+
+// numaScore returns the largest memory available in one NUMA zone of
+// nodeInfo, or 0 if no zone has enough for the memory pod requests or the
+// node publishes no NodeResourceTopology.
+func (cs *CustomScheduler) numaScore(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	if cs.nodeTopologies == nil || nodeInfo.Node() == nil {
+		return 0
+	}
+	nrt, err := cs.nodeTopologies.Get("", nodeInfo.Node().Name)
+	if err != nil || nrt == nil {
+		return 0
+	}
+	request := requestAmount(resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{}), v1.ResourceMemory)
+	largest := zoneAvailable(nrt, v1.ResourceMemory)
+	if largest < request {
+		return 0
+	}
+	return largest
+}
+
+// zoneAvailable returns the largest amount of name available in one NUMA
+// zone of the NodeResourceTopology nrt, -1 if no zone reports it.
+func zoneAvailable(nrt *unstructured.Unstructured, name v1.ResourceName) int64 {
+	largest := int64(-1)
+	zones, _, _ := unstructured.NestedSlice(nrt.Object, "zones")
+	for _, z := range zones {
+		zone, ok := z.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if zoneType, _, _ := unstructured.NestedString(zone, "type"); zoneType != "" && zoneType != "Node" {
+			continue
+		}
+		resources, _, _ := unstructured.NestedSlice(zone, "resources")
+		for _, r := range resources {
+			res, ok := r.(map[string]interface{})
+			if !ok || res["name"] != string(name) {
+				continue
+			}
+			available, err := quantityAmount(res["available"], name)
+			if err != nil {
+				continue
+			}
+			if available > largest {
+				largest = available
+			}
+		}
+	}
+	return largest
+}
+
+// quantityAmount parses a quantity of name from an unstructured object, where
+// it is a string or, if written as a bare number, an integer.
+func quantityAmount(value interface{}, name v1.ResourceName) (int64, error) {
+	var quantity resource.Quantity
+	switch v := value.(type) {
+	case string:
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return 0, err
+		}
+		quantity = q
+	case int64:
+		quantity = *resource.NewQuantity(v, resource.DecimalSI)
+	case float64:
+		quantity = *resource.NewQuantity(int64(v), resource.DecimalSI)
+	default:
+		return 0, fmt.Errorf("invalid quantity %v", value)
+	}
+	return requestAmount(v1.ResourceList{name: quantity}, name), nil
+}
@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+// makeNodeResourceTopology returns the NodeResourceTopology of node with one
+// NUMA zone per amount of available memory.
+func makeNodeResourceTopology(node string, available ...interface{}) *unstructured.Unstructured {
+	var zones []interface{}
+	for _, a := range available {
+		zones = append(zones, map[string]interface{}{
+			"name": "node-0",
+			"type": "Node",
+			"resources": []interface{}{
+				map[string]interface{}{"name": "cpu", "available": "4"},
+				map[string]interface{}{"name": "memory", "available": a},
+			},
+		})
+	}
+	nrt := &unstructured.Unstructured{Object: map[string]interface{}{"zones": zones}}
+	nrt.SetAPIVersion("topology.node.k8s.io/v1alpha2")
+	nrt.SetKind("NodeResourceTopology")
+	nrt.SetName(node)
+	return nrt
+}
+
+func TestCustomScheduler_ScoreNUMA(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{
+		makeNodeInfo("split", 4000, 8<<30),
+		makeNodeInfo("local", 4000, 8<<30),
+		makeNodeInfo("roomy", 4000, 8<<30),
+		makeNodeInfo("unknown", 4000, 8<<30),
+	}
+	topologies := newFakeUnstructuredLister(t,
+		// 4Gi free in all, but no zone has the 3Gi the pod needs.
+		makeNodeResourceTopology("split", "2Gi", "2Gi"),
+		makeNodeResourceTopology("local", "1Gi", "3Gi"),
+		makeNodeResourceTopology("roomy", int64(6<<30)),
+	)
+	pod := makeRequestingPod("default", "pod", "", v1.ResourceList{
+		v1.ResourceMemory: *resource.NewQuantity(3<<30, resource.BinarySI),
+	})
+	cs := &CustomScheduler{scoreMode: numaMode, nodes: nodeInfos, nodeTopologies: topologies}
+	for node, want := range map[string]int64{"split": 0, "local": 3 << 30, "roomy": 6 << 30, "unknown": 0} {
+		score, status := cs.Score(context.Background(), framework.NewCycleState(), pod, node)
+		if !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		if score != want {
+			t.Errorf("expected %s to score %d, got %d", node, want, score)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// defaultPermitTimeout bounds how long the members of a gang wait for each
+// other unless CustomSchedulerArgs.PermitTimeoutSeconds is set.
+const defaultPermitTimeout = 60 * time.Second
+
+// maxPermitTimeout is the longest wait the framework allows in Permit.
+const maxPermitTimeout = 15 * time.Minute
+
+// Permit holds the members of a gang until minAvailable of them have passed
+// scheduling, then releases them together. Members that are not joined by
+// the rest of the gang before the gang's deadline are all rejected, so a
+// partial gang never binds. With CustomSchedulerArgs.GroupAdmission set,
+// only one incomplete gang at a time may wait, and members of the others
+// are rejected until it is complete or rejected.
+func (cs *CustomScheduler) Permit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	if cs.isUngrouped(pod) {
+		return nil, 0
+	}
+	group, members, status := cs.groupAndMembers(state, pod)
+	if !status.IsSuccess() {
+		return status, 0
+	}
+	assigned, err := cs.assignedMembers(members)
+	if err != nil {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list node infos: %v", err)), 0
+	}
+	key := group.key()
+	now := cs.clock.Now()
+	// the pod itself is assumed on nodeName but not yet in the snapshot.
+	if assigned+1 >= group.minAvailable {
+		cs.podGroupManager.allow(group, pod, now)
+		cs.gangReservations.release(key)
+		cs.gangAdmission.release(key)
+		cs.allowWaitingMembers(members)
+		return nil, 0
+	}
+	if cs.groupAdmission {
+		if ahead, ok := cs.gangAdmission.admit(key, groupPriority(pod, group), now); !ok {
+			cs.logger().V(2).Info("Pod group waits for another pod group to be admitted first", "pod", klog.KObj(pod), "group", group.name, "ahead", ahead)
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("pod group %s waits for pod group %s to be admitted first", group.name, ahead)), 0
+		}
+	}
+	cs.activateSiblings(state, pod, members)
+	wait := cs.podGroupManager.wait(group, pod, now, cs.permitTimeout(group)).Sub(now)
+	cs.permitWaits.start(podKey(pod), now)
+	cs.logger().V(2).Info("Pod waits for more members of its pod group", "pod", klog.KObj(pod), "group", group.name, "missing", group.minAvailable-assigned-1)
+	return framework.NewStatus(framework.Wait, fmt.Sprintf("waiting for %d more members of pod group %s", group.minAvailable-assigned-1, group.name)), wait
+}
+
+// permitTimeout is how long group waits to be complete: its own schedule
+// timeout if it has one, up to the framework's limit of 15 minutes.
+func (cs *CustomScheduler) permitTimeout(group *podGroup) time.Duration {
+	if group.scheduleTimeout > 0 {
+		if group.scheduleTimeout > maxPermitTimeout {
+			return maxPermitTimeout
+		}
+		return group.scheduleTimeout
+	}
+	if cs.permitTimeoutSeconds > 0 {
+		return time.Duration(cs.permitTimeoutSeconds) * time.Second
+	}
+	return defaultPermitTimeout
+}
+
+// assignedMembers counts the members bound, or assumed and waiting, on a
+// node in the scheduling snapshot.
+func (cs *CustomScheduler) assignedMembers(members []*v1.Pod) (int, error) {
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return 0, err
+	}
+	assigned := 0
+	for _, ni := range nodeInfos {
+		for _, pi := range ni.Pods {
+			if isMember[podKey(pi.Pod)] {
+				assigned++
+			}
+		}
+	}
+	return assigned, nil
+}
+
+// allowWaitingMembers releases the members waiting in Permit.
+func (cs *CustomScheduler) allowWaitingMembers(members []*v1.Pod) {
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	cs.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if isMember[podKey(wp.GetPod())] {
+			wp.Allow(cs.Name())
+			cs.endPermitWait(podKey(wp.GetPod()), permitAllowed)
+		}
+	})
+}
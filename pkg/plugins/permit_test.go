@@ -0,0 +1,141 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+// newPermitFramework returns a framework running cs as its Permit plugin.
+func newPermitFramework(t *testing.T, cs *CustomScheduler) framework.Framework {
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			st.RegisterPermitPlugin(Name, func(_ runtime.Object, h framework.Handle) (framework.Plugin, error) {
+				cs.handle = h
+				return cs, nil
+			}),
+		},
+		"custom-scheduler",
+		wait.NeverStop,
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	return fwk
+}
+
+func TestCustomScheduler_PermitReleasesGangTogether(t *testing.T) {
+	pods := makeGroupPods("g1", 3, 3)
+	// the framework keeps track of waiting pods by UID.
+	for _, p := range pods {
+		p.UID = types.UID(p.Name)
+	}
+	node := makeNodeInfo("n1", 1000, 1000)
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now()), pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{node}}
+	fwk := newPermitFramework(t, cs)
+
+	results := make(chan *framework.Status, len(pods))
+	for i, pod := range pods[:2] {
+		status := fwk.RunPermitPlugins(context.Background(), framework.NewCycleState(), pod, "n1")
+		if !status.IsWait() {
+			t.Fatalf("member %d: expected to wait, got %v", i, status)
+		}
+		// the scheduler assumes a waiting pod on its node.
+		node.AddPod(pod)
+		go func(pod *v1.Pod) { results <- fwk.WaitOnPermit(context.Background(), pod) }(pod)
+	}
+	select {
+	case status := <-results:
+		t.Fatalf("expected the gang to wait for its last member, got %v", status)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if status := fwk.RunPermitPlugins(context.Background(), framework.NewCycleState(), pods[2], "n1"); !status.IsSuccess() {
+		t.Fatalf("expected the last member to be permitted, got %v", status)
+	}
+	for range pods[:2] {
+		if status := <-results; !status.IsSuccess() {
+			t.Errorf("expected the waiting members to be released, got %v", status)
+		}
+	}
+}
+
+func TestCustomScheduler_UnreserveRejectsGang(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 3, MinAvailable: 3}.Pods()
+	for _, p := range pods {
+		p.UID = types.UID(p.Name)
+	}
+	node := makeNodeInfo("n1", 1000, 1000)
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now()), pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{node}}
+	fwk := newPermitFramework(t, cs)
+
+	results := make(chan *framework.Status, len(pods))
+	for _, pod := range pods[:2] {
+		if status := cs.Reserve(context.Background(), framework.NewCycleState(), pod, "n1"); !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		if status := fwk.RunPermitPlugins(context.Background(), framework.NewCycleState(), pod, "n1"); !status.IsWait() {
+			t.Fatalf("expected to wait, got %v", status)
+		}
+		node.AddPod(pod)
+		go func(pod *v1.Pod) { results <- fwk.WaitOnPermit(context.Background(), pod) }(pod)
+	}
+	if status := cs.Reserve(context.Background(), framework.NewCycleState(), pods[2], "n1"); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if got := cs.gangReservations.count("default/g1"); got != 3 {
+		t.Fatalf("expected 3 reservations, got %d", got)
+	}
+
+	// the last member fails in a later Reserve plugin.
+	cs.Unreserve(context.Background(), framework.NewCycleState(), pods[2], "n1")
+	for range pods[:2] {
+		if status := <-results; status.Code() != framework.Unschedulable {
+			t.Errorf("expected the waiting members to be rejected, got %v", status)
+		}
+	}
+	if got := cs.gangReservations.count("default/g1"); got != 0 {
+		t.Errorf("expected the reservations to be released, got %d", got)
+	}
+}
+
+func TestCustomScheduler_PermitGangDeadline(t *testing.T) {
+	now := time.Now()
+	clock := testingclock.NewFakeClock(now)
+	pods := makeGroupPods("g1", 3, 3)
+	cs := &CustomScheduler{clock: clock, pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{makeNodeInfo("n1", 1000, 1000)}, permitTimeoutSeconds: 30}
+
+	_, first := cs.Permit(context.Background(), nil, pods[0], "n1")
+	clock.Step(10 * time.Second)
+	_, second := cs.Permit(context.Background(), nil, pods[1], "n1")
+	if first != 30*time.Second || second != 20*time.Second {
+		t.Errorf("expected the members to time out together after 30s and 20s, got %v and %v", first, second)
+	}
+
+	// once the deadline passed, the next attempt starts a new one.
+	clock.Step(time.Minute)
+	if _, wait := cs.Permit(context.Background(), nil, pods[0], "n1"); wait != 30*time.Second {
+		t.Errorf("expected a fresh deadline, got %v", wait)
+	}
+}
+
+func TestCustomScheduler_PermitUngrouped(t *testing.T) {
+	cs := &CustomScheduler{}
+	if status, _ := cs.Permit(context.Background(), nil, &v1.Pod{}, "n1"); !status.IsSuccess() {
+		t.Errorf("expected pods without a group to be permitted, got %v", status)
+	}
+}
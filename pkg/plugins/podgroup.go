@@ -0,0 +1,107 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// podGroupLabel names the PodGroup custom resource, in the namespace of the
+// pod, that holds the gang parameters of the pod.
+const podGroupLabel string = "nthu.scheduler/pod-group"
+
+// PodGroupGVR is the resource of PodGroup custom resources.
+var PodGroupGVR = schema.GroupVersionResource{Group: "nthu.scheduler", Version: "v1alpha1", Resource: "podgroups"}
+
+// crdGroup reads the gang parameters of the PodGroup name in namespace:
+// spec.minMember and, optionally, spec.scheduleTimeoutSeconds,
+// spec.priority, spec.nodePool and spec.quota, whose schema admits cpu,
+// memory and nvidia.com/gpu.
+func (cs *CustomScheduler) crdGroup(namespace, name string) (*podGroup, *framework.Status) {
+	pg, err := cs.podGroups.Get(namespace, name)
+	if err != nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to get PodGroup %s/%s: %v", namespace, name, err))
+	}
+	if pg == nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("PodGroup %s/%s not found", namespace, name))
+	}
+	minMember, err := crdMinMember(pg)
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid minMember in PodGroup %s/%s: %v", namespace, name, err))
+	}
+	timeout, _, err := unstructured.NestedInt64(pg.Object, "spec", "scheduleTimeoutSeconds")
+	if err != nil || timeout < 0 {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid scheduleTimeoutSeconds in PodGroup %s/%s: must be a non-negative integer", namespace, name))
+	}
+	var priority *int32
+	if p, found, err := unstructured.NestedInt64(pg.Object, "spec", "priority"); err != nil || p < math.MinInt32 || p > math.MaxInt32 {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid priority in PodGroup %s/%s: must be a 32-bit integer", namespace, name))
+	} else if found {
+		p := int32(p)
+		priority = &p
+	}
+	pool, found, err := unstructured.NestedString(pg.Object, "spec", "nodePool")
+	if err == nil && found {
+		err = validateNodePool(pool)
+	}
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid nodePool in PodGroup %s/%s: %v", namespace, name, err))
+	}
+	quota, err := crdGroupQuota(pg)
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid quota in PodGroup %s/%s: %v", namespace, name, err))
+	}
+
+	return &podGroup{
+		name:            name,
+		namespace:       namespace,
+		minAvailable:    int(minMember),
+		source:          podGroupSource,
+		scheduleTimeout: time.Duration(timeout) * time.Second,
+		priority:        priority,
+		nodePool:        pool,
+		quota:           quota,
+		selector:        labels.SelectorFromSet(labels.Set{podGroupLabel: name}),
+		indexLabel:      podGroupLabel,
+		member: func(p *v1.Pod) bool {
+			return p.Namespace == namespace && p.Labels[podGroupLabel] == name
+		},
+	}, nil
+}
+
+// PodGroupMinMember returns the minimum number of members of the PodGroup
+// pg, resolving a percentage minMember against spec.replicas.
+func PodGroupMinMember(pg *unstructured.Unstructured) (int64, error) {
+	minMember, err := crdMinMember(pg)
+	if err == nil && minMember <= 0 {
+		err = fmt.Errorf("must be positive")
+	}
+	return minMember, err
+}
+
+// crdMinMember returns spec.minMember of the PodGroup pg: an integer or a
+// percentage, such as "80%", of spec.replicas.
+func crdMinMember(pg *unstructured.Unstructured) (int64, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(pg.Object, "spec", "minMember")
+	if err != nil || !found {
+		return 0, fmt.Errorf("must be an integer or a percentage")
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case string:
+		replicas, _, err := unstructured.NestedInt64(pg.Object, "spec", "replicas")
+		if err != nil {
+			return 0, fmt.Errorf("invalid replicas: must be an integer")
+		}
+		n, err := parseMinAvailable(v, int(replicas))
+		return int64(n), err
+	}
+	return 0, fmt.Errorf("must be an integer or a percentage")
+}
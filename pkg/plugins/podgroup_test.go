@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makePodGroup(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	pg := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "nthu.scheduler/v1alpha1",
+		"kind":       "PodGroup",
+		"spec":       spec,
+	}}
+	pg.SetNamespace("default")
+	pg.SetName(name)
+	return pg
+}
+
+func makePodGroupPods(group string, n int) []*v1.Pod {
+	var pods []*v1.Pod
+	for i := 0; i < n; i++ {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", group, i),
+			Namespace: "default",
+			Labels:    map[string]string{podGroupLabel: group},
+		}})
+	}
+	return pods
+}
+
+func TestCustomScheduler_PreFilterPodGroup(t *testing.T) {
+	podGroups := newFakeUnstructuredLister(t,
+		makePodGroup("small", map[string]interface{}{"minMember": int64(2)}),
+		makePodGroup("large", map[string]interface{}{"minMember": int64(4)}),
+		makePodGroup("broken", map[string]interface{}{"minMember": "two"}),
+		makePodGroup("unset", map[string]interface{}{}),
+	)
+	pods := append(makePodGroupPods("small", 2), makePodGroupPods("large", 3)...)
+	// a pod of the same name in another namespace must not count
+	other := makePodGroupPods("large", 1)[0]
+	other.Namespace = "other"
+	pods = append(pods, other)
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want framework.Code
+	}{
+		{name: "enough members", pod: makePodGroupPods("small", 1)[0], want: framework.Success},
+		{name: "not enough members", pod: makePodGroupPods("large", 1)[0], want: framework.Unschedulable},
+		{name: "PodGroup missing", pod: makePodGroupPods("missing", 1)[0], want: framework.Unschedulable},
+		{name: "invalid minMember", pod: makePodGroupPods("broken", 1)[0], want: framework.UnschedulableAndUnresolvable},
+		{name: "minMember unset", pod: makePodGroupPods("unset", 1)[0], want: framework.UnschedulableAndUnresolvable},
+		{name: "invalid name", pod: makePodGroupPods("Not_A_Name", 1)[0], want: framework.UnschedulableAndUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: &faultyPodLister{stale: pods}, podGroups: podGroups}
+			if _, got := cs.PreFilter(context.Background(), nil, tt.pod); got.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %v", tt.want, got.Code(), got.Message())
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_PodGroupScheduleTimeout(t *testing.T) {
+	podGroups := newFakeUnstructuredLister(t,
+		makePodGroup("quick", map[string]interface{}{"minMember": int64(2), "scheduleTimeoutSeconds": int64(10)}),
+		makePodGroup("default", map[string]interface{}{"minMember": int64(2)}),
+	)
+	cs := &CustomScheduler{podGroups: podGroups, permitTimeoutSeconds: 30}
+	for _, tt := range []struct {
+		group string
+		want  time.Duration
+	}{
+		{group: "quick", want: 10 * time.Second},
+		{group: "default", want: 30 * time.Second},
+	} {
+		group, status := cs.groupOf(makePodGroupPods(tt.group, 1)[0])
+		if !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		if got := cs.permitTimeout(group); got != tt.want {
+			t.Errorf("group %s: expected a timeout of %v, got %v", tt.group, tt.want, got)
+		}
+	}
+}
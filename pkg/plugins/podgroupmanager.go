@@ -0,0 +1,181 @@
+package plugins
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// permittedRetention is how long the members permitted with the last
+// release of a gang are still reported, once none of them waits.
+const permittedRetention = 10 * time.Minute
+
+// GroupStatus is the progress of a gang through Permit.
+type GroupStatus struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	MinAvailable int    `json:"minAvailable"`
+	// Waiting are the names of the members waiting in Permit.
+	Waiting []string `json:"waiting"`
+	// Permitted are the names of the members released together when the
+	// gang was last complete.
+	Permitted []string `json:"permitted,omitempty"`
+	// Deadline is when the waiting members are rejected unless the gang is
+	// complete by then.
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// PodGroupManager tracks the gangs going through Permit: the members
+// waiting for the rest, the members permitted together, and the deadline
+// by which the whole gang times out, so that it times out together rather
+// than member by member.
+type PodGroupManager struct {
+	mu     sync.Mutex
+	groups map[string]*gangState
+}
+
+// gangState is the progress of one gang, its members by podKey, so that a
+// member recreated under the same name is not mistaken for the old one.
+type gangState struct {
+	namespace    string
+	name         string
+	minAvailable int
+	waiting      map[string]gangMember
+	permitted    map[string]gangMember
+	deadline     time.Time
+	released     time.Time
+}
+
+// gangMember is a member of a gang and when it reached Permit, or was
+// permitted.
+type gangMember struct {
+	name  string
+	since time.Time
+}
+
+func (s *gangState) status() GroupStatus {
+	status := GroupStatus{Namespace: s.namespace, Name: s.name, MinAvailable: s.minAvailable, Waiting: []string{}}
+	for _, member := range s.waiting {
+		status.Waiting = append(status.Waiting, member.name)
+	}
+	for _, member := range s.permitted {
+		status.Permitted = append(status.Permitted, member.name)
+	}
+	sort.Strings(status.Waiting)
+	sort.Strings(status.Permitted)
+	if !s.deadline.IsZero() {
+		deadline := s.deadline
+		status.Deadline = &deadline
+	}
+	return status
+}
+
+// state returns the state of group, creating it if there is none. It must
+// be called with m.mu held.
+func (m *PodGroupManager) state(group *podGroup) *gangState {
+	if m.groups == nil {
+		m.groups = map[string]*gangState{}
+	}
+	key := group.key()
+	s, ok := m.groups[key]
+	if !ok {
+		s = &gangState{namespace: group.namespace, name: group.name, waiting: map[string]gangMember{}, permitted: map[string]gangMember{}}
+		m.groups[key] = s
+	}
+	s.minAvailable = group.minAvailable
+	return s
+}
+
+// wait records pod waiting in Permit for the rest of group and returns the
+// deadline of the gang, starting it at now plus timeout if the gang has
+// none or its last one has passed.
+func (m *PodGroupManager) wait(group *podGroup, pod *v1.Pod, now time.Time, timeout time.Duration) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(now)
+	s := m.state(group)
+	if s.deadline.IsZero() || !now.Before(s.deadline) {
+		s.deadline = now.Add(timeout)
+		s.permitted = map[string]gangMember{}
+	}
+	s.waiting[podKey(pod)] = gangMember{name: pod.Name, since: now}
+	return s.deadline
+}
+
+// allow records pod completing group, releasing it with the members
+// waiting for it.
+func (m *PodGroupManager) allow(group *podGroup, pod *v1.Pod, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(now)
+	s := m.state(group)
+	s.permitted = map[string]gangMember{podKey(pod): {name: pod.Name, since: now}}
+	for key, member := range s.waiting {
+		s.permitted[key] = gangMember{name: member.name, since: now}
+	}
+	s.waiting = map[string]gangMember{}
+	s.deadline = time.Time{}
+	s.released = now
+}
+
+// reject forgets the gang key once its members are rejected.
+func (m *PodGroupManager) reject(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.groups, key)
+}
+
+// prune forgets the gangs released longer than permittedRetention ago that
+// no member waits for since. It must be called with m.mu held.
+func (m *PodGroupManager) prune(now time.Time) {
+	for key, s := range m.groups {
+		if len(s.waiting) == 0 && now.Sub(s.released) > permittedRetention {
+			delete(m.groups, key)
+		}
+	}
+}
+
+// GetGroupStatus returns the progress of the gang namespace/name, or false
+// if none of its members has reached Permit recently. The gang of the gang
+// labels wins over groups of the same name from other sources.
+func (m *PodGroupManager) GetGroupStatus(namespace, name string) (GroupStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.groups[namespace+"/"+name]; ok {
+		return s.status(), true
+	}
+	for _, s := range m.groups {
+		if s.namespace == namespace && s.name == name {
+			return s.status(), true
+		}
+	}
+	return GroupStatus{}, false
+}
+
+// ListWaitingGroups lists the gangs with members waiting in Permit, ordered
+// by namespace and name.
+func (m *PodGroupManager) ListWaitingGroups() []GroupStatus {
+	m.mu.Lock()
+	list := []GroupStatus{}
+	for _, s := range m.groups {
+		if len(s.waiting) > 0 {
+			list = append(list, s.status())
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Namespace != list[j].Namespace {
+			return list[i].Namespace < list[j].Namespace
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// PodGroupManager returns the gangs going through Permit, for other plugins
+// and tools to follow their progress.
+func (cs *CustomScheduler) PodGroupManager() *PodGroupManager {
+	return &cs.podGroupManager
+}
@@ -0,0 +1,90 @@
+package plugins
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestPodGroupManager(t *testing.T) {
+	pods := makeGroupPods("g1", 3, 3)
+	for _, p := range pods {
+		p.UID = types.UID(p.Name)
+	}
+	now := time.Now()
+	clock := testingclock.NewFakeClock(now)
+	node := makeNodeInfo("n1", 1000, 1000)
+	cs := &CustomScheduler{clock: clock, pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{node}}
+	fwk := newPermitFramework(t, cs)
+	m := cs.PodGroupManager()
+
+	if _, ok := m.GetGroupStatus(pods[0].Namespace, "g1"); ok {
+		t.Fatal("expected no status before any member reached Permit")
+	}
+	for _, p := range pods[:2] {
+		if status := fwk.RunPermitPlugins(context.Background(), framework.NewCycleState(), p, "n1"); !status.IsWait() {
+			t.Fatalf("expected to wait, got %v", status)
+		}
+		// the waiting members are assumed on n1.
+		node.AddPod(p)
+	}
+	deadline := now.Add(defaultPermitTimeout)
+	want := GroupStatus{Namespace: pods[0].Namespace, Name: "g1", MinAvailable: 3, Waiting: []string{pods[0].Name, pods[1].Name}, Deadline: &deadline}
+	if got := m.ListWaitingGroups(); !reflect.DeepEqual(got, []GroupStatus{want}) {
+		t.Errorf("expected the waiting groups %+v, got %+v", []GroupStatus{want}, got)
+	}
+
+	if status := fwk.RunPermitPlugins(context.Background(), framework.NewCycleState(), pods[2], "n1"); !status.IsSuccess() {
+		t.Fatalf("expected the gang to be complete, got %v", status)
+	}
+	want = GroupStatus{Namespace: pods[0].Namespace, Name: "g1", MinAvailable: 3, Waiting: []string{}, Permitted: []string{pods[0].Name, pods[1].Name, pods[2].Name}}
+	if got, ok := m.GetGroupStatus(pods[0].Namespace, "g1"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the status %+v, got %+v", want, got)
+	}
+	if got := m.ListWaitingGroups(); len(got) != 0 {
+		t.Errorf("expected no waiting groups, got %+v", got)
+	}
+
+	clock.Step(permittedRetention + time.Second)
+	m.prune(clock.Now())
+	if _, ok := m.GetGroupStatus(pods[0].Namespace, "g1"); ok {
+		t.Error("expected the released gang to be forgotten after the retention")
+	}
+}
+
+// TestPodGroupManager_RecreatedMember tracks a member recreated under the
+// same name, e.g. by its controller, apart from the one it replaces.
+func TestPodGroupManager_RecreatedMember(t *testing.T) {
+	pods := makeGroupPods("g1", 2, 1)
+	pods[0].UID = "old"
+	recreated := pods[0].DeepCopy()
+	recreated.UID = "new"
+	group := &podGroup{namespace: pods[0].Namespace, name: "g1", minAvailable: 2}
+	now := time.Now()
+	var m PodGroupManager
+	m.wait(group, pods[0], now, defaultPermitTimeout)
+	m.wait(group, recreated, now, defaultPermitTimeout)
+	status, ok := m.GetGroupStatus(pods[0].Namespace, "g1")
+	if want := []string{pods[0].Name, pods[0].Name}; !ok || !reflect.DeepEqual(status.Waiting, want) {
+		t.Errorf("expected the waiting members %v, got %+v", want, status)
+	}
+}
+
+func TestPodGroupManager_UnreserveForgets(t *testing.T) {
+	pods := makeGroupPods("g1", 2, 2)
+	cs := &CustomScheduler{clock: testingclock.NewFakeClock(time.Now()), pods: &faultyPodLister{stale: pods}, nodes: fakeframework.NodeInfoLister{makeNodeInfo("n1", 1000, 1000)}}
+	newPermitFramework(t, cs)
+	if status, _ := cs.Permit(context.Background(), nil, pods[0], "n1"); !status.IsWait() {
+		t.Fatalf("expected to wait, got %v", status)
+	}
+	cs.Unreserve(context.Background(), nil, pods[0], "n1")
+	if _, ok := cs.PodGroupManager().GetGroupStatus(pods[0].Namespace, "g1"); ok {
+		t.Error("expected the rejected gang to be forgotten")
+	}
+}
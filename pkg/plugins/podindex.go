@@ -0,0 +1,96 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// groupIndexLabels are the labels naming the gang of a pod that the pod
+// cache is indexed by, each under an index of its own name keyed by the
+// namespace of the pod and the value of the label.
+var groupIndexLabels = []string{groupNameLabel, podGroupLabel}
+
+// controllerUIDIndex indexes pods by namespace and the UID of their
+// controller, which training jobs find their members by.
+const controllerUIDIndex = "controller-uid"
+
+// indexPodsByGroup indexes the pods of informer by groupIndexLabels and
+// controllerUIDIndex, unless another profile of the plugin already did, and
+// returns its indexer.
+// Indexes can only be added before the informer starts, which New runs
+// before.
+func indexPodsByGroup(informer cache.SharedIndexInformer) (cache.Indexer, error) {
+	indexers := cache.Indexers{}
+	existing := informer.GetIndexer().GetIndexers()
+	for _, label := range groupIndexLabels {
+		if _, ok := existing[label]; !ok {
+			indexers[label] = indexByLabel(label)
+		}
+	}
+	if _, ok := existing[controllerUIDIndex]; !ok {
+		indexers[controllerUIDIndex] = indexByControllerUID
+	}
+	if len(indexers) > 0 {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return nil, fmt.Errorf("failed to index pods by group: %w", err)
+		}
+	}
+	return informer.GetIndexer(), nil
+}
+
+// indexByLabel indexes pods by namespace and the value of label.
+func indexByLabel(label string) cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			return nil, nil
+		}
+		value, ok := pod.Labels[label]
+		if !ok {
+			return nil, nil
+		}
+		return []string{pod.Namespace + "/" + value}, nil
+	}
+}
+
+// indexByControllerUID indexes pods by namespace and the UID of their
+// controller.
+func indexByControllerUID(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil, nil
+	}
+	return []string{pod.Namespace + "/" + string(ref.UID)}, nil
+}
+
+// candidateMembers returns the pods of the pod cache group.selector matches
+// in the namespace of group: through the index of group.indexLabel, which
+// only holds its members, if the pods are indexed, or else by listing the
+// namespace.
+func (cs *CustomScheduler) candidateMembers(group *podGroup) ([]*v1.Pod, error) {
+	if cs.podIndexer == nil || group.indexLabel == "" {
+		return cs.listNamespacedPods(group.namespace, group.selector)
+	}
+	value := group.indexValue
+	if value == "" {
+		value = group.name
+	}
+	objs, err := cs.podIndexer.ByIndex(group.indexLabel, group.namespace+"/"+value)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*v1.Pod, 0, len(objs))
+	for _, obj := range objs {
+		if pod, ok := obj.(*v1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
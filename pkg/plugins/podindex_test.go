@@ -0,0 +1,67 @@
+package plugins
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIndexPodsByGroup(t *testing.T) {
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Pods().Informer()
+	// every profile of the plugin indexes the shared informer.
+	for i := 0; i < 2; i++ {
+		if _, err := indexPodsByGroup(informer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexer := informer.GetIndexer()
+
+	labelled := makeGroupPods("g1", 2, 2)
+	crd := makePodGroupPods("g1", 1)
+	other := makeGroupPods("g1", 2, 1)[0]
+	other.Name, other.Namespace = "other", "other"
+	for _, p := range append(append(labelled, crd...), other, makeGroupPods("g2", 1, 1)[0]) {
+		if err := indexer.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a failing lister shows the members are looked up through the index.
+	cs := &CustomScheduler{podIndexer: indexer, pods: &faultyPodLister{err: errors.New("listed")}}
+	for _, tt := range []struct {
+		name  string
+		group *podGroup
+		want  []*v1.Pod
+	}{
+		{name: "labelled", group: &podGroup{name: "g1", namespace: labelled[0].Namespace, indexLabel: groupNameLabel}, want: labelled},
+		{name: "PodGroup", group: &podGroup{name: "g1", namespace: "default", indexLabel: podGroupLabel}, want: crd},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			members, err := cs.groupMembers(tt.group)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := podNames(members), podNames(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected members %v, got %v", want, got)
+			}
+		})
+	}
+
+	// groups without an index are listed.
+	if _, err := cs.groupMembers(&podGroup{name: "g1", namespace: "default"}); err == nil {
+		t.Error("expected a group without an index label to be listed")
+	}
+}
+
+func podNames(pods []*v1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, p := range pods {
+		names = append(names, p.Namespace+"/"+p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
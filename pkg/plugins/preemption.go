@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// preemptedReason is the event reason for pods evicted to make room for a gang.
+const preemptedReason string = "Preempted"
+
+// preemptForGang looks for lower-priority pods whose eviction makes room
+// for the members of the gang of pod still missing to reach minAvailable,
+// pod first. Either every one of them fits once the victims are gone and all
+// victims are evicted, or none is. Only nodes the filters rejected as
+// resolvable are considered, and only by their resources; disruption budgets
+// are not consulted. It returns nil if the gang cannot be made to fit.
+func (cs *CustomScheduler) preemptForGang(ctx context.Context, state *framework.CycleState, pod *v1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	group, members, status := cs.groupAndMembers(state, pod)
+	if !status.IsSuccess() || len(members) < group.minAvailable {
+		return nil, nil
+	}
+	isMember := make(map[string]bool, len(members))
+	needed := group.minAvailable
+	var pending []*v1.Pod
+	for _, p := range members {
+		isMember[podKey(p)] = true
+		if p.Spec.NodeName != "" {
+			needed--
+		} else if podKey(p) != podKey(pod) {
+			pending = append(pending, p)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Name < pending[j].Name })
+	toPlace := append([]*v1.Pod{pod}, pending...)
+	if needed < 1 {
+		needed = 1
+	}
+	if needed > len(toPlace) {
+		return nil, nil
+	}
+	toPlace = toPlace[:needed]
+
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return nil, nil
+	}
+	var nodes []*framework.NodeInfo
+	for _, ni := range nodeInfos {
+		if s := filteredNodeStatusMap[ni.Node().Name]; s.Code() == framework.UnschedulableAndUnresolvable {
+			continue
+		}
+		nodes = append(nodes, ni.Clone())
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Node().Name < nodes[j].Node().Name })
+
+	var victims []*v1.Pod
+	nominated := ""
+	for _, p := range toPlace {
+		node, evicted := placeWithPreemption(p, nodes, isMember)
+		if node == nil {
+			return nil, nil
+		}
+		victims = append(victims, evicted...)
+		node.AddPod(p)
+		if nominated == "" {
+			nominated = node.Node().Name
+		}
+	}
+	if len(victims) == 0 {
+		// the gang fits as it is, so something other than capacity keeps it out.
+		return nil, nil
+	}
+
+	if status := cycleAborted(ctx); status != nil {
+		return nil, status
+	}
+	for _, victim := range victims {
+		uid := victim.UID
+		err := cs.handle.ClientSet().CoreV1().Pods(victim.Namespace).Delete(ctx, victim.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}})
+		if err != nil {
+			return nil, framework.AsStatus(fmt.Errorf("failed to preempt pod %s/%s for pod group %s: %w", victim.Namespace, victim.Name, group.name, err))
+		}
+		cs.recordEvent(victim, v1.EventTypeNormal, preemptedReason, "Preempting", fmt.Sprintf("preempted by pod group %s/%s", group.namespace, group.name))
+	}
+	cs.logger().V(2).Info("Preempted pods for the members of a pod group", "pod", klog.KObj(pod), "group", group.name, "victims", len(victims), "members", len(toPlace), "node", nominated)
+	return framework.NewPostFilterResultWithNominatedNode(nominated), framework.NewStatus(framework.Success)
+}
+
+// placeWithPreemption returns the node of nodes that pod fits on after
+// evicting the fewest pods of lower priority that are not members of its
+// gang, and those pods, removed from the node. It returns a nil node if pod
+// fits nowhere.
+func placeWithPreemption(pod *v1.Pod, nodes []*framework.NodeInfo, isMember map[string]bool) (*framework.NodeInfo, []*v1.Pod) {
+	priority := corev1helpers.PodPriority(pod)
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	var best *framework.NodeInfo
+	var bestVictims []*v1.Pod
+	for _, ni := range nodes {
+		if fitsResources(requests, ni) {
+			return ni, nil
+		}
+		var candidates []*v1.Pod
+		for _, pi := range ni.Pods {
+			if corev1helpers.PodPriority(pi.Pod) < priority && !isMember[podKey(pi.Pod)] {
+				candidates = append(candidates, pi.Pod)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return corev1helpers.PodPriority(candidates[i]) < corev1helpers.PodPriority(candidates[j])
+		})
+		trial := ni.Clone()
+		var evicted []*v1.Pod
+		for _, c := range candidates {
+			if best != nil && len(evicted)+1 >= len(bestVictims) {
+				break
+			}
+			if err := trial.RemovePod(c); err != nil {
+				break
+			}
+			evicted = append(evicted, c)
+			if fitsResources(requests, trial) {
+				best, bestVictims = ni, evicted
+				break
+			}
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	for _, victim := range bestVictims {
+		if err := best.RemovePod(victim); err != nil {
+			return nil, nil
+		}
+	}
+	return best, bestVictims
+}
+
+// fitsResources reports whether requests fit in what nodeInfo has left.
+func fitsResources(requests v1.ResourceList, nodeInfo *framework.NodeInfo) bool {
+	if n := nodeInfo.Allocatable.AllowedPodNumber; n > 0 && len(nodeInfo.Pods) >= n {
+		return false
+	}
+	for name := range requests {
+		if requestAmount(requests, name) > resourceAmount(nodeInfo.Allocatable, name)-resourceAmount(nodeInfo.Requested, name) {
+			return false
+		}
+	}
+	return true
+}
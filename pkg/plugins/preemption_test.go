@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func makeRunningPod(name, nodeName string, priority int32, milliCPU int64) *v1.Pod {
+	p := makeRequestingPod("default", name, nodeName, v1.ResourceList{v1.ResourceCPU: *resource.NewMilliQuantity(milliCPU, resource.DecimalSI)})
+	p.UID = types.UID(name)
+	p.Spec.Priority = &priority
+	return p
+}
+
+func TestCustomScheduler_PostFilterGangPreemption(t *testing.T) {
+	tests := []struct {
+		name string
+		// priorities of the pods running on n1 and n2.
+		running     [2]int32
+		wantNode    string
+		wantVictims []string
+	}{
+		{name: "both members fit after preemption", running: [2]int32{0, 0}, wantNode: "n1", wantVictims: []string{"low-n1", "low-n2"}},
+		{name: "second member does not fit", running: [2]int32{0, 100}},
+		{name: "victims must have lower priority", running: [2]int32{10, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gang := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 2, MinAvailable: 2, Priority: 10, Shape: fixtures.Shape{CPU: "600m", Memory: "512Mi"}}.Pods()
+			for _, p := range gang {
+				p.UID = types.UID(p.Name)
+			}
+			n1, n2 := makeNodeInfo("n1", 1000, 4*1024*1024*1024), makeNodeInfo("n2", 1000, 4*1024*1024*1024)
+			running := []*v1.Pod{makeRunningPod("low-n1", "n1", tt.running[0], 600), makeRunningPod("low-n2", "n2", tt.running[1], 600)}
+			n1.AddPod(running[0])
+			n2.AddPod(running[1])
+
+			client := clientsetfake.NewSimpleClientset(running[0], running[1])
+			fh, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				},
+				"default-scheduler",
+				wait.NeverStop,
+				frameworkruntime.WithClientSet(client),
+			)
+			if err != nil {
+				t.Fatalf("fail to create framework: %s", err)
+			}
+			cs := &CustomScheduler{handle: fh, scoreMode: leastMode, gangPreemption: true,
+				pods: &faultyPodLister{stale: append(gang, running...)}, nodes: fakeframework.NodeInfoLister{n1, n2}}
+
+			result, status := cs.PostFilter(context.Background(), framework.NewCycleState(), gang[0], framework.NodeToStatusMap{
+				"n1": framework.NewStatus(framework.Unschedulable),
+				"n2": framework.NewStatus(framework.Unschedulable),
+			})
+			if tt.wantNode == "" {
+				if status.Code() != framework.Unschedulable {
+					t.Errorf("expected Unschedulable, got %v", status)
+				}
+			} else if !status.IsSuccess() || result == nil || result.NominatedNodeName != tt.wantNode {
+				t.Errorf("expected to nominate %s, got %v and %v", tt.wantNode, result, status)
+			}
+
+			left, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(running) - len(left.Items); got != len(tt.wantVictims) {
+				t.Errorf("expected %d pods to be preempted, got %d", len(tt.wantVictims), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package plugins
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Less orders the scheduling queue: higher priority first, including the
+// boost of pods that have waited long with aging enabled; then, in the
+// EarliestDeadlineFirst order, pods that must start earliest to meet their
+// deadline, ahead of pods without one, or, in the Group order, pods of the
+// group created first and then of the group named first; then, with fair
+// sharing enabled, pods of the namespace with the smaller dominant resource
+// share; and then the pod enqueued first. Aging and fair shares change over
+// time, which the queue only picks up as pods move through it, so with
+// either of them the order is approximate.
+func (cs *CustomScheduler) Less(pInfo1, pInfo2 *framework.QueuedPodInfo) bool {
+	p1 := cs.agedPriority(pInfo1.Pod)
+	p2 := cs.agedPriority(pInfo2.Pod)
+	if p1 != p2 {
+		return p1 > p2
+	}
+	if cs.config().queueOrder == queueOrderEarliestDeadlineFirst {
+		s1, ok1 := latestStart(pInfo1.Pod)
+		s2, ok2 := latestStart(pInfo2.Pod)
+		if ok1 != ok2 {
+			return ok1
+		}
+		if ok1 && !s1.Equal(s2) {
+			return s1.Before(s2)
+		}
+	}
+	if cs.config().queueOrder == queueOrderGroup {
+		k1, c1 := cs.queueGroup(pInfo1.Pod)
+		k2, c2 := cs.queueGroup(pInfo2.Pod)
+		if !c1.Equal(c2) {
+			return c1.Before(c2)
+		}
+		if k1 != k2 {
+			return k1 < k2
+		}
+	}
+	if cs.fairShare != nil && pInfo1.Pod.Namespace != pInfo2.Pod.Namespace {
+		s1 := cs.fairShare.share(pInfo1.Pod.Namespace)
+		s2 := cs.fairShare.share(pInfo2.Pod.Namespace)
+		if s1 != s2 {
+			return s1 < s2
+		}
+	}
+	return pInfo1.Timestamp.Before(pInfo2.Timestamp)
+}
+
+// queueGroup returns the namespace/name of the group of pod and when the
+// group was created, for the Group queue order: the creation time of its
+// PodGroup or Volcano PodGroup, or else that of its oldest member. Pods
+// without a group are ordered by their own creation time.
+func (cs *CustomScheduler) queueGroup(pod *v1.Pod) (string, time.Time) {
+	if cs.podGroups != nil {
+		if name := pod.Labels[podGroupLabel]; name != "" {
+			if pg, err := cs.podGroups.Get(pod.Namespace, name); err == nil && pg != nil {
+				return pod.Namespace + "/" + name, pg.GetCreationTimestamp().Time
+			}
+		}
+	}
+	if cs.volcanoPodGroups != nil {
+		if name := volcanoGroupName(pod); name != "" {
+			if pg, err := cs.volcanoPodGroups.Get(pod.Namespace, name); err == nil && pg != nil {
+				return pod.Namespace + "/" + name, pg.GetCreationTimestamp().Time
+			}
+		}
+	}
+	name := pod.Labels[groupNameLabel]
+	if name == "" {
+		return "", pod.CreationTimestamp.Time
+	}
+	created := pod.CreationTimestamp.Time
+	// Less runs on every comparison of the queue, so the members are looked
+	// up through the pod index rather than by listing the pod cache.
+	group := &podGroup{
+		name:       name,
+		namespace:  pod.Namespace,
+		selector:   labels.SelectorFromSet(labels.Set{groupNameLabel: name}),
+		indexLabel: groupNameLabel,
+	}
+	if members, err := cs.candidateMembers(group); err == nil {
+		for _, p := range members {
+			if p.Namespace == pod.Namespace && p.CreationTimestamp.Time.Before(created) {
+				created = p.CreationTimestamp.Time
+			}
+		}
+	}
+	return pod.Namespace + "/" + name, created
+}
@@ -0,0 +1,146 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestCustomScheduler_LessEarliestDeadlineFirst(t *testing.T) {
+	now := time.Now()
+	queued := func(priority int32, annotations map[string]string, enqueued time.Time) *framework.QueuedPodInfo {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}, Spec: v1.PodSpec{Priority: &priority}}
+		return &framework.QueuedPodInfo{PodInfo: &framework.PodInfo{Pod: pod}, Timestamp: enqueued}
+	}
+	noon := map[string]string{deadlineAnnotation: "2023-06-01T12:00:00Z"}
+	evening := map[string]string{deadlineAnnotation: "2023-06-01T18:00:00Z"}
+	// must start by 10:00, before the noon deadline.
+	longEvening := map[string]string{deadlineAnnotation: "2023-06-01T18:00:00Z", expectedRuntimeAnnotation: "8h"}
+
+	tests := []struct {
+		name  string
+		order string
+		a, b  *framework.QueuedPodInfo
+		want  bool
+	}{
+		{name: "priority first", order: queueOrderEarliestDeadlineFirst, a: queued(0, noon, now), b: queued(1, evening, now), want: false},
+		{name: "earlier deadline first", order: queueOrderEarliestDeadlineFirst, a: queued(0, evening, now), b: queued(0, noon, now.Add(time.Minute)), want: false},
+		{name: "least slack first", order: queueOrderEarliestDeadlineFirst, a: queued(0, longEvening, now.Add(time.Minute)), b: queued(0, noon, now), want: true},
+		{name: "deadline before none", order: queueOrderEarliestDeadlineFirst, a: queued(0, evening, now.Add(time.Minute)), b: queued(0, nil, now), want: true},
+		{name: "invalid deadline is none", order: queueOrderEarliestDeadlineFirst, a: queued(0, map[string]string{deadlineAnnotation: "tonight"}, now.Add(time.Minute)), b: queued(0, nil, now), want: false},
+		{name: "priority order ignores deadlines", order: queueOrderPriority, a: queued(0, noon, now.Add(time.Minute)), b: queued(0, evening, now), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{queueOrder: tt.order}
+			if got := cs.Less(tt.a, tt.b); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_LessAging(t *testing.T) {
+	now := time.Now()
+	clock := testingclock.NewFakeClock(now)
+	queued := func(priority int32, created time.Time) *framework.QueuedPodInfo {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}, Spec: v1.PodSpec{Priority: &priority}}
+		return &framework.QueuedPodInfo{PodInfo: &framework.PodInfo{Pod: pod}, Timestamp: now}
+	}
+	aging, err := newAgingArgs(AgingArgs{AfterSeconds: 600, IntervalSeconds: 60, Boost: 10, MaxBoost: 50, RelaxTopology: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &CustomScheduler{clock: clock, aging: aging}
+
+	tests := []struct {
+		name string
+		a, b *framework.QueuedPodInfo
+		want bool
+	}{
+		{name: "not aged yet", a: queued(0, now.Add(-9*time.Minute)), b: queued(5, now), want: false},
+		{name: "aged gang overtakes higher priority", a: queued(0, now.Add(-10*time.Minute)), b: queued(5, now), want: true},
+		{name: "boost grows every interval", a: queued(0, now.Add(-12*time.Minute)), b: queued(25, now), want: true},
+		{name: "boost is capped", a: queued(0, now.Add(-24*time.Hour)), b: queued(51, now), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cs.Less(tt.a, tt.b); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if cs.topologyRelaxed(queued(0, now).Pod) || !cs.topologyRelaxed(queued(0, now.Add(-time.Hour)).Pod) {
+		t.Errorf("expected only aged pods to have their topology relaxed")
+	}
+	if _, err := newAgingArgs(AgingArgs{Boost: -1}); err == nil {
+		t.Errorf("expected a negative boost to be rejected")
+	}
+}
+
+func TestCustomScheduler_LessGroup(t *testing.T) {
+	now := time.Now()
+	queued := func(name, group string, priority int32, created, enqueued time.Time) *framework.QueuedPodInfo {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created)}, Spec: v1.PodSpec{Priority: &priority}}
+		if group != "" {
+			pod.Labels = map[string]string{groupNameLabel: group, minAvailableLabel: "2"}
+		}
+		return &framework.QueuedPodInfo{PodInfo: &framework.PodInfo{Pod: pod}, Timestamp: enqueued}
+	}
+	// a-1 was created late, but a-0 makes group a older than group b.
+	a0 := queued("a-0", "a", 0, now, now)
+	a1 := queued("a-1", "a", 0, now.Add(2*time.Minute), now.Add(2*time.Minute))
+	b0 := queued("b-0", "b", 0, now.Add(time.Minute), now.Add(time.Minute))
+	// c-0 and d-0 were created together.
+	c0 := queued("c-0", "c", 0, now.Add(3*time.Minute), now.Add(4*time.Minute))
+	d0 := queued("d-0", "d", 0, now.Add(3*time.Minute), now.Add(3*time.Minute))
+	solo := queued("solo", "", 0, now.Add(90*time.Second), now)
+	urgent := queued("urgent", "b", 1, now.Add(time.Minute), now.Add(time.Minute))
+	var pods []*v1.Pod
+	for _, p := range []*framework.QueuedPodInfo{a0, a1, b0, c0, d0, solo} {
+		pods = append(pods, p.Pod)
+	}
+	listed := &CustomScheduler{queueOrder: queueOrderGroup, pods: &faultyPodLister{stale: pods}}
+	// a failing lister shows the members are looked up through the index.
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Pods().Informer()
+	indexer, err := indexPodsByGroup(informer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range pods {
+		if err := indexer.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexed := &CustomScheduler{queueOrder: queueOrderGroup, podIndexer: indexer, pods: &faultyPodLister{err: errors.New("listed")}}
+
+	tests := []struct {
+		name string
+		a, b *framework.QueuedPodInfo
+		want bool
+	}{
+		{name: "priority first", a: a1, b: urgent, want: false},
+		{name: "older group first", a: a1, b: b0, want: true},
+		{name: "group before later pod without one", a: a1, b: solo, want: true},
+		{name: "pod without a group by its creation", a: solo, b: b0, want: false},
+		{name: "group name breaks ties", a: c0, b: d0, want: true},
+		{name: "members by enqueue time", a: a1, b: a0, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cs := range []*CustomScheduler{listed, indexed} {
+				if got := cs.Less(tt.a, tt.b); got != tt.want {
+					t.Errorf("indexed %v: expected %v, got %v", cs.podIndexer != nil, tt.want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// gpuResourceName is the resource the quotaGPU label bounds.
+const gpuResourceName v1.ResourceName = "nvidia.com/gpu"
+
+// groupQuotaLabels are the labels declaring the quota of a labelled gang,
+// and the resource each bounds.
+var groupQuotaLabels = []struct {
+	label    string
+	resource v1.ResourceName
+}{
+	{label: "quotaCPU", resource: v1.ResourceCPU},
+	{label: "quotaMemory", resource: v1.ResourceMemory},
+	{label: "quotaGPU", resource: gpuResourceName},
+}
+
+// labelGroupQuota returns the quota declared by the quota labels of pod in
+// the labelled gang name, nil if it has none.
+func labelGroupQuota(pod *v1.Pod, name string) (v1.ResourceList, *framework.Status) {
+	var quota v1.ResourceList
+	for _, l := range groupQuotaLabels {
+		value, ok := pod.Labels[l.label]
+		if !ok {
+			continue
+		}
+		quantity, err := parseQuota(value)
+		if err != nil {
+			return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				fmt.Sprintf("invalid %s label %q of pod group %s: %v", l.label, truncate(value), name, err))
+		}
+		if quota == nil {
+			quota = v1.ResourceList{}
+		}
+		quota[l.resource] = quantity
+	}
+	return quota, nil
+}
+
+// crdGroupQuota returns spec.quota of the PodGroup pg, a map of resource
+// names to quantities, nil if it has none. The CRD schema only keeps cpu,
+// memory and nvidia.com/gpu, the resources the quota labels cover.
+func crdGroupQuota(pg *unstructured.Unstructured) (v1.ResourceList, error) {
+	spec, found, err := unstructured.NestedMap(pg.Object, "spec", "quota")
+	if err != nil || !found {
+		return nil, err
+	}
+	quota := v1.ResourceList{}
+	for name, value := range spec {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid resource name %q: %s", truncate(name), strings.Join(errs, "; "))
+		}
+		quantity, err := parseQuota(fmt.Sprint(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		quota[v1.ResourceName(name)] = quantity
+	}
+	return quota, nil
+}
+
+// parseQuota parses a quota quantity, which must not be negative.
+func parseQuota(value string) (resource.Quantity, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	if quantity.Sign() < 0 {
+		return resource.Quantity{}, fmt.Errorf("must not be negative")
+	}
+	return quantity, nil
+}
+
+// checkGroupQuota rejects pod if, with it, the members of its group admitted
+// so far would request more of a resource than the quota of the group, so
+// that one runaway gang cannot take over the cluster. Members on a node in
+// the snapshot, bound or assumed (including those waiting in Permit), are
+// admitted; the rejection lifts as they finish.
+func (cs *CustomScheduler) checkGroupQuota(pod *v1.Pod, group *podGroup, members []*v1.Pod) *framework.Status {
+	if !cs.groupQuota || len(group.quota) == 0 {
+		return nil
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list node infos: %v", err))
+	}
+	placed := map[string]bool{}
+	for _, ni := range nodeInfos {
+		for _, pi := range ni.Pods {
+			placed[podKey(pi.Pod)] = true
+		}
+	}
+	requested := framework.NewResource(resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{}))
+	for _, p := range members {
+		if placed[podKey(p)] && podKey(p) != podKey(pod) {
+			requested.Add(resourcehelper.PodRequests(p, resourcehelper.PodResourcesOptions{}))
+		}
+	}
+
+	var exceeded []string
+	for name := range group.quota {
+		if amount, limit := resourceAmount(requested, name), requestAmount(group.quota, name); amount > limit {
+			exceeded = append(exceeded, fmt.Sprintf("%s=%s of %s", name, formatAmount(name, amount), formatAmount(name, limit)))
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	sort.Strings(exceeded)
+	return framework.NewStatus(framework.Unschedulable,
+		fmt.Sprintf("pod group %s would exceed its quota with the pod admitted: %s", group.name, strings.Join(exceeded, ", ")))
+}
+
+// formatAmount renders an amount of name, CPU in millicores, as a quantity.
+func formatAmount(name v1.ResourceName, amount int64) string {
+	if name == v1.ResourceCPU {
+		return resource.NewMilliQuantity(amount, resource.DecimalSI).String()
+	}
+	if name == v1.ResourceMemory || name == v1.ResourceEphemeralStorage {
+		return resource.NewQuantity(amount, resource.BinarySI).String()
+	}
+	return resource.NewQuantity(amount, resource.DecimalSI).String()
+}
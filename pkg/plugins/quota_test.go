@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestCustomScheduler_GroupQuota(t *testing.T) {
+	gang := func(labels map[string]string) []*v1.Pod {
+		return fixtures.GroupSpec{
+			Name: "g1", Namespace: "default", Size: 4, MinAvailable: 1,
+			Shape:  fixtures.Shape{CPU: "1", Memory: "1Gi"},
+			Labels: labels,
+		}.Pods()
+	}
+	for _, tt := range []struct {
+		name     string
+		labels   map[string]string
+		admitted int
+		disabled bool
+		want     framework.Code
+		wantMsg  string
+	}{
+		{name: "within the quota", labels: map[string]string{"quotaCPU": "3"}, admitted: 2, want: framework.Success},
+		{name: "CPU quota exceeded", labels: map[string]string{"quotaCPU": "2500m"}, admitted: 2, want: framework.Unschedulable, wantMsg: "cpu=3 of 2500m"},
+		{name: "memory quota exceeded", labels: map[string]string{"quotaMemory": "2Gi"}, admitted: 2, want: framework.Unschedulable, wantMsg: "memory=3Gi of 2Gi"},
+		{name: "GPU quota of zero", labels: map[string]string{"quotaGPU": "0"}, admitted: 0, want: framework.Success},
+		{name: "check disabled", labels: map[string]string{"quotaCPU": "1"}, admitted: 3, disabled: true, want: framework.Success},
+		{name: "pending members do not count", labels: map[string]string{"quotaCPU": "1"}, want: framework.Success},
+		{name: "invalid quota", labels: map[string]string{"quotaCPU": "lots"}, want: framework.UnschedulableAndUnresolvable},
+		{name: "negative quota", labels: map[string]string{"quotaMemory": "-1Gi"}, want: framework.UnschedulableAndUnresolvable},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := gang(tt.labels)
+			node := plugintesting.MakeNodeInfo("n1", 16000, 64<<30)
+			for _, p := range pods[1 : 1+tt.admitted] {
+				p.Spec.NodeName = "n1"
+				node.AddPod(p)
+			}
+			fwk, err := plugintesting.NewFramework([]*framework.NodeInfo{node}, pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, groupQuota: !tt.disabled}
+			_, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0])
+			if status.Code() != tt.want || !strings.Contains(status.Message(), tt.wantMsg) {
+				t.Errorf("expected %v with %q, got %v: %s", tt.want, tt.wantMsg, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestCrdGroupQuota(t *testing.T) {
+	quota, err := crdGroupQuota(makePodGroup("g1", map[string]interface{}{
+		"minMember": int64(2),
+		"quota":     map[string]interface{}{"cpu": "8", "memory": "32Gi", "nvidia.com/gpu": int64(4)},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("8"),
+		v1.ResourceMemory: resource.MustParse("32Gi"),
+		gpuResourceName:   resource.MustParse("4"),
+	}
+	if formatResourceList(quota) != formatResourceList(want) {
+		t.Errorf("expected %s, got %s", formatResourceList(want), formatResourceList(quota))
+	}
+
+	for _, spec := range []map[string]interface{}{
+		{"quota": map[string]interface{}{"cpu": "eight"}},
+		{"quota": map[string]interface{}{"not a name": "1"}},
+		{"quota": "8"},
+	} {
+		if _, err := crdGroupQuota(makePodGroup("g1", spec)); err == nil {
+			t.Errorf("expected an error for %v", spec)
+		}
+	}
+	if quota, err := crdGroupQuota(makePodGroup("g1", map[string]interface{}{})); err != nil || quota != nil {
+		t.Errorf("expected no quota, got %v, %v", quota, err)
+	}
+}
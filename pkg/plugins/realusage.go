@@ -0,0 +1,181 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
+	"my-scheduler-plugins/pkg/usage"
+)
+
+// realLeastMode and realMostMode score nodes like Least and Most, by the
+// memory actually free on them rather than allocatable: allocatable less
+// the usage the metrics provider observes, or less the requests of their
+// pods where the usage is unavailable or stale.
+const (
+	realLeastMode string = "RealLeast"
+	realMostMode  string = "RealMost"
+)
+
+// RealUsageArgs configures the RealLeast and RealMost modes, which read
+// usage from CustomSchedulerArgs.MetricsProvider.
+type RealUsageArgs struct {
+	// CacheSeconds is how long the usage of a node is reused before it is
+	// queried again, 30 by default.
+	CacheSeconds int64 `json:"cacheSeconds,omitempty"`
+	// MaxStalenessSeconds is the age past which the usage the provider
+	// reports is ignored, and the node scored by its requests instead. 120
+	// by default.
+	MaxStalenessSeconds int64 `json:"maxStalenessSeconds,omitempty"`
+}
+
+// realUsage caches the observed usage of nodes.
+type realUsage struct {
+	metrics      usage.MetricsProvider
+	clock        clock.Clock
+	ttl          time.Duration
+	maxStaleness time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedUsage
+}
+
+// cachedUsage is the usage of a node, nil if the provider failed to report
+// it, and when it was queried.
+type cachedUsage struct {
+	usage   *usage.NodeUsage
+	queried time.Time
+}
+
+func newRealUsage(args RealUsageArgs, metrics usage.MetricsProvider, c clock.Clock) (*realUsage, error) {
+	if args.CacheSeconds < 0 {
+		return nil, fmt.Errorf("real usage cacheSeconds must not be negative, got %d", args.CacheSeconds)
+	}
+	if args.MaxStalenessSeconds < 0 {
+		return nil, fmt.Errorf("real usage maxStalenessSeconds must not be negative, got %d", args.MaxStalenessSeconds)
+	}
+	r := &realUsage{
+		metrics:      metrics,
+		clock:        c,
+		ttl:          time.Duration(args.CacheSeconds) * time.Second,
+		maxStaleness: time.Duration(args.MaxStalenessSeconds) * time.Second,
+		cache:        map[string]cachedUsage{},
+	}
+	if r.ttl == 0 {
+		r.ttl = 30 * time.Second
+	}
+	if r.maxStaleness == 0 {
+		r.maxStaleness = 2 * time.Minute
+	}
+	return r, nil
+}
+
+// nodeUsage returns the usage of nodeName, queried again once the cached one
+// is older than the TTL, or nil if it is unavailable or stale. Failures are
+// cached too, so that a provider that is down is not queried for every node
+// of every cycle.
+func (r *realUsage) nodeUsage(ctx context.Context, nodeName string) *usage.NodeUsage {
+	now := r.clock.Now()
+	r.mu.Lock()
+	c, ok := r.cache[nodeName]
+	r.mu.Unlock()
+	if !ok || now.Sub(c.queried) >= r.ttl {
+		u, err := r.metrics.NodeUsage(ctx, nodeName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			klog.FromContext(ctx).V(4).Info("Usage of node is unavailable, scoring it by requests", "node", nodeName, "err", err)
+			u = nil
+		}
+		c = cachedUsage{usage: u, queried: now}
+		r.mu.Lock()
+		r.cache[nodeName] = c
+		r.mu.Unlock()
+	}
+	if c.usage == nil {
+		return nil
+	}
+	if !c.usage.Timestamp.IsZero() && now.Sub(c.usage.Timestamp) > r.maxStaleness {
+		return nil
+	}
+	return c.usage
+}
+
+// retain forgets the usage of the nodes not in names.
+func (r *realUsage) retain(names map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.cache {
+		if !names[name] {
+			delete(r.cache, name)
+		}
+	}
+}
+
+// realFreeMemory returns the memory free on nodeInfo by its observed usage,
+// or by the requests of its pods if there is none.
+func (cs *CustomScheduler) realFreeMemory(ctx context.Context, nodeInfo *framework.NodeInfo) int64 {
+	if cs.realUsage != nil {
+		if u := cs.realUsage.nodeUsage(ctx, nodeInfo.Node().Name); u != nil {
+			return nonNegative(nodeInfo.Allocatable.Memory - u.Memory)
+		}
+	}
+	return nonNegative(nodeInfo.Allocatable.Memory - nodeInfo.Requested.Memory)
+}
+
+const realFreeStateKey = framework.StateKey(Name + "/real-free")
+
+// realFree is the memory free on every node, computed once per cycle so
+// every node is scored by usage of the same age, and the most free on any.
+type realFree struct {
+	free map[string]int64
+	most int64
+}
+
+func (r *realFree) Clone() framework.StateData {
+	return r
+}
+
+// realFree returns the memory free on every node, from state if PreScore
+// stored it.
+func (cs *CustomScheduler) realFree(ctx context.Context, state *framework.CycleState) (*realFree, error) {
+	if state != nil {
+		if data, err := state.Read(realFreeStateKey); err == nil {
+			return data.(*realFree), nil
+		}
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	free := make([]int64, len(nodeInfos))
+	measure := func(i int) {
+		free[i] = cs.realFreeMemory(ctx, nodeInfos[i])
+	}
+	// the provider is queried for every node whose usage is not cached.
+	if cs.handle != nil {
+		cs.handle.Parallelizer().Until(ctx, len(nodeInfos), measure, Name)
+	} else {
+		for i := range nodeInfos {
+			measure(i)
+		}
+	}
+	r := &realFree{free: make(map[string]int64, len(nodeInfos))}
+	names := make(map[string]bool, len(nodeInfos))
+	for i, ni := range nodeInfos {
+		names[ni.Node().Name] = true
+		r.free[ni.Node().Name] = free[i]
+		if free[i] > r.most {
+			r.most = free[i]
+		}
+	}
+	if cs.realUsage != nil {
+		cs.realUsage.retain(names)
+	}
+	return r, nil
+}
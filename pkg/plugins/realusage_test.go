@@ -0,0 +1,83 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	"my-scheduler-plugins/pkg/usage"
+)
+
+// countingMetrics counts the queries of fakeMetrics.
+type countingMetrics struct {
+	fakeMetrics
+	queries int
+}
+
+func (m *countingMetrics) NodeUsage(ctx context.Context, nodeName string) (*usage.NodeUsage, error) {
+	m.queries++
+	return m.fakeMetrics.NodeUsage(ctx, nodeName)
+}
+
+func TestCustomScheduler_RealUsageModes(t *testing.T) {
+	now := time.Now()
+	clock := testingclock.NewFakeClock(now)
+	// equal allocatable; busy uses most of it, idle little, and stale's
+	// usage is too old to trust, so its requests count instead.
+	busy, idle, stale := makeNodeInfo("busy", 1000, 1000), makeNodeInfo("idle", 1000, 1000), makeNodeInfo("stale", 1000, 1000)
+	stale.Requested.Memory = 500
+	metrics := &countingMetrics{fakeMetrics: fakeMetrics{
+		"busy":  {Memory: 900, Timestamp: now},
+		"idle":  {Memory: 100, Timestamp: now},
+		"stale": {Memory: 0, Timestamp: now.Add(-time.Hour)},
+	}}
+	realUsage, err := newRealUsage(RealUsageArgs{}, metrics, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := fakeframework.NodeInfoLister{busy, idle, stale}
+	for _, tt := range []struct {
+		mode string
+		want map[string]int64
+	}{
+		{mode: realMostMode, want: map[string]int64{"busy": 100, "idle": 900, "stale": 500}},
+		{mode: realLeastMode, want: map[string]int64{"busy": 800, "idle": 0, "stale": 400}},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: tt.mode, clock: clock, nodes: nodes, realUsage: realUsage}
+			state := framework.NewCycleState()
+			if status := cs.PreScore(context.Background(), state, makeGroupPods("g1", 1, 1)[0], nil); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for name, want := range tt.want {
+				if got, status := cs.Score(context.Background(), state, makeGroupPods("g1", 1, 1)[0], name); !status.IsSuccess() || got != want {
+					t.Errorf("node %s: expected %d, got %d, %v", name, want, got, status)
+				}
+			}
+		})
+	}
+	if metrics.queries != 3 {
+		t.Errorf("expected the usage of each node to be queried once while cached, got %d queries", metrics.queries)
+	}
+	clock.Step(time.Minute)
+	realUsage.nodeUsage(context.Background(), "busy")
+	if metrics.queries != 4 {
+		t.Errorf("expected the usage to be queried again after the cache expired, got %d queries", metrics.queries)
+	}
+}
+
+func TestRealUsage_Unavailable(t *testing.T) {
+	realUsage, err := newRealUsage(RealUsageArgs{}, fakeMetrics{}, testingclock.NewFakeClock(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ni := makeNodeInfo("n1", 1000, 1000)
+	ni.Requested.Memory = 300
+	cs := &CustomScheduler{realUsage: realUsage}
+	if got := cs.realFreeMemory(context.Background(), ni); got != 700 {
+		t.Errorf("expected to fall back to the 700 not requested, got %d", got)
+	}
+}
@@ -0,0 +1,178 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/utils/clock"
+)
+
+// RebalanceArgs configures the background placement analyzer.
+type RebalanceArgs struct {
+	// IntervalSeconds is how often placements are evaluated, 300 by default.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+	// MinScoreGain is how much higher, in normalized score, another node must
+	// score for a pod to be recommended to move; 50 by default.
+	MinScoreGain int64 `json:"minScoreGain,omitempty"`
+	// Address serves the recommendations as JSON at /recommendations,
+	// "127.0.0.1:10261" by default. Every profile needs its own.
+	Address string `json:"address,omitempty"`
+}
+
+// defaultRebalanceAddress keeps the unauthenticated recommendations off the
+// network unless an address is configured.
+const defaultRebalanceAddress = "127.0.0.1:10261"
+
+// Recommendation is a running pod that would score much better on another node.
+type Recommendation struct {
+	Namespace    string `json:"namespace"`
+	Pod          string `json:"pod"`
+	CurrentNode  string `json:"currentNode"`
+	CurrentScore int64  `json:"currentScore"`
+	TargetNode   string `json:"targetNode"`
+	TargetScore  int64  `json:"targetScore"`
+}
+
+// rebalancer periodically re-scores the nodes of running group members and
+// keeps the latest recommendations for a descheduler to consume.
+type rebalancer struct {
+	scoreMode    func() string
+	minScoreGain int64
+	interval     time.Duration
+	clock        clock.Clock
+	pods         PodLister
+	nodes        listersv1.NodeLister
+
+	mu              sync.RWMutex
+	recommendations []Recommendation
+}
+
+func newRebalancer(args RebalanceArgs, scoreMode func() string, c clock.Clock, pods PodLister, nodes listersv1.NodeLister) *rebalancer {
+	r := &rebalancer{
+		scoreMode:    scoreMode,
+		minScoreGain: args.MinScoreGain,
+		interval:     time.Duration(args.IntervalSeconds) * time.Second,
+		clock:        c,
+		pods:         pods,
+		nodes:        nodes,
+	}
+	if r.minScoreGain <= 0 {
+		r.minScoreGain = 50
+	}
+	if r.interval <= 0 {
+		r.interval = 5 * time.Minute
+	}
+	return r
+}
+
+// run analyzes placements every interval until ctx is done.
+func (r *rebalancer) run(ctx context.Context) {
+	for {
+		timer := r.clock.NewTimer(r.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+			recommendations, err := r.analyze(ctx)
+			if err != nil {
+				klog.FromContext(ctx).Error(err, "Failed to analyze placements")
+				continue
+			}
+			r.mu.Lock()
+			r.recommendations = recommendations
+			r.mu.Unlock()
+		}
+	}
+}
+
+// analyze scores every node for each running group member, against node
+// infos built from the informer caches rather than the scheduling snapshot,
+// which only the scheduling cycle may read.
+func (r *rebalancer) analyze(ctx context.Context) ([]Recommendation, error) {
+	nodes, err := r.nodes.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := r.pods.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByNode := map[string][]*v1.Pod{}
+	for _, p := range pods {
+		if p.Spec.NodeName != "" {
+			podsByNode[p.Spec.NodeName] = append(podsByNode[p.Spec.NodeName], p)
+		}
+	}
+	var nodeInfos fakeframework.NodeInfoLister
+	for _, n := range nodes {
+		ni := framework.NewNodeInfo(podsByNode[n.Name]...)
+		ni.SetNode(n)
+		nodeInfos = append(nodeInfos, ni)
+	}
+	cs := &CustomScheduler{scoreMode: r.scoreMode(), pods: r.pods, nodes: nodeInfos, clock: r.clock}
+
+	var recommendations []Recommendation
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase != v1.PodRunning || p.Labels[groupNameLabel] == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		state := framework.NewCycleState()
+		scores := framework.NodeScoreList{}
+		for _, ni := range nodeInfos {
+			score, status := cs.Score(ctx, state, p, ni.Node().Name)
+			if !status.IsSuccess() {
+				continue
+			}
+			scores = append(scores, framework.NodeScore{Name: ni.Node().Name, Score: score})
+		}
+		if status := cs.NormalizeScore(ctx, state, p, scores); !status.IsSuccess() {
+			continue
+		}
+		if rec, ok := r.recommend(p, scores); ok {
+			recommendations = append(recommendations, rec)
+		}
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].TargetScore-recommendations[i].CurrentScore > recommendations[j].TargetScore-recommendations[j].CurrentScore
+	})
+	return recommendations, nil
+}
+
+func (r *rebalancer) recommend(pod *v1.Pod, scores framework.NodeScoreList) (Recommendation, bool) {
+	rec := Recommendation{Namespace: pod.Namespace, Pod: pod.Name, CurrentNode: pod.Spec.NodeName, TargetScore: -1}
+	for _, s := range scores {
+		if s.Name == pod.Spec.NodeName {
+			rec.CurrentScore = s.Score
+		} else if s.Score > rec.TargetScore {
+			rec.TargetNode, rec.TargetScore = s.Name, s.Score
+		}
+	}
+	return rec, rec.TargetNode != "" && rec.TargetScore-rec.CurrentScore >= r.minScoreGain
+}
+
+// ServeHTTP serves the latest recommendations as JSON.
+func (r *rebalancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	recommendations := r.recommendations
+	r.mu.RUnlock()
+	if recommendations == nil {
+		recommendations = []Recommendation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recommendations)
+}
@@ -0,0 +1,80 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func newNodeLister(t *testing.T, nodes ...*v1.Node) listersv1.NodeLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, n := range nodes {
+		if err := indexer.Add(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return listersv1.NewNodeLister(indexer)
+}
+
+func TestRebalancer_Analyze(t *testing.T) {
+	small := makeNodeInfo("small", 1000, 100).Node()
+	large := makeNodeInfo("large", 1000, 300).Node()
+	pods := makeGroupPods("g1", 1, 2)
+	pods[0].Spec.NodeName, pods[0].Status.Phase = "small", v1.PodRunning
+	pods[1].Spec.NodeName, pods[1].Status.Phase = "large", v1.PodRunning
+	lister := podListerFunc(func(labels.Selector) ([]*v1.Pod, error) { return pods, nil })
+
+	r := newRebalancer(RebalanceArgs{}, func() string { return mostMode }, testingclock.NewFakeClock(time.Now()), lister, newNodeLister(t, small, large))
+	got, err := r.analyze(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Recommendation{Namespace: pods[0].Namespace, Pod: pods[0].Name, CurrentNode: "small", CurrentScore: 0, TargetNode: "large", TargetScore: 100}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("expected [%+v], got %+v", want, got)
+	}
+
+	r.minScoreGain = 101
+	if got, _ := r.analyze(context.Background()); len(got) != 0 {
+		t.Errorf("expected no recommendations above the gain threshold, got %+v", got)
+	}
+}
+
+func TestRebalancer_Run(t *testing.T) {
+	small := makeNodeInfo("small", 1000, 100).Node()
+	large := makeNodeInfo("large", 1000, 300).Node()
+	pods := makeGroupPods("g1", 1, 1)
+	pods[0].Spec.NodeName, pods[0].Status.Phase = "large", v1.PodRunning
+	lister := podListerFunc(func(labels.Selector) ([]*v1.Pod, error) { return pods, nil })
+	clock := testingclock.NewFakeClock(time.Now())
+	r := newRebalancer(RebalanceArgs{IntervalSeconds: 60}, func() string { return leastMode }, clock, lister, newNodeLister(t, small, large))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx)
+
+	var got []Recommendation
+	for i := 0; i < 100 && len(got) == 0; i++ {
+		for !clock.HasWaiters() {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Step(time.Minute)
+		time.Sleep(time.Millisecond)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", "/recommendations", nil))
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 1 || got[0].TargetNode != "small" {
+		t.Errorf("expected a move to small, got %+v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigReloadArgs points at the ConfigMap whose args key holds
+// CustomSchedulerArgs to apply, while the scheduler runs, over those the
+// plugin was created with. Only mode, maxMinAvailable,
+// minAvailableConflictPolicy, tieScore, queueOrder, scoreRange,
+// invertScores, extendedResources, imageLocalityWeight, nodeTierWeights and
+// freeResourceThresholds may be set; removing them, the key or the
+// ConfigMap restores the args the plugin was created with.
+type ConfigReloadArgs struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// configReloadKey is the key of the ConfigMap holding the args.
+const configReloadKey = "args"
+
+// configReloader publishes the configuration of the ConfigReload ConfigMap.
+type configReloader struct {
+	cs   *CustomScheduler
+	name string
+	// args are those the plugin was created with.
+	args CustomSchedulerArgs
+}
+
+func (r *configReloader) handler() cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			cm, ok := obj.(*v1.ConfigMap)
+			return ok && cm.Name == r.name
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { r.reload(obj.(*v1.ConfigMap).Data) },
+			UpdateFunc: func(_, obj interface{}) { r.reload(obj.(*v1.ConfigMap).Data) },
+			DeleteFunc: func(interface{}) { r.reload(nil) },
+		},
+	}
+}
+
+// reload publishes the configuration data asks for, or keeps the current
+// one if it is invalid.
+func (r *configReloader) reload(data map[string]string) {
+	cfg, err := r.config(data)
+	if err != nil {
+		r.cs.logger().Error(err, "Failed to reload the configuration, keeping the current one", "configMap", r.name)
+		return
+	}
+	r.cs.updateConfig(func(c *schedulerConfig) { *c = *cfg })
+	r.cs.logger().Info("Reloaded the configuration", "configMap", r.name, "mode", cfg.scoreMode)
+}
+
+// config returns the configuration of the args in data applied over r.args.
+func (r *configReloader) config(data map[string]string) (*schedulerConfig, error) {
+	args := r.args
+	if raw, ok := data[configReloadKey]; ok {
+		var fields map[string]interface{}
+		if err := yaml.Unmarshal([]byte(raw), &fields); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", configReloadKey, err)
+		}
+		var reloaded CustomSchedulerArgs
+		if err := yaml.UnmarshalStrict([]byte(raw), &reloaded); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", configReloadKey, err)
+		}
+		for field := range fields {
+			if !reloadArg(field, &args, &reloaded) {
+				return nil, fmt.Errorf("%s cannot be reloaded, restart the scheduler to change it", field)
+			}
+		}
+	}
+	if err := ValidateCustomSchedulerArgs(nil, &args); err != nil {
+		return nil, err
+	}
+	cfg, err := newSchedulerConfig(&args)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.scoreMode == numaMode && r.cs.nodeTopologies == nil {
+		return nil, fmt.Errorf("the %s mode needs nodeResourceTopology set when the scheduler starts", numaMode)
+	}
+	return cfg, nil
+}
+
+// reloadArg sets the field of args with the given JSON name to its value
+// in reloaded, and reports whether it may be reloaded: whether it is one
+// of the fields of schedulerConfig.
+func reloadArg(field string, args, reloaded *CustomSchedulerArgs) bool {
+	switch field {
+	case "mode":
+		args.Mode = reloaded.Mode
+	case "maxMinAvailable":
+		args.MaxMinAvailable = reloaded.MaxMinAvailable
+	case "minAvailableConflictPolicy":
+		args.MinAvailableConflictPolicy = reloaded.MinAvailableConflictPolicy
+	case "tieScore":
+		args.TieScore = reloaded.TieScore
+	case "queueOrder":
+		args.QueueOrder = reloaded.QueueOrder
+	case "scoreRange":
+		args.ScoreRange = reloaded.ScoreRange
+	case "invertScores":
+		args.InvertScores = reloaded.InvertScores
+	case "extendedResources":
+		args.ExtendedResources = reloaded.ExtendedResources
+	case "imageLocalityWeight":
+		args.ImageLocalityWeight = reloaded.ImageLocalityWeight
+	case "nodeTierWeights":
+		args.NodeTierWeights = reloaded.NodeTierWeights
+	case "freeResourceThresholds":
+		args.FreeResourceThresholds = reloaded.FreeResourceThresholds
+	default:
+		return false
+	}
+	return true
+}
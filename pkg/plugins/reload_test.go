@@ -0,0 +1,142 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+func TestConfigReloader_Config(t *testing.T) {
+	r := &configReloader{
+		cs:   &CustomScheduler{},
+		name: "custom-scheduler-config",
+		args: CustomSchedulerArgs{Mode: leastMode, ImageLocalityWeight: 10},
+	}
+	tests := []struct {
+		name              string
+		data              map[string]string
+		wantMode          string
+		wantImageLocality int64
+		wantInvertScores  bool
+		wantNodeTiers     int
+		wantErr           bool
+	}{
+		{name: "no ConfigMap", wantMode: leastMode, wantImageLocality: 10},
+		{name: "no args key", data: map[string]string{"other": "mode: Most"}, wantMode: leastMode, wantImageLocality: 10},
+		{
+			name:              "reloaded fields",
+			data:              map[string]string{configReloadKey: "mode: Most\ninvertScores: true\nnodeTierWeights:\n  node.kubernetes.io/lifecycle=spot: 0.5\n"},
+			wantMode:          mostMode,
+			wantImageLocality: 10,
+			wantInvertScores:  true,
+			wantNodeTiers:     1,
+		},
+		{name: "field not reloadable", data: map[string]string{configReloadKey: "resource: cpu"}, wantErr: true},
+		{name: "unknown field", data: map[string]string{configReloadKey: "scoreMode: Most"}, wantErr: true},
+		{name: "invalid mode", data: map[string]string{configReloadKey: "mode: Fastest"}, wantErr: true},
+		{name: "invalid weight", data: map[string]string{configReloadKey: "imageLocalityWeight: 1000"}, wantErr: true},
+		{name: "NUMA mode without topologies", data: map[string]string{configReloadKey: "mode: " + numaMode}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := r.config(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.scoreMode != tt.wantMode || cfg.imageLocalityWeight != tt.wantImageLocality || cfg.invertScores != tt.wantInvertScores || len(cfg.nodeTiers) != tt.wantNodeTiers {
+				t.Errorf("expected mode %s, image locality %d, inverted %v and %d tiers, got %s, %d, %v and %d",
+					tt.wantMode, tt.wantImageLocality, tt.wantInvertScores, tt.wantNodeTiers,
+					cfg.scoreMode, cfg.imageLocalityWeight, cfg.invertScores, len(cfg.nodeTiers))
+			}
+		})
+	}
+}
+
+// TestNew_ConfigReload follows the configuration ConfigMap as it is created,
+// changed to invalid args and deleted.
+func TestNew_ConfigReload(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		ctx.Done(),
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informers.NewSharedInformerFactory(client, 0)),
+		frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: []*framework.NodeInfo{makeNodeInfo("n1", 1000, 100)}}),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	p, err := NewWithContext(ctx)(&CustomSchedulerArgs{
+		Mode:         leastMode,
+		ConfigReload: &ConfigReloadArgs{Namespace: "kube-system", Name: "custom-scheduler-config"},
+	}, fh)
+	if err != nil {
+		t.Fatalf("fail to create plugin: %s", err)
+	}
+	cs := p.(*CustomScheduler)
+	waitForMode := func(want string) {
+		t.Helper()
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return cs.config().scoreMode == want, nil
+		}); err != nil {
+			t.Fatalf("expected mode %s, got %s", want, cs.config().scoreMode)
+		}
+	}
+
+	configMaps := client.CoreV1().ConfigMaps("kube-system")
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "custom-scheduler-config"},
+		Data:       map[string]string{configReloadKey: "mode: Most"},
+	}
+	if _, err := configMaps.Create(context.Background(), cm, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForMode(mostMode)
+
+	// invalid args keep the current configuration.
+	cm.Data[configReloadKey] = "mode: Fastest"
+	if _, err := configMaps.Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	cm.Data[configReloadKey] = "mode: Most\nqueueOrder: Group"
+	if _, err := configMaps.Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return cs.config().queueOrder == queueOrderGroup, nil
+	}); err != nil {
+		t.Fatalf("expected queue order %s, got %s", queueOrderGroup, cs.config().queueOrder)
+	}
+	if got := cs.config().scoreMode; got != mostMode {
+		t.Errorf("expected mode %s, got %s", mostMode, got)
+	}
+
+	// deleting the ConfigMap restores the args the plugin was created with.
+	if err := configMaps.Delete(context.Background(), cm.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForMode(leastMode)
+}
@@ -0,0 +1,190 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"sigs.k8s.io/yaml"
+)
+
+// reservationLabel lets a pod use the nodes of the named reservation.
+const reservationLabel = "nthu.scheduler/reservation"
+
+// backfillAnnotation marks a pod that may backfill reserved nodes, i.e. use
+// them while they are held for others, if its runtime is capped.
+const backfillAnnotation = "nthu.scheduler/backfill"
+
+// ReservationsArgs points at the ConfigMap holding external reservations.
+type ReservationsArgs struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// MaxBackfillSeconds is the runtime cap of backfilling pods: a pod with
+	// the nthu.scheduler/backfill annotation may use reserved nodes if its
+	// activeDeadlineSeconds is at most this, so the kubelet hands the node
+	// back within the cap. Zero, the default, disables backfilling.
+	MaxBackfillSeconds int64 `json:"maxBackfillSeconds,omitempty"`
+}
+
+// Reservation is an external (e.g. Slurm) reservation of nodes for a time
+// window. Each key of the ConfigMap holds one, named after the key.
+type Reservation struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Nodes are node names or Slurm hostlists such as "gpu[01-04,07]".
+	Nodes []string `json:"nodes"`
+	// Owner is the namespace whose pods may use the reserved nodes; pods of
+	// other namespaces need the nthu.scheduler/reservation label.
+	Owner string `json:"owner,omitempty"`
+}
+
+// reservation is a parsed Reservation.
+type reservation struct {
+	name       string
+	start, end time.Time
+	nodes      map[string]bool
+	owner      string
+}
+
+// reservations parses the reservation ConfigMap, re-parsing only when it changes.
+type reservations struct {
+	configMaps         listersv1.ConfigMapNamespaceLister
+	name               string
+	maxBackfillSeconds int64
+
+	mu              sync.Mutex
+	resourceVersion string
+	parsed          []reservation
+}
+
+// get returns the current reservations, or an error if the ConfigMap cannot
+// be parsed. A missing ConfigMap means there are no reservations.
+func (r *reservations) get() ([]reservation, error) {
+	cm, err := r.configMaps.Get(r.name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cm.ResourceVersion == r.resourceVersion && r.parsed != nil {
+		return r.parsed, nil
+	}
+	parsed, err := parseReservations(cm.Data)
+	if err != nil {
+		return nil, err
+	}
+	r.resourceVersion, r.parsed = cm.ResourceVersion, parsed
+	return parsed, nil
+}
+
+func parseReservations(data map[string]string) ([]reservation, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := []reservation{}
+	for _, name := range names {
+		var res Reservation
+		if err := yaml.UnmarshalStrict([]byte(data[name]), &res); err != nil {
+			return nil, fmt.Errorf("invalid reservation %s: %w", name, err)
+		}
+		if !res.End.After(res.Start) {
+			return nil, fmt.Errorf("invalid reservation %s: end must be after start", name)
+		}
+		nodes := map[string]bool{}
+		for _, hostlist := range res.Nodes {
+			hosts, err := expandHostlist(hostlist)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reservation %s: %w", name, err)
+			}
+			for _, h := range hosts {
+				nodes[h] = true
+			}
+		}
+		parsed = append(parsed, reservation{name: name, start: res.Start, end: res.End, nodes: nodes, owner: res.Owner})
+	}
+	return parsed, nil
+}
+
+// expandHostlist expands a Slurm hostlist expression with one bracketed
+// range list, e.g. "gpu[01-03,07]" to gpu01, gpu02, gpu03 and gpu07.
+func expandHostlist(hostlist string) ([]string, error) {
+	open := strings.Index(hostlist, "[")
+	if open < 0 {
+		return []string{hostlist}, nil
+	}
+	end := strings.Index(hostlist, "]")
+	if end < open {
+		return nil, fmt.Errorf("unbalanced brackets in hostlist %q", hostlist)
+	}
+	prefix, suffix := hostlist[:open], hostlist[end+1:]
+	var hosts []string
+	for _, part := range strings.Split(hostlist[open+1:end], ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			hi = lo
+		}
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q in hostlist %q", part, hostlist)
+		}
+		last, err := strconv.Atoi(hi)
+		if err != nil || last < first {
+			return nil, fmt.Errorf("invalid range %q in hostlist %q", part, hostlist)
+		}
+		for i := first; i <= last; i++ {
+			hosts = append(hosts, fmt.Sprintf("%s%0*d%s", prefix, len(lo), i, suffix))
+		}
+	}
+	return hosts, nil
+}
+
+// filterReservations rejects nodes held by an active reservation that pod is
+// not part of.
+func (cs *CustomScheduler) filterReservations(pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if cs.reservations == nil {
+		return nil
+	}
+	active, err := cs.reservations.get()
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("failed to read reservations: %v", err))
+	}
+	now := cs.clock.Now()
+	for _, res := range active {
+		if !res.nodes[nodeInfo.Node().Name] || now.Before(res.start) || !now.Before(res.end) {
+			continue
+		}
+		if pod.Labels[reservationLabel] == res.name || (res.owner != "" && pod.Namespace == res.owner) {
+			continue
+		}
+		if cs.reservations.canBackfill(pod) {
+			continue
+		}
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node is held by reservation %s until %s", res.name, res.end.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// canBackfill reports whether pod may use nodes held by a reservation: it
+// asks to backfill and its runtime is capped at maxBackfillSeconds. The cap
+// cannot be extended once the pod runs, since activeDeadlineSeconds may only
+// ever be lowered.
+func (r *reservations) canBackfill(pod *v1.Pod) bool {
+	if r.maxBackfillSeconds <= 0 || pod.Annotations[backfillAnnotation] != "true" {
+		return false
+	}
+	deadline := pod.Spec.ActiveDeadlineSeconds
+	return deadline != nil && *deadline > 0 && *deadline <= r.maxBackfillSeconds
+}
@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestExpandHostlist(t *testing.T) {
+	got, err := expandHostlist("gpu[01-03,07]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"gpu01", "gpu02", "gpu03", "gpu07"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	for _, invalid := range []string{"gpu[1-", "gpu[3-1]", "gpu[a]"} {
+		if _, err := expandHostlist(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestCustomScheduler_FilterReservations(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "reservations", ResourceVersion: "1"},
+		Data: map[string]string{
+			"maint": "start: 2023-06-01T00:00:00Z\nend: 2023-06-02T00:00:00Z\nnodes: [\"n[1-2]\"]\nowner: physics\n",
+		},
+	})
+	clock := testingclock.NewFakeClock(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	cs := &CustomScheduler{
+		clock:        clock,
+		reservations: &reservations{configMaps: listersv1.NewConfigMapLister(indexer).ConfigMaps("kube-system"), name: "reservations", maxBackfillSeconds: 3600},
+	}
+
+	other := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}
+	owner := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "physics"}}
+	labelled := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Labels: map[string]string{reservationLabel: "maint"}}}
+	backfill := func(deadline *int64) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Annotations: map[string]string{backfillAnnotation: "true"}},
+			Spec:       v1.PodSpec{ActiveDeadlineSeconds: deadline},
+		}
+	}
+	short, long := int64(1800), int64(7200)
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		node string
+		now  time.Time
+		want framework.Code
+	}{
+		{name: "reserved node", pod: other, node: "n1", want: framework.UnschedulableAndUnresolvable},
+		{name: "unreserved node", pod: other, node: "n3", want: framework.Success},
+		{name: "owner namespace", pod: owner, node: "n2", want: framework.Success},
+		{name: "reservation label", pod: labelled, node: "n2", want: framework.Success},
+		{name: "short backfill", pod: backfill(&short), node: "n1", want: framework.Success},
+		{name: "backfill over the cap", pod: backfill(&long), node: "n1", want: framework.UnschedulableAndUnresolvable},
+		{name: "uncapped backfill", pod: backfill(nil), node: "n1", want: framework.UnschedulableAndUnresolvable},
+		{name: "after the window", pod: other, node: "n1", now: time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC), want: framework.Success},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.now.IsZero() {
+				clock.SetTime(tt.now)
+			}
+			status := cs.filterReservations(tt.pod, makeNodeInfo(tt.node, 1000, 100))
+			if status.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, status)
+			}
+		})
+	}
+}
+
+func TestParseReservations_Invalid(t *testing.T) {
+	for _, data := range []string{
+		"start: 2023-06-02T00:00:00Z\nend: 2023-06-01T00:00:00Z\nnodes: [n1]\n",
+		"start: 2023-06-01T00:00:00Z\nend: 2023-06-02T00:00:00Z\nnodes: [n1]\nusers: [alice]\n",
+	} {
+		if _, err := parseReservations(map[string]string{"r": data}); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
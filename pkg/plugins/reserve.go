@@ -0,0 +1,106 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// gangReservations are the nodes reserved for the members of each gang, by
+// namespace/name of the group and then by pod key, from Reserve until the
+// gang is permitted or one of its members is unreserved.
+type gangReservations struct {
+	mu       sync.Mutex
+	reserved map[string]map[string]string
+}
+
+// reserve records that the member podKey of the gang key holds nodeName.
+func (g *gangReservations) reserve(key, podKey, nodeName string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.reserved == nil {
+		g.reserved = map[string]map[string]string{}
+	}
+	if g.reserved[key] == nil {
+		g.reserved[key] = map[string]string{}
+	}
+	g.reserved[key][podKey] = nodeName
+}
+
+// release drops the reservations of the gang key, returning how many there were.
+func (g *gangReservations) release(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := len(g.reserved[key])
+	delete(g.reserved, key)
+	return n
+}
+
+// count returns how many members of the gang key hold a reservation.
+func (g *gangReservations) count(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.reserved[key])
+}
+
+// Reserve records the node pod is assumed on as a reservation of its gang,
+// and as a placement the next cycles score nodeName by until their snapshot
+// shows pod on it.
+func (cs *CustomScheduler) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	cs.recordPlacement(pod, nodeName)
+	if cs.isUngrouped(pod) {
+		return nil
+	}
+	group, _, status := cs.groupAndMembers(state, pod)
+	if !status.IsSuccess() {
+		return status
+	}
+	cs.gangReservations.reserve(group.key(), podKey(pod), nodeName)
+	return nil
+}
+
+// Unreserve runs when pod fails after Reserve, in a later plugin or while
+// binding. A gang must not bind partially, so it releases the reservations
+// of the whole gang and rejects the members waiting in Permit, which then
+// are unreserved and retried with the rest of the gang.
+func (cs *CustomScheduler) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	cs.placements.remove(podKey(pod))
+	if cs.isUngrouped(pod) {
+		return
+	}
+	waited := cs.endPermitWait(podKey(pod), permitRejected)
+	group, members, status := cs.groupAndMembers(state, pod)
+	if !status.IsSuccess() {
+		cs.logger().Error(status.AsError(), "Failed to resolve the pod group of unreserved pod", "pod", klog.KObj(pod))
+		return
+	}
+	if waited {
+		if assigned, err := cs.assignedMembers(members); err == nil {
+			cs.recordGangEvent(pod, group, gangRejectedReason, "Permit",
+				fmt.Sprintf("pod group %s was rejected with %d of the %d members it needs (minAvailable) assigned", group.name, assigned, group.minAvailable))
+		}
+	}
+	key := group.key()
+	released := cs.gangReservations.release(key)
+	cs.podGroupManager.reject(key)
+	cs.gangAdmission.release(key)
+	cs.rejectWaitingMembers(members, fmt.Sprintf("member %s of pod group %s was unreserved", pod.Name, group.name))
+	cs.logger().V(2).Info("Pod was unreserved, releasing the reservations of its pod group", "pod", klog.KObj(pod), "group", group.name, "released", released)
+}
+
+// rejectWaitingMembers rejects the members waiting in Permit.
+func (cs *CustomScheduler) rejectWaitingMembers(members []*v1.Pod, msg string) {
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	cs.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if isMember[podKey(wp.GetPod())] {
+			wp.Reject(cs.Name(), msg)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"sigs.k8s.io/yaml"
+)
+
+// scenario is a declarative scheduling test read from testdata/scenarios.
+type scenario struct {
+	Name  string         `json:"name"`
+	Mode  string         `json:"mode"`
+	Nodes []scenarioNode `json:"nodes"`
+	// ExistingPods are in the informer cache but are not scheduled by the scenario.
+	ExistingPods []scenarioPod `json:"existingPods"`
+	// Incoming pods are added to the cache up front and then scheduled in order.
+	Incoming []scenarioPod `json:"incoming"`
+}
+
+type scenarioNode struct {
+	Name   string `json:"name"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+type scenarioPod struct {
+	Name         string            `json:"name"`
+	Group        string            `json:"group"`
+	MinAvailable *int              `json:"minAvailable,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	// Phase is the pod phase, Pending if unset.
+	Phase  v1.PodPhase     `json:"phase,omitempty"`
+	Expect *scenarioExpect `json:"expect,omitempty"`
+}
+
+type scenarioExpect struct {
+	// Status is the expected PreFilter code, e.g. Success or Unschedulable.
+	Status string `json:"status"`
+	// Node is the expected highest-scoring node when the pod passes PreFilter.
+	Node string `json:"node,omitempty"`
+}
+
+func (p scenarioPod) toPod() *v1.Pod {
+	labels := map[string]string{}
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+	if p.Group != "" {
+		labels[groupNameLabel] = p.Group
+	}
+	if p.MinAvailable != nil {
+		labels[minAvailableLabel] = strconv.Itoa(*p.MinAvailable)
+	}
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Labels: labels}, Status: v1.PodStatus{Phase: p.Phase}}
+}
+
+func (n scenarioNode) toNodeInfo() *framework.NodeInfo {
+	cpu := resource.MustParse(n.CPU)
+	memory := resource.MustParse(n.Memory)
+	return makeNodeInfo(n.Name, cpu.MilliValue(), memory.Value())
+}
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sc scenario
+		if err := yaml.UnmarshalStrict(data, &sc); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		t.Run(sc.Name, func(t *testing.T) {
+			runScenario(t, sc)
+		})
+	}
+}
+
+func runScenario(t *testing.T, sc scenario) {
+	client := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	var nodes []*framework.NodeInfo
+	for _, n := range sc.Nodes {
+		nodes = append(nodes, n.toNodeInfo())
+	}
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"default-scheduler",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: nodes}),
+	)
+	if err != nil {
+		t.Fatalf("fail to create framework: %s", err)
+	}
+	mode := sc.Mode
+	if mode == "" {
+		mode = leastMode
+	}
+	cs := &CustomScheduler{handle: fh, scoreMode: mode}
+
+	store := informerFactory.Core().V1().Pods().Informer().GetStore()
+	for _, p := range append(append([]scenarioPod{}, sc.ExistingPods...), sc.Incoming...) {
+		store.Add(p.toPod())
+	}
+
+	ctx := context.Background()
+	for _, p := range sc.Incoming {
+		if p.Expect == nil {
+			continue
+		}
+		pod := p.toPod()
+		_, status := cs.PreFilter(ctx, framework.NewCycleState(), pod)
+		if got := status.Code().String(); got != p.Expect.Status {
+			t.Errorf("pod %s: expected PreFilter %s, got %s (%v)", p.Name, p.Expect.Status, got, status.Message())
+			continue
+		}
+		if !status.IsSuccess() || p.Expect.Node == "" {
+			continue
+		}
+
+		scores := framework.NodeScoreList{}
+		for _, n := range sc.Nodes {
+			score, status := cs.Score(ctx, framework.NewCycleState(), pod, n.Name)
+			if !status.IsSuccess() {
+				t.Fatalf("pod %s: unexpected Score error: %v", p.Name, status)
+			}
+			scores = append(scores, framework.NodeScore{Name: n.Name, Score: score})
+		}
+		if status := cs.NormalizeScore(ctx, framework.NewCycleState(), pod, scores); !status.IsSuccess() {
+			t.Fatalf("pod %s: unexpected NormalizeScore error: %v", p.Name, status)
+		}
+		best := scores[0]
+		for _, s := range scores[1:] {
+			if s.Score > best.Score {
+				best = s
+			}
+		}
+		if best.Name != p.Expect.Node {
+			t.Errorf("pod %s: expected node %s, got %s (scores %v)", p.Name, p.Expect.Node, best.Name, scores)
+		}
+	}
+}
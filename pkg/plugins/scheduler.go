@@ -2,30 +2,425 @@ package plugins
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"math"
-	"strconv"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	resourcelisters "k8s.io/client-go/listers/resource/v1alpha2"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"k8s.io/utils/clock"
+	"my-scheduler-plugins/pkg/leader"
+	"my-scheduler-plugins/pkg/scorepolicy"
+	"my-scheduler-plugins/pkg/usage"
 )
 
+// CustomSchedulerArgs are the args of the plugin in its pluginConfig entry.
 type CustomSchedulerArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
 	Mode string `json:"mode"`
+	// Resource is what the Least and Most modes compare nodes by: memory,
+	// cpu, ephemeral-storage, pods or any extended resource name. memory by
+	// default.
+	Resource v1.ResourceName `json:"resource,omitempty"`
+	// VolcanoCompatibility reads minMember and queue from the Volcano PodGroup
+	// named by a pod's scheduling.k8s.io/group-name annotation.
+	VolcanoCompatibility bool `json:"volcanoCompatibility"`
+	// CapacityHints reports the capacity a blocked gang is missing as a
+	// FailedScheduling event, so the Cluster Autoscaler can size a scale-up.
+	CapacityHints bool `json:"capacityHints"`
+	// CapacityHintAnnotation additionally writes the missing capacity to the
+	// nthu.scheduler/capacity-shortfall annotation of the pod.
+	CapacityHintAnnotation bool `json:"capacityHintAnnotation"`
+	// CapacityCheck rejects a gang in PreFilter while the members it is
+	// missing to reach minAvailable request more than the free capacity of
+	// the cluster (or of its node pool) in aggregate, rather than placing
+	// some of them only for the gang to time out in Permit.
+	CapacityCheck bool `json:"capacityCheck"`
+	// GroupQuota rejects a gang member in PreFilter if, with it, the members
+	// of its group admitted so far would request more CPU, memory or GPUs
+	// than the quota of the group: the quotaCPU, quotaMemory and quotaGPU
+	// labels, or spec.quota of its PodGroup.
+	GroupQuota bool `json:"groupQuota"`
+	// GangPreemption, when no node fits a gang member, evicts lower-priority
+	// pods to make room for all the members the gang needs to reach
+	// minAvailable, or none if they would not all fit.
+	GangPreemption bool `json:"gangPreemption"`
+	// GroupBinding defers binding the members of a gang until minAvailable
+	// of them reached the Bind extension point, then binds them together.
+	// The profile must run the plugin's Bind before DefaultBinder.
+	GroupBinding bool `json:"groupBinding"`
+	// GroupAdmission lets only one incomplete gang at a time wait in Permit
+	// for the rest of its members, so gangs competing for capacity cannot
+	// deadlock each holding part of it. The others are admitted in order of
+	// their groupPriority label or PodGroup spec.priority, defaulting to the
+	// priority of their pods, and then of arrival.
+	GroupAdmission bool `json:"groupAdmission"`
+	// NodePoolKey is the node label, or taint, whose value names the pool
+	// of a node, nthu.scheduler/node-pool by default. Gangs with a nodePool
+	// label or PodGroup spec.nodePool only run on the nodes of that pool.
+	NodePoolKey string `json:"nodePoolKey,omitempty"`
+	// TimeoutAnnotation writes the time their group timed out to the
+	// nthu.scheduler/pod-group-timed-out annotation of the pending members
+	// of groups that did not reach minAvailable within their schedule
+	// timeout (the scheduleTimeoutSeconds label or PodGroup field).
+	TimeoutAnnotation bool `json:"timeoutAnnotation"`
+	// MetricsProvider configures where usage-based decisions read the
+	// observed resource usage of nodes from.
+	MetricsProvider *usage.Config `json:"metricsProvider,omitempty"`
+	// RealUsage configures how the RealLeast and RealMost modes cache the
+	// usage they read from MetricsProvider, which they require.
+	RealUsage *RealUsageArgs `json:"realUsage,omitempty"`
+	// TrainingOperatorIntegration derives the group and minAvailable of pods
+	// owned by Kubeflow PyTorchJobs, TFJobs and MPIJobs from the job itself.
+	TrainingOperatorIntegration bool `json:"trainingOperatorIntegration"`
+	// DynamicResources filters out nodes on which an allocated ResourceClaim of
+	// the pod is not available and deprioritizes nodes that resource drivers
+	// reported as unsuitable. Requires the resource.k8s.io/v1alpha2 API.
+	DynamicResources bool `json:"dynamicResources"`
+	// NodeFeatures filters or prefers nodes by Node Feature Discovery labels.
+	NodeFeatures []NodeFeatureRule `json:"nodeFeatures,omitempty"`
+	// Rebalance periodically compares running placements against the score
+	// mode and serves rebalancing recommendations for a descheduler.
+	Rebalance *RebalanceArgs `json:"rebalance,omitempty"`
+	// UngroupedPods, if set, scores pods without gang semantics neutrally or
+	// hands them off to the default scheduler.
+	UngroupedPods *UngroupedPodsArgs `json:"ungroupedPods,omitempty"`
+	// RequireGroupLabels rejects pods without gang semantics as
+	// unresolvable. By default they skip the gang checks and are scheduled
+	// like any other pod.
+	RequireGroupLabels bool `json:"requireGroupLabels"`
+	// ProfileMembersOnly counts only the gang members whose
+	// spec.schedulerName is the profile the plugin runs in, so that members
+	// left to another scheduler, such as the default one, do not count
+	// towards minAvailable.
+	ProfileMembersOnly bool `json:"profileMembersOnly"`
+	// ScorePolicy, if set, delegates Score to a local gRPC policy service and
+	// falls back to Mode when the service fails or times out.
+	ScorePolicy *scorepolicy.Config `json:"scorePolicy,omitempty"`
+	// Reservations, if set, keeps pods off nodes held by external (e.g.
+	// Slurm) reservations defined in a ConfigMap.
+	Reservations *ReservationsArgs `json:"reservations,omitempty"`
+	// Karpenter, if set, prefers consolidation-friendly placements on
+	// Karpenter nodes and, with CapacityHints, annotates blocked gangs with
+	// what they need provisioned.
+	Karpenter *KarpenterArgs `json:"karpenter,omitempty"`
+	// SpotInterruption, if set, watches for interruption signals of cloud
+	// termination handlers, scores doomed nodes lowest and keeps new gang
+	// members off them.
+	SpotInterruption *SpotInterruptionArgs `json:"spotInterruption,omitempty"`
+	// StateExporter, if set, publishes group states, queue depths and
+	// reservation utilization as metrics and as JSON for dashboards.
+	StateExporter *StateExporterArgs `json:"stateExporter,omitempty"`
+	// Debug, if set, serves the configuration, the gangs waiting in Permit,
+	// group membership counts and the last scores of each pod as JSON for
+	// troubleshooting, and optionally pprof profiles.
+	Debug *DebugArgs `json:"debug,omitempty"`
+	// LeaderElection, if set, runs the rebalancer and the state exporter in
+	// the replica holding a Lease only, and rebuilds the gang state from the
+	// API server when a replica takes over.
+	LeaderElection *LeaderElectionArgs `json:"leaderElection,omitempty"`
+	// ConfigReload, if set, reloads the score mode and the other scoring and
+	// gang parameters from a ConfigMap whenever it changes, without
+	// restarting the scheduler. Invalid args are logged and ignored.
+	ConfigReload *ConfigReloadArgs `json:"configReload,omitempty"`
+	// QueueOrder is Priority (the default), which orders the scheduling
+	// queue like the default PrioritySort, or EarliestDeadlineFirst, which
+	// orders pods of equal priority by the latest time they can start and
+	// still meet their nthu.scheduler/deadline, given their
+	// nthu.scheduler/expected-runtime, or Group, which orders pods of equal
+	// priority by the creation time and then the name of their group, so
+	// the members of a gang are scheduled back-to-back.
+	QueueOrder string `json:"queueOrder,omitempty"`
+	// FairShare, if set, orders the scheduling queue so that, among pods of
+	// equal priority, namespaces with the smallest dominant resource share
+	// go first.
+	FairShare *FairShareArgs `json:"fairShare,omitempty"`
+	// Aging, if set, boosts the queue priority of pods that have waited
+	// long, so large gangs are not starved by streams of small pods.
+	Aging *AgingArgs `json:"aging,omitempty"`
+	// Forecast, if set, blends into the score the capacity each node is
+	// predicted to have free over the expected runtime of the pod, from the
+	// usage trend the metrics provider reports. Requires MetricsProvider.
+	Forecast *ForecastArgs `json:"forecast,omitempty"`
+	// RackTopology, if set, places gangs within one rack where possible,
+	// falling back to adjacent racks of the same row.
+	RackTopology *RackTopologyArgs `json:"rackTopology,omitempty"`
+	// GroupColocation, if set, prefers nodes, or topology domains such as
+	// zones, that already host members of the gang of a pod, or, with
+	// anti-affinity, those hosting the fewest.
+	GroupColocation *GroupColocationArgs `json:"groupColocation,omitempty"`
+	// ZoneSpread, if set, spreads the members of each gang evenly across
+	// zones, by score or, in Required mode, also by rejecting nodes in zones
+	// that would exceed the maximum skew.
+	ZoneSpread *ZoneSpreadArgs `json:"zoneSpread,omitempty"`
+	// MaxMinAvailable is the largest accepted minAvailable, 10000 by default;
+	// pods of groups asking for more are rejected as unresolvable.
+	MaxMinAvailable int `json:"maxMinAvailable,omitempty"`
+	// MinAvailableConflictPolicy decides the minAvailable of a group whose
+	// members' labels disagree: Max (the default) takes the largest value,
+	// PodGroup takes the minMember of the PodGroup, or else the Volcano
+	// PodGroup, of the same name and keeps the group unschedulable with an
+	// event until one exists, and Reject keeps the group unschedulable with
+	// an event.
+	MinAvailableConflictPolicy string `json:"minAvailableConflictPolicy,omitempty"`
+	// TieScore is the normalized score every node gets when all raw scores
+	// are equal, half of the maximum node score by default so that a tie
+	// neither favours nor penalizes the nodes against other plugins.
+	TieScore *int64 `json:"tieScore,omitempty"`
+	// ScoreRange is the range raw scores are normalized to, 0-100 by
+	// default, so the plugin can weigh in less against other score plugins
+	// without changing its weight. Ties score the middle of it.
+	ScoreRange *ScoreRange `json:"scoreRange,omitempty"`
+	// InvertScores normalizes the lowest raw score to the top of the range
+	// rather than the bottom, so that a mode ranks nodes the other way
+	// round: Most with InvertScores ranks like Least straight from the
+	// allocatable amounts of the nodes.
+	InvertScores bool `json:"invertScores"`
+	// FailurePolicies decide, by integration (metricsProvider, scorePolicy,
+	// kueue, podGroup, volcano or trainingOperator), what happens while it is
+	// unavailable: Ignore (the default) carries on without it, and Fail keeps
+	// the pods depending on it unschedulable. Least/Most scoring and labelled
+	// gangs never depend on an integration. Integrations backed by custom
+	// resources are unavailable only if their CRD is not installed: while
+	// their informers sync, e.g. after a restart, their pods are
+	// unschedulable under either policy.
+	FailurePolicies map[string]string `json:"failurePolicies,omitempty"`
+	// StartWindowTimeZone is the IANA time zone of the start windows in
+	// nthu.scheduler/start-window annotations, UTC by default.
+	StartWindowTimeZone string `json:"startWindowTimeZone,omitempty"`
+	// ScoreFreeResources makes the score modes compare the allocatable
+	// resources of nodes less what running pods request, so that Least packs
+	// and Most spreads by the capacity actually left. Pods reserved a node
+	// count against it until the snapshot shows them there. kube-scheduler's
+	// snapshot already holds the pods it assumed, so this only guards
+	// against a snapshot lagging behind Reserve; without ScoreFreeResources,
+	// allocatable resources do not change as pods land and nothing is
+	// counted.
+	ScoreFreeResources bool `json:"scoreFreeResources"`
+	// ScoreCache caches the resources the score modes compare of each node
+	// until the node or its pods change, rather than computing them for
+	// every pod and node.
+	ScoreCache bool `json:"scoreCache"`
+	// ScoreResources, if set, score nodes by the weighted sum of one score
+	// per resource in the Weighted mode, which it implies if Mode is unset.
+	ScoreResources []ResourceStrategy `json:"scoreResources,omitempty"`
+	// BalancedResources weigh the resources the Balanced mode balances,
+	// cpu and memory with equal weights by default.
+	BalancedResources []ResourceWeight `json:"balancedResources,omitempty"`
+	// ExtendedResources, such as nvidia.com/gpu, add up to their weight to
+	// the score of nodes for pods requesting them: spreading them in the
+	// Most and MostCPU modes and packing them in the others.
+	ExtendedResources []ResourceWeight `json:"extendedResources,omitempty"`
+	// ImageLocalityWeight, from 0 to 100, is the most that having the
+	// container images of a pod already adds to the score of a node, so
+	// large images need not be pulled again. 0, the default, disables it.
+	ImageLocalityWeight int64 `json:"imageLocalityWeight,omitempty"`
+	// NodeTierWeights multiply the normalized score of the nodes matching
+	// each label selector by its weight, e.g. 0.5 for
+	// "node.kubernetes.io/lifecycle=spot", so operators can prefer some
+	// nodes over others whatever the mode. A node matching several
+	// selectors is multiplied by each of their weights.
+	NodeTierWeights map[string]float64 `json:"nodeTierWeights,omitempty"`
+	// FreeResourceThresholds filter out nodes with less of a resource free,
+	// allocatable less what running pods request, than an absolute quantity
+	// or a percentage of the allocatable amount.
+	FreeResourceThresholds []FreeResourceThreshold `json:"freeResourceThresholds,omitempty"`
+	// PermitTimeoutSeconds is how long the members of a gang wait in Permit
+	// for the rest of it, 60 by default; then the waiting members are all
+	// rejected. At most 900, the framework's limit.
+	PermitTimeoutSeconds int64 `json:"permitTimeoutSeconds,omitempty"`
+	// PodGroupCRD reads the gang parameters of pods labelled
+	// nthu.scheduler/pod-group from the PodGroup custom resource it names.
+	PodGroupCRD bool `json:"podGroupCRD"`
+	// NodeResourceTopology reads the NUMA zones of nodes from their
+	// NodeResourceTopology custom resources for the NUMA mode. It is implied
+	// by that mode, and needed if pods select it by annotation.
+	NodeResourceTopology bool `json:"nodeResourceTopology"`
+	// KueueIntegration holds pods labelled with a Kueue queue in PreEnqueue
+	// until their Kueue Workload is admitted.
+	KueueIntegration bool `json:"kueueIntegration"`
 }
 
 type CustomScheduler struct {
-	handle    framework.Handle
+	handle framework.Handle
+	// scoreMode and the fields that say so below configure plugins created
+	// without New, as in tests; read the configuration with config().
 	scoreMode string
+	// pods and nodes override the handle's informer and snapshot listers,
+	// e.g. to inject mocks and faults in tests.
+	pods  PodLister
+	nodes NodeInfoLister
+	// clock is the source of time for every time-based behavior (timeouts,
+	// backoff, windows, TTLs), so tests can drive it with a fake clock.
+	clock clock.Clock
+	// log tags every line with the profile the plugin runs in, since one
+	// deployment may run the plugin in several profiles with different args.
+	log klog.Logger
+	// capacityHints, capacityHintAnnotation, capacityCheck, groupQuota,
+	// gangPreemption and timeoutAnnotation mirror CustomSchedulerArgs.
+	capacityHints          bool
+	capacityHintAnnotation bool
+	capacityCheck          bool
+	groupQuota             bool
+	gangPreemption         bool
+	timeoutAnnotation      bool
+	// requireGroupLabels and profileMembersOnly mirror CustomSchedulerArgs.
+	requireGroupLabels bool
+	profileMembersOnly bool
+	// resource, scoreFreeResources, scoreResources and balancedResources
+	// mirror CustomSchedulerArgs.
+	resource           v1.ResourceName
+	scoreFreeResources bool
+	scoreResources     []ResourceStrategy
+	balancedResources  []ResourceWeight
+	// placements are the pods reserved a node that the snapshot may not
+	// show on it yet, which scoring free resources subtracts.
+	placements placements
+	// scoreCache is set when CustomSchedulerArgs.ScoreCache is.
+	scoreCache *scoreCache
+	// metrics is set when a metrics provider is configured.
+	metrics usage.MetricsProvider
+	// realUsage caches the usage of nodes for the RealLeast and RealMost
+	// modes, and is set when a metrics provider is configured.
+	realUsage *realUsage
+	// resourceClaims and podSchedulingContexts are set when DRA awareness is enabled.
+	resourceClaims        resourcelisters.ResourceClaimLister
+	podSchedulingContexts resourcelisters.PodSchedulingContextLister
+	// nodeFeatures are the validated CustomSchedulerArgs.NodeFeatures.
+	nodeFeatures []nodeFeatureRule
+	// dynamicInformers watches custom resources of optional integrations.
+	dynamicInformers dynamicinformer.DynamicSharedInformerFactory
+	// workloads is set when the Kueue integration is enabled.
+	workloads WorkloadLister
+	// podGroups is set when PodGroup custom resources are enabled.
+	podGroups PodGroupGetter
+	// volcanoPodGroups is set when the Volcano compatibility mode is enabled.
+	volcanoPodGroups PodGroupGetter
+	// nodeTopologies is set when NodeResourceTopology custom resources are read.
+	nodeTopologies PodGroupGetter
+	// trainingJobs is set, by kind, when the training-operator integration is enabled.
+	trainingJobs map[string]PodGroupGetter
+	// ungrouped is set when an ungrouped pods policy is configured.
+	ungrouped *ungroupedPolicy
+	// scorePolicy is set when Score is delegated to a policy service.
+	scorePolicy ScorePolicyScorer
+	// reservations is set when external reservations are imported.
+	reservations *reservations
+	// karpenter is set when Karpenter awareness is enabled.
+	karpenter *KarpenterArgs
+	// spot is set when spot interruption awareness is enabled.
+	spot *spotWatcher
+	// fairShare is set when fair sharing across namespaces is enabled.
+	fairShare *fairShare
+	// aging is set when waiting pods are boosted in the queue.
+	aging *AgingArgs
+	// forecast is set when forecast-based scoring is enabled.
+	forecast *forecaster
+	// rackTopology is set when rack-aware gang placement is enabled.
+	rackTopology *RackTopologyArgs
+	// groupColocation is set when group co-location scoring is enabled.
+	groupColocation *GroupColocationArgs
+	// zoneSpread is set when zone spreading of gangs is enabled.
+	zoneSpread *ZoneSpreadArgs
+	// debug is set when the debug endpoint is enabled.
+	debug *debugServer
+	// maxMinAvailable, minAvailableConflictPolicy, tieScore, queueOrder,
+	// scoreRange, invertScores, extendedResources, imageLocalityWeight,
+	// nodeTiers and freeResourceThresholds configure plugins created without
+	// New, like scoreMode.
+	maxMinAvailable            int
+	minAvailableConflictPolicy string
+	tieScore                   *int64
+	queueOrder                 string
+	scoreRange                 *ScoreRange
+	invertScores               bool
+	extendedResources          []ResourceWeight
+	imageLocalityWeight        int64
+	nodeTiers                  []nodeTier
+	freeResourceThresholds     []FreeResourceThreshold
+	// cfg is the current configuration once published, which the
+	// ConfigReload ConfigMap may replace, and cfgMu serializes updates to it.
+	cfg   atomic.Pointer[schedulerConfig]
+	cfgMu sync.Mutex
+	// failurePolicies are the validated CustomSchedulerArgs.FailurePolicies.
+	failurePolicies map[string]string
+	// startWindowLocation is the time zone of start windows, UTC if nil.
+	startWindowLocation *time.Location
+	// integrationsSynced are the informers of each optional integration,
+	// integrationResources the resources they watch, and discovery and crds
+	// tell whether those are installed while the informers have not synced.
+	integrationsSynced   map[string][]cache.InformerSynced
+	integrationResources map[string][]schema.GroupVersionResource
+	discovery            discovery.DiscoveryInterface
+	crds                 crdCache
+	// permitTimeoutSeconds mirrors CustomSchedulerArgs, podGroupManager
+	// tracks the gangs going through Permit, permitWaits when their members
+	// started to wait and gangReservations the nodes reserved for their
+	// members.
+	permitTimeoutSeconds int64
+	podGroupManager      PodGroupManager
+	permitWaits          permitWaits
+	gangReservations     gangReservations
+	// groupBinding mirrors CustomSchedulerArgs, and gangBindings are the
+	// members of each gang waiting in Bind.
+	groupBinding bool
+	gangBindings gangBindings
+	// groupAdmission mirrors CustomSchedulerArgs, and gangAdmission is the
+	// gate incomplete gangs take turns through.
+	groupAdmission bool
+	gangAdmission  gangAdmission
+	// nodePoolKey mirrors CustomSchedulerArgs.
+	nodePoolKey string
+	// informersSynced are the informers PreFilter waits for, and synced
+	// caches that they all have.
+	informersSynced []cache.InformerSynced
+	synced          atomic.Bool
+	// podIndexer indexes the pod cache by the labels naming gangs, nil for
+	// plugins not created by New.
+	podIndexer cache.Indexer
 }
 
+var _ framework.QueueSortPlugin = &CustomScheduler{}
+var _ framework.PreEnqueuePlugin = &CustomScheduler{}
+var _ framework.EnqueueExtensions = &CustomScheduler{}
 var _ framework.PreFilterPlugin = &CustomScheduler{}
+var _ framework.PreFilterExtensions = &CustomScheduler{}
+var _ framework.FilterPlugin = &CustomScheduler{}
+var _ framework.PostFilterPlugin = &CustomScheduler{}
+var _ framework.PreScorePlugin = &CustomScheduler{}
+var _ framework.ReservePlugin = &CustomScheduler{}
+var _ framework.PermitPlugin = &CustomScheduler{}
+var _ framework.BindPlugin = &CustomScheduler{}
 var _ framework.ScorePlugin = &CustomScheduler{}
 
+// PodLister lists the pods matching a label selector.
+type PodLister interface {
+	List(selector labels.Selector) ([]*v1.Pod, error)
+}
+
+// NodeInfoLister returns the scheduling snapshot of nodes.
+type NodeInfoLister interface {
+	Get(nodeName string) (*framework.NodeInfo, error)
+	List() ([]*framework.NodeInfo, error)
+}
+
 // Name is the name of the plugin used in Registry and configurations.
 const (
 	Name              string = "CustomScheduler"
@@ -33,112 +428,860 @@ const (
 	minAvailableLabel string = "minAvailable"
 	leastMode         string = "Least"
 	mostMode          string = "Most"
+	// leastCPUMode and mostCPUMode score nodes by allocatable CPU rather
+	// than memory.
+	leastCPUMode string = "LeastCPU"
+	mostCPUMode  string = "MostCPU"
+	// consolidationMode packs pods onto the busiest nodes so that idle nodes
+	// can be scaled down.
+	consolidationMode string = "Consolidation"
 )
 
 func (cs *CustomScheduler) Name() string {
 	return Name
 }
 
-// New initializes and returns a new CustomScheduler plugin.
+// New initializes and returns a new CustomScheduler plugin, whose background
+// loops, leader election and endpoints run for the life of the process.
 func New(obj runtime.Object, h framework.Handle) (framework.Plugin, error) {
-	cs := CustomScheduler{}
-	mode := leastMode
-	if obj != nil {
-		args := obj.(*runtime.Unknown)
-		var csArgs CustomSchedulerArgs
-		if err := json.Unmarshal(args.Raw, &csArgs); err != nil {
-			fmt.Printf("Error unmarshal: %v\n", err)
+	return newCustomScheduler(context.Background(), obj, h)
+}
+
+// NewWithContext returns a factory of CustomScheduler plugins whose
+// background loops, leader election and endpoints stop once ctx is done,
+// e.g. when the scheduler shuts down.
+func NewWithContext(ctx context.Context) frameworkruntime.PluginFactory {
+	return func(obj runtime.Object, h framework.Handle) (framework.Plugin, error) {
+		return newCustomScheduler(ctx, obj, h)
+	}
+}
+
+func newCustomScheduler(parent context.Context, obj runtime.Object, h framework.Handle) (framework.Plugin, error) {
+	cs := CustomScheduler{handle: h}
+	csArgs, err := LoadArgs(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateCustomSchedulerArgs(nil, csArgs); err != nil {
+		return nil, fmt.Errorf("invalid %s args: %w", Name, err)
+	}
+	cfg, err := newSchedulerConfig(csArgs)
+	if err != nil {
+		return nil, err
+	}
+	mode := cfg.scoreMode
+	cs.cfg.Store(cfg)
+	cs.clock = clock.RealClock{}
+	cs.log = klog.LoggerWithValues(klog.Background(), "profile", cs.profileName())
+	// background loops log through the logger of their context.
+	ctx := klog.NewContext(parent, cs.log)
+	cs.capacityHints = csArgs.CapacityHints
+	cs.capacityCheck = csArgs.CapacityCheck
+	cs.groupQuota = csArgs.GroupQuota
+	cs.gangPreemption = csArgs.GangPreemption
+	cs.groupBinding = csArgs.GroupBinding
+	cs.groupAdmission = csArgs.GroupAdmission
+	cs.nodePoolKey = csArgs.NodePoolKey
+	cs.timeoutAnnotation = csArgs.TimeoutAnnotation
+	cs.requireGroupLabels = csArgs.RequireGroupLabels
+	cs.profileMembersOnly = csArgs.ProfileMembersOnly
+	cs.capacityHintAnnotation = csArgs.CapacityHintAnnotation
+	cs.resource = csArgs.Resource
+	cs.scoreFreeResources = csArgs.ScoreFreeResources
+	cs.scoreResources = csArgs.ScoreResources
+	cs.balancedResources = csArgs.BalancedResources
+	cs.permitTimeoutSeconds = csArgs.PermitTimeoutSeconds
+	registerMetrics()
+	cs.recordScoreMode(mode)
+	nodeFeatures, err := newNodeFeatureRules(csArgs.NodeFeatures)
+	if err != nil {
+		return nil, err
+	}
+	cs.nodeFeatures = nodeFeatures
+	failurePolicies, err := newFailurePolicies(csArgs.FailurePolicies)
+	if err != nil {
+		return nil, err
+	}
+	cs.failurePolicies = failurePolicies
+	if csArgs.StartWindowTimeZone != "" {
+		location, err := time.LoadLocation(csArgs.StartWindowTimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startWindowTimeZone: %w", err)
+		}
+		cs.startWindowLocation = location
+	}
+	if csArgs.UngroupedPods != nil {
+		ungrouped, err := newUngroupedPolicy(*csArgs.UngroupedPods)
+		if err != nil {
+			return nil, err
+		}
+		cs.ungrouped = ungrouped
+	}
+	if csArgs.Karpenter != nil {
+		karpenter, err := newKarpenterArgs(*csArgs.Karpenter)
+		if err != nil {
+			return nil, err
+		}
+		cs.karpenter = karpenter
+	}
+	if csArgs.Aging != nil {
+		aging, err := newAgingArgs(*csArgs.Aging)
+		if err != nil {
+			return nil, err
+		}
+		cs.aging = aging
+	}
+	if csArgs.RackTopology != nil {
+		rackTopology, err := newRackTopologyArgs(*csArgs.RackTopology)
+		if err != nil {
+			return nil, err
 		}
-		mode = csArgs.Mode
-		if mode != leastMode && mode != mostMode {
-			return nil, fmt.Errorf("invalid mode, got %s", mode)
+		cs.rackTopology = rackTopology
+	}
+	if csArgs.GroupColocation != nil {
+		groupColocation, err := newGroupColocationArgs(*csArgs.GroupColocation)
+		if err != nil {
+			return nil, err
+		}
+		cs.groupColocation = groupColocation
+	}
+	if csArgs.ZoneSpread != nil {
+		zoneSpread, err := newZoneSpreadArgs(*csArgs.ZoneSpread)
+		if err != nil {
+			return nil, err
+		}
+		cs.zoneSpread = zoneSpread
+	}
+	if csArgs.SpotInterruption != nil {
+		cs.spot = newSpotWatcher(*csArgs.SpotInterruption)
+		cs.spot.logger = cs.log
+		if _, err := h.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(cs.spot.handler()); err != nil {
+			return nil, fmt.Errorf("failed to watch nodes for spot interruptions: %w", err)
+		}
+	}
+	if csArgs.ScoreCache {
+		cs.scoreCache = newScoreCache()
+		if _, err := h.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(cs.scoreCache.handler()); err != nil {
+			return nil, fmt.Errorf("failed to watch nodes for the score cache: %w", err)
+		}
+	}
+	if csArgs.MetricsProvider != nil {
+		metrics, err := usage.New(*csArgs.MetricsProvider, h.ClientSet().Discovery().RESTClient())
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the metrics provider: %w", err)
+		}
+		cs.metrics = metrics
+		var args RealUsageArgs
+		if csArgs.RealUsage != nil {
+			args = *csArgs.RealUsage
+		}
+		realUsage, err := newRealUsage(args, cs.metrics, cs.clock)
+		if err != nil {
+			return nil, err
+		}
+		cs.realUsage = realUsage
+	}
+	if csArgs.ScorePolicy != nil {
+		scorePolicy, err := scorepolicy.New(*csArgs.ScorePolicy)
+		if err != nil {
+			return nil, err
+		}
+		cs.scorePolicy = scorePolicy
+	}
+	if csArgs.Reservations != nil {
+		factory := informers.NewSharedInformerFactoryWithOptions(h.ClientSet(), 0, informers.WithNamespace(csArgs.Reservations.Namespace))
+		cs.reservations = &reservations{
+			configMaps:         factory.Core().V1().ConfigMaps().Lister().ConfigMaps(csArgs.Reservations.Namespace),
+			name:               csArgs.Reservations.Name,
+			maxBackfillSeconds: csArgs.Reservations.MaxBackfillSeconds,
+		}
+		cs.addInformerSynced(factory.Core().V1().ConfigMaps().Informer().HasSynced)
+		factory.Start(ctx.Done())
+	}
+	if csArgs.DynamicResources {
+		resources := h.SharedInformerFactory().Resource().V1alpha2()
+		cs.resourceClaims = resources.ResourceClaims().Lister()
+		cs.podSchedulingContexts = resources.PodSchedulingContexts().Lister()
+	}
+	if h.ClientSet() != nil {
+		cs.discovery = h.ClientSet().Discovery()
+	}
+	if csArgs.KueueIntegration {
+		workloads, err := cs.newUnstructuredLister(workloadGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the Kueue integration: %w", err)
+		}
+		cs.workloads = workloads
+		cs.addIntegrationSynced(integrationKueue, workloadGVR, workloads.synced)
+	}
+	if csArgs.PodGroupCRD {
+		podGroups, err := cs.newUnstructuredLister(PodGroupGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up PodGroup custom resources: %w", err)
+		}
+		cs.podGroups = podGroups
+		cs.addIntegrationSynced(integrationPodGroup, PodGroupGVR, podGroups.synced)
+	}
+	if csArgs.VolcanoCompatibility {
+		podGroups, err := cs.newUnstructuredLister(volcanoPodGroupGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the Volcano compatibility mode: %w", err)
+		}
+		cs.volcanoPodGroups = podGroups
+		cs.addIntegrationSynced(integrationVolcano, volcanoPodGroupGVR, podGroups.synced)
+	}
+	if csArgs.NodeResourceTopology || mode == numaMode {
+		topologies, err := cs.newUnstructuredLister(nodeResourceTopologyGVR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up NodeResourceTopology custom resources: %w", err)
+		}
+		cs.nodeTopologies = topologies
+		cs.addIntegrationSynced(integrationNodeResourceTopology, nodeResourceTopologyGVR, topologies.synced)
+	}
+	if csArgs.TrainingOperatorIntegration {
+		cs.trainingJobs = map[string]PodGroupGetter{}
+		for kind, job := range trainingJobs {
+			jobs, err := cs.newUnstructuredLister(job.gvr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up the training-operator integration: %w", err)
+			}
+			cs.trainingJobs[kind] = jobs
+			cs.addIntegrationSynced(integrationTrainingOperator, job.gvr, jobs.synced)
 		}
 	}
-	cs.handle = h
-	cs.scoreMode = mode
-	log.Printf("Custom scheduler runs with the mode: %s.", mode)
+	// leading are the loops publishing gang state, which run in a single
+	// replica with leader election.
+	var leading []func(context.Context)
+	if csArgs.Rebalance != nil {
+		r := newRebalancer(*csArgs.Rebalance, func() string { return cs.config().scoreMode }, cs.clock, cs.podLister(), h.SharedInformerFactory().Core().V1().Nodes().Lister())
+		address := csArgs.Rebalance.Address
+		if address == "" {
+			address = defaultRebalanceAddress
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/recommendations", r)
+		if err := cs.serve(ctx, "rebalancing recommendations", address, mux); err != nil {
+			return nil, err
+		}
+		leading = append(leading, r.run)
+	}
+	if csArgs.FairShare != nil {
+		cs.fairShare = newFairShare(*csArgs.FairShare, cs.clock, cs.podLister(), h.SharedInformerFactory().Core().V1().Nodes().Lister())
+		go cs.fairShare.run(ctx)
+	}
+	if csArgs.Forecast != nil {
+		forecast, err := newForecaster(*csArgs.Forecast, cs.metrics, cs.clock, h.SharedInformerFactory().Core().V1().Nodes().Lister())
+		if err != nil {
+			return nil, err
+		}
+		cs.forecast = forecast
+		go cs.forecast.run(ctx)
+	}
+	if csArgs.StateExporter != nil {
+		e := newStateExporter(*csArgs.StateExporter, &cs, h.SharedInformerFactory().Core().V1().Nodes().Lister())
+		address := csArgs.StateExporter.Address
+		if address == "" {
+			address = defaultStateExporterAddress
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/state", e)
+		if err := cs.serve(ctx, "the scheduler state", address, mux); err != nil {
+			return nil, err
+		}
+		leading = append(leading, e.run)
+	}
+	if csArgs.Debug != nil {
+		cs.debug = newDebugServer(*csArgs.Debug, &cs)
+		address := csArgs.Debug.Address
+		if address == "" {
+			address = defaultDebugAddress
+		}
+		if err := cs.serve(ctx, "the debug endpoint", address, cs.debug.Handler()); err != nil {
+			return nil, err
+		}
+	}
+	if csArgs.LeaderElection != nil {
+		config, err := newLeaderConfig(*csArgs.LeaderElection, cs.profileName())
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			if err := leader.Run(ctx, h.ClientSet(), config, func(ctx context.Context) { cs.takeOver(ctx, leading) }); err != nil {
+				cs.logger().Error(err, "Failed to run leader election")
+			}
+		}()
+	} else {
+		for _, run := range leading {
+			go run(ctx)
+		}
+	}
+	if csArgs.ConfigReload != nil {
+		factory := informers.NewSharedInformerFactoryWithOptions(h.ClientSet(), 0, informers.WithNamespace(csArgs.ConfigReload.Namespace))
+		reloader := &configReloader{cs: &cs, name: csArgs.ConfigReload.Name, args: *csArgs}
+		registration, err := factory.Core().V1().ConfigMaps().Informer().AddEventHandler(reloader.handler())
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch the configuration ConfigMap: %w", err)
+		}
+		cs.addInformerSynced(registration.HasSynced)
+		factory.Start(ctx.Done())
+	}
+	if cs.dynamicInformers != nil {
+		cs.dynamicInformers.Start(ctx.Done())
+	}
+	podInformer := h.SharedInformerFactory().Core().V1().Pods().Informer()
+	if indexer, err := indexPodsByGroup(podInformer); err != nil {
+		// PreFilter still finds the members by listing their namespace.
+		cs.logger().Error(err, "Failed to index pods, listing them instead")
+	} else {
+		cs.podIndexer = indexer
+	}
+	cs.addInformerSynced(podInformer.HasSynced)
+	go cs.logCacheSync(ctx)
+	cs.logger().Info("Custom scheduler started", "mode", mode)
 
 	return &cs, nil
 }
 
+// podLister returns the lister used to look up the members of a pod group.
+func (cs *CustomScheduler) podLister() PodLister {
+	if cs.pods != nil {
+		return cs.pods
+	}
+	return cs.handle.SharedInformerFactory().Core().V1().Pods().Lister()
+}
+
+// listNamespacedPods lists the pods in namespace matching selector, through
+// the namespace index of the informer cache where the lister has one.
+func (cs *CustomScheduler) listNamespacedPods(namespace string, selector labels.Selector) ([]*v1.Pod, error) {
+	if lister, ok := cs.podLister().(listersv1.PodLister); ok {
+		return lister.Pods(namespace).List(selector)
+	}
+	return cs.podLister().List(selector)
+}
+
+// profileName returns the name of the scheduler profile the plugin runs in,
+// since one deployment may run the plugin in several profiles.
+func (cs *CustomScheduler) profileName() string {
+	if profile, ok := cs.handle.(interface{ ProfileName() string }); ok {
+		return profile.ProfileName()
+	}
+	return ""
+}
+
+// logger returns the logger of the plugin, or the global one for plugins
+// not created by New.
+func (cs *CustomScheduler) logger() klog.Logger {
+	if cs.log.GetSink() == nil {
+		return klog.Background()
+	}
+	return cs.log
+}
+
+// nodeInfos returns the lister used to look up nodes while scoring.
+func (cs *CustomScheduler) nodeInfos() NodeInfoLister {
+	if cs.nodes != nil {
+		return cs.nodes
+	}
+	return cs.handle.SnapshotSharedLister().NodeInfos()
+}
+
+// NewStandalone returns a CustomScheduler that runs outside of the scheduling
+// framework, e.g. behind the scheduler extender API, reading group members and
+// nodes from the given listers. Only the Least and Most modes are supported:
+// the others need args or integrations only New sets up.
+func NewStandalone(mode string, pods PodLister, nodes NodeInfoLister) (*CustomScheduler, error) {
+	if mode != leastMode && mode != mostMode {
+		return nil, fmt.Errorf("invalid mode, want %s or %s, got %s", leastMode, mostMode, mode)
+	}
+	return &CustomScheduler{scoreMode: mode, pods: pods, nodes: nodes, clock: clock.RealClock{}}, nil
+}
+
+func validMode(mode string) bool {
+	return mode == leastMode || mode == mostMode || mode == leastCPUMode || mode == mostCPUMode || mode == consolidationMode || mode == balancedMode || mode == numaMode || mode == weightedMode || mode == realLeastMode || mode == realMostMode
+}
+
+// PreEnqueue hands off pods without gang semantics, holds gangs outside of
+// their start window, holds Kueue-managed pods until their Workload is
+// admitted, and holds gangs until minAvailable members exist.
+func (cs *CustomScheduler) PreEnqueue(ctx context.Context, pod *v1.Pod) *framework.Status {
+	if status := cs.handoffUngrouped(pod); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.startWindowGate(pod); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.kueueGate(pod); !status.IsSuccess() {
+		return status
+	}
+	return cs.gangGate(pod)
+}
+
 // filter the pod if the pod in group is less than minAvailable
 func (cs *CustomScheduler) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
-	log.Printf("Pod %s is in Prefilter phase.", pod.Name)
+	cs.logger().V(4).Info("PreFilter", "pod", klog.KObj(pod))
+	if status := cycleAborted(ctx); status != nil {
+		return nil, status
+	}
 	newStatus := framework.NewStatus(framework.Success, "")
+	// the window may have closed since the pod passed PreEnqueue.
+	if status := cs.startWindowGate(pod); !status.IsSuccess() {
+		return nil, status
+	}
+	// pods without gang semantics are not gang scheduled, unless the profile
+	// is for gangs only.
+	if cs.isUngrouped(pod) {
+		if cs.requireGroupLabels {
+			msg := fmt.Sprintf("pod has no %s label, which this scheduler profile requires", groupNameLabel)
+			cs.recordEvent(pod, v1.EventTypeWarning, invalidPodGroupReason, "Scheduling", msg)
+			return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, msg)
+		}
+		return nil, newStatus
+	}
 
 	// TODO
+	// a cold cache right after a restart would miscount the group.
+	if !cs.cachesSynced() {
+		return nil, framework.NewStatus(framework.Unschedulable, "waiting for informer caches to sync")
+	}
 	// 1. extract the label of the pod
-	podGroup := pod.GetLabels()[groupNameLabel]
-	minAvailable, err := strconv.Atoi(pod.GetLabels()[minAvailableLabel])
-	if err != nil {
-		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("invalid minAvailable value: %v", err))
+	group, status := cs.groupOf(pod)
+	if !status.IsSuccess() {
+		if status.Code() == framework.UnschedulableAndUnresolvable {
+			// only the user can fix an invalid group, so tell them why it is stuck.
+			cs.recordEvent(pod, v1.EventTypeWarning, invalidPodGroupReason, "Scheduling", status.Message())
+		}
+		return nil, status
 	}
 	// 2. retrieve the pod with the same group label
-	sameLabelPods, err := cs.handle.SharedInformerFactory().Core().V1().Pods().Lister().List(labels.SelectorFromSet(labels.Set{groupNameLabel: podGroup}))
+	sameLabelPods, err := cs.groupMembers(group)
 	if err != nil {
-		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("failed to list pods: %v", err))
+		// a lister failure says nothing about the group itself, so let the pod
+		// go through backoff and retry instead of failing the cycle with an Error.
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list pods: %v", err))
 	}
+	// counting a large group takes a while; do not act on a stale count.
+	if status := cycleAborted(ctx); status != nil {
+		return nil, status
+	}
+	if status := cs.reconcileMinAvailable(group, sameLabelPods); !status.IsSuccess() {
+		cs.recordEvent(pod, v1.EventTypeWarning, invalidPodGroupReason, "Scheduling", status.Message())
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	writeGroupSnapshot(state, group, sameLabelPods)
 	// 3. justify if the pod can be scheduled
-	if len(sameLabelPods) < minAvailable {
-		return nil, framework.NewStatus(framework.Unschedulable, "not enough pods in the group")
+	if timedOut, ok := cs.groupTimedOut(group, sameLabelPods); ok {
+		status := cs.rejectTimedOutGroup(ctx, pod, group, sameLabelPods, timedOut)
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	if len(sameLabelPods) < group.minAvailable {
+		msg := insufficientMembers(group, len(sameLabelPods))
+		cs.recordGangEvent(pod, group, insufficientMembersReason, "PreFilter", msg)
+		status := framework.NewStatus(framework.Unschedulable, msg)
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	if status := cs.checkNodePool(pod, group, sameLabelPods); !status.IsSuccess() {
+		cs.recordGangEvent(pod, group, nodePoolFullReason, "PreFilter", status.Message())
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	if status := cs.checkGroupQuota(pod, group, sameLabelPods); !status.IsSuccess() {
+		cs.recordGangEvent(pod, group, groupQuotaExceededReason, "PreFilter", status.Message())
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	if status := cs.checkGangCapacity(pod, group, sameLabelPods); !status.IsSuccess() {
+		cs.recordGangEvent(pod, group, insufficientCapacityReason, "PreFilter", status.Message())
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	if err := cs.placeGang(state, sameLabelPods); err != nil {
+		status := framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list node infos: %v", err))
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
+	}
+	if err := cs.countZones(state, pod, sameLabelPods); err != nil {
+		status := framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to list node infos: %v", err))
+		cs.recordPreFilterRejection(pod, group, status)
+		return nil, status
 	}
 
 	return nil, newStatus
 }
 
-// PreFilterExtensions returns a PreFilterExtensions interface if the plugin implements one.
-func (cs *CustomScheduler) PreFilterExtensions() framework.PreFilterExtensions {
-	return nil
+// Filter rejects nodes with less of a resource free than its threshold,
+// nodes whose preemption would break the preemption budget of a pod group,
+// nodes outside the node pool of a gang, nodes outside the rack or row a
+// gang must stay in, nodes in zones that would host too many members of a
+// gang, nodes held by a reservation the pod is not part of, nodes about to
+// be interrupted, nodes that lack a required hardware feature, and nodes on
+// which an allocated resource claim of the pod is not available.
+func (cs *CustomScheduler) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if status := cs.filterFreeResources(nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterPreemptionBudgets(state); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterNodePool(state, nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterRackTopology(state, pod, nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterZoneSpread(state, nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterReservations(pod, nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterInterruptedNodes(pod, nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	if status := cs.filterNodeFeatures(pod, nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	return cs.filterResourceClaims(pod, nodeInfo)
 }
 
 // Score invoked at the score extension point.
 func (cs *CustomScheduler) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
-	log.Printf("Pod %s is in Score phase. Calculate the score of Node %s.", pod.Name, nodeName)
+	score, status := cs.score(ctx, state, pod, nodeName)
+	if status.IsSuccess() {
+		cs.logger().V(4).Info("Scored node", "pod", klog.KObj(pod), "node", nodeName, "mode", cs.scoreModeOf(pod), "score", score)
+	}
+	return score, status
+}
+
+// score returns the raw score of nodeName for pod.
+func (cs *CustomScheduler) score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	if status := cycleAborted(ctx); status != nil {
+		return 0, status
+	}
 
 	// TODO
-	// 1. retrieve the node allocatable memory
-	nodeInfo, err := cs.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	// 1. retrieve the node allocatable resource and CPU
+	nodeInfo, err := cs.nodeInfos().Get(nodeName)
 	if err != nil {
-		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("failed to get node info: %v", err))
+		// the node was most likely deleted after Filter; score it rather than
+		// abort the cycle for every other node, NormalizeScore ranks it lowest.
+		cs.logger().V(4).Info("Node is missing from the snapshot, scoring it lowest", "pod", klog.KObj(pod), "node", nodeName, "err", err)
+		missingNodesOf(state).add(nodeName)
+		return 0, framework.NewStatus(framework.Success)
 	}
-	allocatableMemory := nodeInfo.Allocatable.Memory
-	// 2. return the score based on the scheduler mode
-	if cs.scoreMode == leastMode {
-		return -allocatableMemory, framework.NewStatus(framework.Success)
+	allocatableAmount, allocatableMilliCPU := cs.adjustedResources(cs.pendingPlacements(state), nodeInfo)
+	if score, ok := delegatedScore(state, nodeName); ok {
+		return score, framework.NewStatus(framework.Success)
 	}
+	if cs.scheduleUngrouped(pod) {
+		return 0, framework.NewStatus(framework.Success)
+	}
+	// 2. return the score based on the scheduler mode. Raw scores are never
+	// negative: the least modes count down from the largest allocatable
+	// amount of any node.
+	switch mode := cs.scoreModeOf(pod); mode {
+	case leastMode, leastCPUMode:
+		largest, err := cs.largestAllocatable(state)
+		if err != nil {
+			return 0, framework.NewStatus(framework.Error, fmt.Sprintf("failed to list node infos: %v", err))
+		}
+		if mode == leastCPUMode {
+			return nonNegative(largest.milliCPU - allocatableMilliCPU), framework.NewStatus(framework.Success)
+		}
+		return nonNegative(largest.amount - allocatableAmount), framework.NewStatus(framework.Success)
+	case mostCPUMode:
+		return allocatableMilliCPU, framework.NewStatus(framework.Success)
+	case consolidationMode:
+		return consolidationScore(nodeInfo), framework.NewStatus(framework.Success)
+	case balancedMode:
+		return cs.balancedScore(pod, nodeInfo), framework.NewStatus(framework.Success)
+	case numaMode:
+		return cs.numaScore(pod, nodeInfo), framework.NewStatus(framework.Success)
+	case weightedMode:
+		largest, err := cs.largestAmounts(state)
+		if err != nil {
+			return 0, framework.NewStatus(framework.Error, fmt.Sprintf("failed to list node infos: %v", err))
+		}
+		return cs.weightedScore(largest, nodeInfo), framework.NewStatus(framework.Success)
+	case realLeastMode, realMostMode:
+		free, err := cs.realFree(ctx, state)
+		if err != nil {
+			return 0, framework.NewStatus(framework.Error, fmt.Sprintf("failed to list node infos: %v", err))
+		}
+		if mode == realLeastMode {
+			return nonNegative(free.most - free.free[nodeName]), framework.NewStatus(framework.Success)
+		}
+		return free.free[nodeName], framework.NewStatus(framework.Success)
+	}
+
+	return allocatableAmount, framework.NewStatus(framework.Success)
+}
+
+const largestAllocatableStateKey = framework.StateKey(Name + "/largest-allocatable")
+
+// largestAllocatable is the largest allocatable (or, scoring free resources,
+// free) amount of the scored resource and CPU of any node, computed once per
+// cycle.
+type largestAllocatable struct {
+	amount, milliCPU int64
+}
+
+func (l largestAllocatable) Clone() framework.StateData {
+	return l
+}
+
+// largestAllocatable returns the largest allocatable resource and CPU of any
+// node, from state if PreScore stored them.
+func (cs *CustomScheduler) largestAllocatable(state *framework.CycleState) (largestAllocatable, error) {
+	if state != nil {
+		if data, err := state.Read(largestAllocatableStateKey); err == nil {
+			return data.(largestAllocatable), nil
+		}
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return largestAllocatable{}, err
+	}
+	pending := cs.pendingPlacements(state)
+	var largest largestAllocatable
+	for _, ni := range nodeInfos {
+		amount, milliCPU := cs.adjustedResources(pending, ni)
+		if amount > largest.amount {
+			largest.amount = amount
+		}
+		if milliCPU > largest.milliCPU {
+			largest.milliCPU = milliCPU
+		}
+	}
+	return largest, nil
+}
 
-	return allocatableMemory, framework.NewStatus(framework.Success)
+// scoredResources returns the amount of CustomSchedulerArgs.Resource and the
+// CPU of nodeInfo the score modes compare: what is allocatable, or what of
+// it is not requested yet when scoring free resources. They come from the
+// score cache if enabled.
+func (cs *CustomScheduler) scoredResources(nodeInfo *framework.NodeInfo) (amount, milliCPU int64) {
+	if cs.scoreCache != nil && nodeInfo.Node() != nil {
+		return cs.scoreCache.get(nodeInfo, cs.computeScoredResources)
+	}
+	return cs.computeScoredResources(nodeInfo)
+}
+
+func (cs *CustomScheduler) computeScoredResources(nodeInfo *framework.NodeInfo) (amount, milliCPU int64) {
+	resource := cs.resource
+	if resource == "" {
+		resource = v1.ResourceMemory
+	}
+	return cs.scoredAmount(nodeInfo, resource), cs.scoredAmount(nodeInfo, v1.ResourceCPU)
 }
 
 // ensure the scores are within the valid range
 func (cs *CustomScheduler) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	if status := cycleAborted(ctx); status != nil {
+		return status
+	}
 	// TODO
 	// find the range of the current score and map to the valid range
-	var minScore, maxScore int64 = math.MaxInt64, math.MinInt64
+	c := cs.config()
+	missing := missingNodesOf(state)
+	missing.rankLowest(scores, c.invertScores)
+	cs.normalize(scores)
+
+	// avoid nodes trending toward saturation over the pod's runtime.
+	if cs.forecast != nil {
+		for i := range scores {
+			scores[i].Score = cs.forecast.blend(pod, scores[i].Name, scores[i].Score)
+		}
+	}
 
-	for _, score := range scores {
-		if score.Score < minScore {
-			minScore = score.Score
+	// pack or spread the extended resources, such as GPUs, the pod requests.
+	if len(c.extendedResources) > 0 {
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(scores[i].Score + cs.extendedResourceBonus(pod, nodeInfo))
 		}
-		if score.Score > maxScore {
-			maxScore = score.Score
+	}
+
+	// prefer nodes that already have the images of the pod.
+	if c.imageLocalityWeight > 0 {
+		if nodeInfos, err := cs.nodeInfos().List(); err == nil {
+			for i := range scores {
+				nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+				if err != nil {
+					continue
+				}
+				scores[i].Score = cs.clampToRange(scores[i].Score + cs.imageLocalityBonus(pod, nodeInfo, len(nodeInfos)))
+			}
 		}
 	}
 
-	scoreRange := maxScore - minScore
-	if scoreRange > 0 {
+	// prefer nodes with the hardware features the pod asks for.
+	if len(cs.nodeFeatures) > 0 {
 		for i := range scores {
-			scores[i].Score = ((scores[i].Score - minScore) * 100) / scoreRange
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(scores[i].Score + cs.nodeFeatureBonus(pod, nodeInfo.Node()))
 		}
-	} else {
+	}
+
+	// keep gangs together in one rack, or at least one row.
+	if cs.rackTopology != nil {
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(scores[i].Score + cs.rackTopologyBonus(state, nodeInfo.Node()))
+		}
+	}
+
+	// spread the members of a gang evenly across zones.
+	if cs.zoneSpread != nil {
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(scores[i].Score + cs.zoneSpreadBonus(state, nodeInfo.Node()))
+		}
+	}
+
+	// co-locate the members of a gang, or spread them with anti-affinity.
+	if cs.groupColocation != nil && !cs.isUngrouped(pod) {
+		domains, err := cs.groupDomains(state, pod)
+		if err != nil {
+			cs.logger().V(4).Info("Skipping group colocation", "pod", klog.KObj(pod), "err", err)
+		}
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil || domains == nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(scores[i].Score + cs.groupColocationBonus(domains, nodeInfo.Node()))
+		}
+	}
+
+	// prefer packing busy Karpenter nodes so empty ones can be consolidated.
+	if cs.karpenter != nil {
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(scores[i].Score + cs.karpenterBonus(nodeInfo))
+		}
+	}
+
+	// weigh the score by the tiers of the node.
+	if len(c.nodeTiers) > 0 {
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err != nil {
+				continue
+			}
+			scores[i].Score = cs.clampToRange(cs.tierScore(scores[i].Score, nodeInfo.Node()))
+		}
+	}
+
+	// let nodes about to be drained empty out.
+	if cs.scoreModeOf(pod) == consolidationMode {
 		for i := range scores {
-			scores[i].Score = 0
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err == nil && isCordonCandidate(nodeInfo.Node()) {
+				scores[i].Score = framework.MinNodeScore
+			}
 		}
 	}
 
+	// avoid nodes about to be interrupted.
+	if cs.spot != nil {
+		for i := range scores {
+			nodeInfo, err := cs.nodeInfos().Get(scores[i].Name)
+			if err == nil && cs.spot.isDoomed(nodeInfo.Node()) {
+				scores[i].Score = framework.MinNodeScore
+			}
+		}
+	}
+
+	// prefer nodes on which the pod's resource claims can be allocated.
+	unsuitable := cs.draUnsuitableNodes(pod)
+	for i := range scores {
+		if unsuitable[scores[i].Name] {
+			scores[i].Score = framework.MinNodeScore
+		}
+	}
+
+	// never prefer nodes deleted after Filter.
+	for i := range scores {
+		if missing.has(scores[i].Name) {
+			scores[i].Score = framework.MinNodeScore
+		}
+	}
+
+	for i := range scores {
+		scores[i].Score = clampScore(scores[i].Score)
+	}
+	cs.recordNodeScores(scores)
+	if cs.debug != nil {
+		cs.debug.recordScores(pod, scores)
+	}
 	return framework.NewStatus(framework.Success)
 }
 
+// clampToRange bounds score to the normalized range, so bonuses never lift
+// a node out of the configured scoreRange.
+func (cs *CustomScheduler) clampToRange(score int64) int64 {
+	lo, hi := cs.normalizedRange()
+	if score < lo {
+		return lo
+	}
+	if score > hi {
+		return hi
+	}
+	return score
+}
+
+// clampScore bounds score to the range the framework accepts.
+func clampScore(score int64) int64 {
+	if score < framework.MinNodeScore {
+		return framework.MinNodeScore
+	}
+	if score > framework.MaxNodeScore {
+		return framework.MaxNodeScore
+	}
+	return score
+}
+
+// serve listens on address and serves handler, what the log lines call
+// what, until ctx is done. Listening fails, and so does New, if address is
+// taken, e.g. by the same endpoint of another profile, rather than leaving
+// the endpoint unserved.
+func (cs *CustomScheduler) serve(ctx context.Context, what, address string, handler http.Handler) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to serve %s, give every profile its own address: %w", what, err)
+	}
+	server := &http.Server{Handler: handler}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		cs.logger().Info("Serving "+what, "address", listener.Addr().String())
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cs.logger().Error(err, "Failed to serve "+what)
+		}
+	}()
+	return nil
+}
+
 // ScoreExtensions of the Score plugin.
 func (cs *CustomScheduler) ScoreExtensions() framework.ScoreExtensions {
 	return cs
@@ -13,6 +13,7 @@ import (
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/wait"
 	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
@@ -347,4 +348,30 @@ func (f *fakeSharedLister) StorageInfos() framework.StorageInfoLister {
 
 func (f *fakeSharedLister) NodeInfos() framework.NodeInfoLister {
 	return fakeframework.NodeInfoLister(f.nodes)
-}
\ No newline at end of file
+}
+
+// podListerFunc adapts a function to the PodLister interface.
+type podListerFunc func(selector labels.Selector) ([]*v1.Pod, error)
+
+func (f podListerFunc) List(selector labels.Selector) ([]*v1.Pod, error) {
+	return f(selector)
+}
+
+func TestCustomScheduler_MockedListers(t *testing.T) {
+	pods := podListerFunc(func(selector labels.Selector) ([]*v1.Pod, error) {
+		return makeGroupPods("g1", 2, 2), nil
+	})
+	nodes := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100)}
+	cs := &CustomScheduler{scoreMode: mostMode, pods: pods, nodes: nodes}
+	pod := makeGroupPods("g1", 2, 1)[0]
+
+	if _, status := cs.PreFilter(context.Background(), nil, pod); !status.IsSuccess() {
+		t.Errorf("unexpected PreFilter status: %v", status)
+	}
+	if score, status := cs.Score(context.Background(), nil, pod, "m1"); !status.IsSuccess() || score != 100 {
+		t.Errorf("expected score 100, got %d (%v)", score, status)
+	}
+	if score, status := cs.Score(context.Background(), nil, pod, "missing"); !status.IsSuccess() || score != 0 {
+		t.Errorf("expected the lowest score for a missing node, got %d (%v)", score, status)
+	}
+}
@@ -0,0 +1,353 @@
+package plugins
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func TestCustomScheduler_ScoreRange(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100), makeNodeInfo("m2", 1000, 200), makeNodeInfo("m3", 1000, 400)}
+	nodes := []*v1.Node{nodeInfos[0].Node(), nodeInfos[1].Node(), nodeInfos[2].Node()}
+	for _, mode := range []string{leastMode, mostMode} {
+		t.Run(mode, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: mode, nodes: nodeInfos}
+			state := framework.NewCycleState()
+			if status := cs.PreScore(context.Background(), state, &v1.Pod{}, nodes); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			scores := framework.NodeScoreList{}
+			for _, n := range nodes {
+				score, status := cs.Score(context.Background(), state, &v1.Pod{}, n.Name)
+				if !status.IsSuccess() {
+					t.Fatal(status)
+				}
+				if score < 0 {
+					t.Errorf("node %s: negative raw score %d", n.Name, score)
+				}
+				scores = append(scores, framework.NodeScore{Name: n.Name, Score: score})
+			}
+			if status := cs.NormalizeScore(context.Background(), state, &v1.Pod{}, scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			best := framework.MaxNodeScore
+			if mode == mostMode {
+				best = framework.MinNodeScore
+			}
+			if scores[0].Score != best {
+				t.Errorf("expected m1 to score %d, got %v", best, scores)
+			}
+			for _, s := range scores {
+				if s.Score < framework.MinNodeScore || s.Score > framework.MaxNodeScore {
+					t.Errorf("node %s: score %d out of range", s.Name, s.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_ScoreCPUModes(t *testing.T) {
+	// cpu-rich has the most CPU but the least memory.
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("cpu-rich", 4000, 100), makeNodeInfo("balanced", 2000, 200), makeNodeInfo("memory-rich", 1000, 400)}
+	nodes := []*v1.Node{nodeInfos[0].Node(), nodeInfos[1].Node(), nodeInfos[2].Node()}
+	for mode, want := range map[string]string{leastCPUMode: "memory-rich", mostCPUMode: "cpu-rich", leastMode: "cpu-rich", mostMode: "memory-rich"} {
+		t.Run(mode, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: mode, nodes: nodeInfos}
+			state := framework.NewCycleState()
+			if status := cs.PreScore(context.Background(), state, &v1.Pod{}, nodes); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			scores := framework.NodeScoreList{}
+			for _, n := range nodes {
+				score, status := cs.Score(context.Background(), state, &v1.Pod{}, n.Name)
+				if !status.IsSuccess() {
+					t.Fatal(status)
+				}
+				scores = append(scores, framework.NodeScore{Name: n.Name, Score: score})
+			}
+			if status := cs.NormalizeScore(context.Background(), state, &v1.Pod{}, scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for _, s := range scores {
+				if s.Name == want && s.Score != framework.MaxNodeScore {
+					t.Errorf("expected %s to score highest, got %v", want, scores)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_ScoreFreeResources(t *testing.T) {
+	// large has the most allocatable memory but the least of it free.
+	large := makeNodeInfo("large", 1000, 400)
+	large.AddPod(makeRequestingPod("default", "running", "large", v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(350, resource.BinarySI)}))
+	small := makeNodeInfo("small", 1000, 200)
+	nodeInfos := fakeframework.NodeInfoLister{large, small}
+	for _, tt := range []struct {
+		free bool
+		mode string
+		want string
+	}{
+		{free: false, mode: mostMode, want: "large"},
+		{free: true, mode: mostMode, want: "small"},
+		{free: true, mode: leastMode, want: "large"},
+	} {
+		cs := &CustomScheduler{scoreMode: tt.mode, scoreFreeResources: tt.free, nodes: nodeInfos}
+		scores := map[string]int64{}
+		for _, name := range []string{"large", "small"} {
+			score, status := cs.Score(context.Background(), framework.NewCycleState(), &v1.Pod{}, name)
+			if !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			scores[name] = score
+		}
+		for name, score := range scores {
+			if name != tt.want && score >= scores[tt.want] {
+				t.Errorf("%s with free resources %v: expected %s to score highest, got %v", tt.mode, tt.free, tt.want, scores)
+			}
+		}
+	}
+}
+
+func TestCustomScheduler_ScoreBalanced(t *testing.T) {
+	// the pod takes half of the CPU and half of the memory of even, but
+	// all of the CPU and a quarter of the memory of skewed.
+	even := makeNodeInfo("even", 2000, 400)
+	skewed := makeNodeInfo("skewed", 1000, 800)
+	nodeInfos := fakeframework.NodeInfoLister{even, skewed}
+	pod := makeRequestingPod("default", "pod", "", v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(1000, resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(200, resource.BinarySI),
+	})
+	for _, tt := range []struct {
+		name    string
+		weights []ResourceWeight
+		want    int64
+	}{
+		{name: "default weights", want: 625},
+		{name: "memory only", weights: []ResourceWeight{{Name: v1.ResourceMemory, Weight: 1}}, want: 1000},
+		{name: "cpu outweighs memory", weights: []ResourceWeight{{Name: v1.ResourceCPU, Weight: 3}, {Name: v1.ResourceMemory, Weight: 1}}, want: 675},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: balancedMode, balancedResources: tt.weights, nodes: nodeInfos}
+			score, status := cs.Score(context.Background(), framework.NewCycleState(), pod, "even")
+			if !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			if score != 1000 {
+				t.Errorf("expected even to score 1000, got %d", score)
+			}
+			score, status = cs.Score(context.Background(), framework.NewCycleState(), pod, "skewed")
+			if !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			if score != tt.want {
+				t.Errorf("expected skewed to score %d, got %d", tt.want, score)
+			}
+		})
+	}
+}
+
+func TestValidateResourceWeights(t *testing.T) {
+	for _, weights := range [][]ResourceWeight{
+		{{Name: "", Weight: 1}},
+		{{Name: v1.ResourceCPU, Weight: 0}},
+		{{Name: v1.ResourceCPU, Weight: 1}, {Name: v1.ResourceCPU, Weight: 2}},
+	} {
+		if err := validateResourceWeights(weights); err == nil {
+			t.Errorf("expected %v to be invalid", weights)
+		}
+	}
+	if err := validateResourceWeights(defaultBalancedResources); err != nil {
+		t.Errorf("expected the default weights to be valid, got %v", err)
+	}
+}
+
+func TestCustomScheduler_ScoreModeAnnotation(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("small", 1000, 100), makeNodeInfo("large", 1000, 400)}
+	for _, tt := range []struct {
+		annotation string
+		want       string
+	}{
+		{annotation: "", want: "small"},
+		{annotation: mostMode, want: "large"},
+		{annotation: "Spread", want: "small"},
+	} {
+		pod := &v1.Pod{}
+		if tt.annotation != "" {
+			pod.Annotations = map[string]string{scoreModeAnnotation: tt.annotation}
+		}
+		cs := &CustomScheduler{scoreMode: leastMode, nodes: nodeInfos}
+		state := framework.NewCycleState()
+		if status := cs.PreScore(context.Background(), state, pod, nil); !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		scores := framework.NodeScoreList{}
+		for _, name := range []string{"small", "large"} {
+			score, status := cs.Score(context.Background(), state, pod, name)
+			if !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			scores = append(scores, framework.NodeScore{Name: name, Score: score})
+		}
+		if status := cs.NormalizeScore(context.Background(), state, pod, scores); !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		for _, s := range scores {
+			if s.Name == tt.want && s.Score != framework.MaxNodeScore {
+				t.Errorf("annotation %q: expected %s to score highest, got %v", tt.annotation, tt.want, scores)
+			}
+		}
+	}
+}
+
+func TestClampScore(t *testing.T) {
+	for in, want := range map[int64]int64{-5: framework.MinNodeScore, 42: 42, 250: framework.MaxNodeScore} {
+		if got := clampScore(in); got != want {
+			t.Errorf("clampScore(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestCustomScheduler_ClampToRange(t *testing.T) {
+	cs := &CustomScheduler{scoreRange: &ScoreRange{Min: 10, Max: 50}}
+	for in, want := range map[int64]int64{5: 10, 42: 42, 80: 50} {
+		if got := cs.clampToRange(in); got != want {
+			t.Errorf("clampToRange(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestCustomScheduler_NormalizeScoreMissingNode(t *testing.T) {
+	for _, invert := range []bool{false, true} {
+		cs := &CustomScheduler{
+			scoreMode: mostMode, invertScores: invert, scoreRange: &ScoreRange{Min: 10, Max: 50},
+			nodes: fakeframework.NodeInfoLister{makeNodeInfo("n1", 4000, 800), makeNodeInfo("n2", 4000, 600)},
+		}
+		state := framework.NewCycleState()
+		pod := &v1.Pod{}
+		if status := cs.PreScore(context.Background(), state, pod, nil); !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		var scores framework.NodeScoreList
+		for _, name := range []string{"n1", "n2", "deleted"} {
+			score, status := cs.Score(context.Background(), state, pod, name)
+			if !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			scores = append(scores, framework.NodeScore{Name: name, Score: score})
+		}
+		if status := cs.NormalizeScore(context.Background(), state, pod, scores); !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		// the missing node neither stretches the range nor, inverted, ends up on top.
+		want := map[string]int64{"n1": 50, "n2": 10, "deleted": framework.MinNodeScore}
+		if invert {
+			want = map[string]int64{"n1": 10, "n2": 50, "deleted": framework.MinNodeScore}
+		}
+		for _, s := range scores {
+			if s.Score != want[s.Name] {
+				t.Errorf("invert %v: expected %s to score %d, got %d", invert, s.Name, want[s.Name], s.Score)
+			}
+		}
+	}
+}
+
+func TestCustomScheduler_NormalizeScoreTie(t *testing.T) {
+	zero := int64(0)
+	for _, tt := range []struct {
+		name string
+		cs   *CustomScheduler
+		want int64
+	}{
+		{name: "default", cs: &CustomScheduler{scoreMode: leastMode}, want: framework.MaxNodeScore / 2},
+		{name: "configured", cs: &CustomScheduler{scoreMode: leastMode, tieScore: &zero}, want: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := framework.NodeScoreList{{Name: "m1", Score: 7}, {Name: "m2", Score: 7}}
+			if status := tt.cs.NormalizeScore(context.Background(), nil, &v1.Pod{}, scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for _, s := range scores {
+				if s.Score != tt.want {
+					t.Errorf("node %s: expected %d, got %d", s.Name, tt.want, s.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_UpdateConfig(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100), makeNodeInfo("m2", 1000, 200)}
+	cs := &CustomScheduler{scoreMode: mostMode, nodes: nodeInfos}
+	before := cs.config()
+	cs.updateConfig(func(c *schedulerConfig) { c.scoreMode = leastMode })
+	if before.scoreMode != mostMode {
+		t.Errorf("expected the published configuration to stay unchanged, got %s", before.scoreMode)
+	}
+	if got, _ := cs.Score(context.Background(), nil, &v1.Pod{}, "m1"); got != 100 {
+		t.Errorf("expected the least mode score 100 after the update, got %d", got)
+	}
+}
+
+func TestCustomScheduler_ScoreHugeNodes(t *testing.T) {
+	const tib = int64(1) << 40
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("small", 1000, 64<<30), makeNodeInfo("large", 1000, 12*tib), makeNodeInfo("huge", 1000, 16*tib)}
+	nodes := []*v1.Node{nodeInfos[0].Node(), nodeInfos[1].Node(), nodeInfos[2].Node()}
+	for mode, want := range map[string]map[string]int64{
+		leastMode: {"small": framework.MaxNodeScore, "large": 25, "huge": framework.MinNodeScore},
+		mostMode:  {"small": framework.MinNodeScore, "large": 74, "huge": framework.MaxNodeScore},
+	} {
+		t.Run(mode, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: mode, nodes: nodeInfos}
+			scores := framework.NodeScoreList{}
+			for _, n := range nodes {
+				score, status := cs.Score(context.Background(), nil, &v1.Pod{}, n.Name)
+				if !status.IsSuccess() {
+					t.Fatal(status)
+				}
+				scores = append(scores, framework.NodeScore{Name: n.Name, Score: score})
+			}
+			if status := cs.NormalizeScore(context.Background(), nil, &v1.Pod{}, scores); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for _, s := range scores {
+				if s.Score != want[s.Name] {
+					t.Errorf("expected %v, got %v", want, scores)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_NormalizeExtremeScores(t *testing.T) {
+	scores := framework.NodeScoreList{{Name: "lowest", Score: math.MinInt64}, {Name: "middle", Score: 0}, {Name: "highest", Score: math.MaxInt64}}
+	(&CustomScheduler{}).normalize(scores)
+	want := []int64{framework.MinNodeScore, (framework.MaxNodeScore - framework.MinNodeScore) / 2, framework.MaxNodeScore}
+	for i, s := range scores {
+		if s.Score != want[i] {
+			t.Errorf("expected %v, got %v", want, scores)
+			break
+		}
+	}
+}
+
+func TestCustomScheduler_ScoreWeightedHugeAmounts(t *testing.T) {
+	const eib = int64(1) << 60
+	half, full := makeNodeInfo("half", 1000, 1000), makeNodeInfo("full", 1000, 1000)
+	half.Allocatable.EphemeralStorage, full.Allocatable.EphemeralStorage = eib, 2*eib
+	cs := &CustomScheduler{scoreResources: []ResourceStrategy{{Name: v1.ResourceEphemeralStorage, Weight: 1, Strategy: mostMode}}}
+	largest := largestAmounts{v1.ResourceEphemeralStorage: 2 * eib}
+	if got := cs.weightedScore(largest, half); got != 500 {
+		t.Errorf("expected half the largest amount to score 500, got %d", got)
+	}
+	if got := cs.weightedScore(largest, full); got != 1000 {
+		t.Errorf("expected the largest amount to score 1000, got %d", got)
+	}
+}
@@ -0,0 +1,66 @@
+package plugins
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// scoredNode is the scored resource and CPU the score modes compare of a node, as of
+// a resource version of the node and a generation of its NodeInfo, which
+// changes whenever pods are added to or removed from it.
+type scoredNode struct {
+	resourceVersion  string
+	generation       int64
+	amount, milliCPU int64
+}
+
+// scoreCache keeps the scored resources of each node, so that they are
+// computed once per node and cycle rather than once per pod and node on
+// large clusters, until the node or its pods change.
+type scoreCache struct {
+	mu    sync.RWMutex
+	nodes map[string]scoredNode
+}
+
+func newScoreCache() *scoreCache {
+	return &scoreCache{nodes: map[string]scoredNode{}}
+}
+
+// get returns the cached scored resources of nodeInfo, computing them with
+// compute if nodeInfo changed since they were.
+func (c *scoreCache) get(nodeInfo *framework.NodeInfo, compute func(*framework.NodeInfo) (int64, int64)) (amount, milliCPU int64) {
+	node := nodeInfo.Node()
+	c.mu.RLock()
+	cached, ok := c.nodes[node.Name]
+	c.mu.RUnlock()
+	if ok && cached.resourceVersion == node.ResourceVersion && cached.generation == nodeInfo.Generation {
+		return cached.amount, cached.milliCPU
+	}
+	amount, milliCPU = compute(nodeInfo)
+	c.mu.Lock()
+	c.nodes[node.Name] = scoredNode{resourceVersion: node.ResourceVersion, generation: nodeInfo.Generation, amount: amount, milliCPU: milliCPU}
+	c.mu.Unlock()
+	return amount, milliCPU
+}
+
+func (c *scoreCache) invalidate(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if node, ok := obj.(*v1.Node); ok {
+		c.mu.Lock()
+		delete(c.nodes, node.Name)
+		c.mu.Unlock()
+	}
+}
+
+// handler drops the cached resources of nodes that are updated or deleted.
+func (c *scoreCache) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { c.invalidate(obj) },
+		DeleteFunc: c.invalidate,
+	}
+}
@@ -0,0 +1,62 @@
+package plugins
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestScoreCache(t *testing.T) {
+	c := newScoreCache()
+	computed := 0
+	compute := func(ni *framework.NodeInfo) (int64, int64) {
+		computed++
+		return ni.Allocatable.Memory - ni.Requested.Memory, ni.Allocatable.MilliCPU - ni.Requested.MilliCPU
+	}
+	ni := makeNodeInfo("n1", 1000, 400)
+	ni.Node().ResourceVersion = "1"
+
+	for i := 0; i < 3; i++ {
+		if memory, milliCPU := c.get(ni, compute); memory != 400 || milliCPU != 1000 {
+			t.Fatalf("expected 400 memory and 1000m CPU, got %d and %dm", memory, milliCPU)
+		}
+	}
+	if computed != 1 {
+		t.Errorf("expected the resources to be computed once, got %d times", computed)
+	}
+
+	// a pod added to the node bumps the generation of its NodeInfo.
+	ni.AddPod(makeRequestingPod("default", "p", "n1", v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(100, resource.BinarySI)}))
+	if memory, _ := c.get(ni, compute); memory != 300 || computed != 2 {
+		t.Errorf("expected the resources recomputed to 300 memory, got %d after %d computations", memory, computed)
+	}
+
+	ni.Node().ResourceVersion = "2"
+	c.get(ni, compute)
+	if computed != 3 {
+		t.Errorf("expected a new resource version to recompute the resources, got %d computations", computed)
+	}
+
+	c.handler().OnDelete(ni.Node())
+	c.get(ni, compute)
+	if computed != 4 {
+		t.Errorf("expected a deleted node to be dropped from the cache, got %d computations", computed)
+	}
+}
+
+func TestCustomScheduler_ScoreCacheMatches(t *testing.T) {
+	ni := makeNodeInfo("n1", 2000, 800)
+	ni.AddPod(makeRequestingPod("default", "p", "n1", v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(300, resource.BinarySI)}))
+	for _, free := range []bool{false, true} {
+		uncached := &CustomScheduler{scoreFreeResources: free}
+		cached := &CustomScheduler{scoreFreeResources: free, scoreCache: newScoreCache()}
+		wantMemory, wantMilliCPU := uncached.scoredResources(ni)
+		for i := 0; i < 2; i++ {
+			if memory, milliCPU := cached.scoredResources(ni); memory != wantMemory || milliCPU != wantMilliCPU {
+				t.Errorf("scoreFreeResources %v: expected %d and %dm from the cache, got %d and %dm", free, wantMemory, wantMilliCPU, memory, milliCPU)
+			}
+		}
+	}
+}
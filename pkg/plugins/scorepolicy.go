@@ -0,0 +1,111 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/scorepolicy"
+)
+
+const scorePolicyStateKey = framework.StateKey(Name + "/score-policy")
+
+// ScorePolicyScorer scores the feasible nodes of a pod in one call.
+type ScorePolicyScorer interface {
+	Score(ctx context.Context, req scorepolicy.Request) (map[string]int64, error)
+}
+
+// scorePolicyState holds the delegated scores of one scheduling cycle.
+type scorePolicyState struct {
+	scores map[string]int64
+}
+
+func (s *scorePolicyState) Clone() framework.StateData {
+	return s
+}
+
+// PreScore computes the inputs of the built-in score mode of the pod, which
+// its nthu.scheduler/score-mode annotation may override, once per cycle and
+// asks the score policy service, if configured, to score every feasible node
+// in a single batched call. Policy failures fail open, falling back to the
+// built-in score mode, unless the scorePolicy failure policy is Fail.
+func (cs *CustomScheduler) PreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node) *framework.Status {
+	cs.checkScoreModeAnnotation(pod)
+	scoreMode := cs.scoreModeOf(pod)
+	state.Write(missingNodesStateKey, &missingNodes{})
+	if cs.scoreFreeResources {
+		state.Write(pendingPlacementsStateKey, cs.pendingPlacements(nil))
+	}
+	if scoreMode == leastMode || scoreMode == leastCPUMode {
+		if largest, err := cs.largestAllocatable(state); err == nil {
+			state.Write(largestAllocatableStateKey, largest)
+		}
+	}
+	if scoreMode == weightedMode {
+		if largest, err := cs.largestAmounts(nil); err == nil {
+			state.Write(largestAmountsStateKey, largest)
+		}
+	}
+	if scoreMode == realLeastMode || scoreMode == realMostMode {
+		if free, err := cs.realFree(ctx, nil); err == nil {
+			state.Write(realFreeStateKey, free)
+		}
+	}
+	if cs.scorePolicy == nil {
+		return nil
+	}
+	if status := cycleAborted(ctx); status != nil {
+		return status
+	}
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	req := scorepolicy.Request{
+		Mode: scoreMode,
+		Pod: scorepolicy.Pod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Labels:    pod.Labels,
+			MilliCPU:  requests.Cpu().MilliValue(),
+			Memory:    requests.Memory().Value(),
+		},
+	}
+	for _, node := range nodes {
+		n := scorepolicy.Node{Name: node.Name, Labels: node.Labels}
+		if nodeInfo, err := cs.nodeInfos().Get(node.Name); err == nil {
+			n.AllocatableMilliCPU, n.AllocatableMemory = nodeInfo.Allocatable.MilliCPU, nodeInfo.Allocatable.Memory
+			n.RequestedMilliCPU, n.RequestedMemory = nodeInfo.Requested.MilliCPU, nodeInfo.Requested.Memory
+		}
+		req.Nodes = append(req.Nodes, n)
+	}
+
+	scores, err := cs.scorePolicy.Score(ctx, req)
+	if status := cycleAborted(ctx); status != nil {
+		// the policy call failed because the cycle was aborted, not because
+		// the policy is down, so there is nothing to fall back to.
+		return status
+	}
+	if err != nil {
+		if cs.failClosed(integrationScorePolicy) {
+			return framework.AsStatus(fmt.Errorf("score policy failed: %w", err))
+		}
+		cs.logger().Error(err, "Score policy failed, falling back to the score mode", "pod", klog.KObj(pod), "mode", scoreMode)
+		return nil
+	}
+	state.Write(scorePolicyStateKey, &scorePolicyState{scores: scores})
+	return nil
+}
+
+// delegatedScore returns the score policy's score of nodeName in this cycle.
+func delegatedScore(state *framework.CycleState, nodeName string) (int64, bool) {
+	if state == nil {
+		return 0, false
+	}
+	data, err := state.Read(scorePolicyStateKey)
+	if err != nil {
+		return 0, false
+	}
+	score, ok := data.(*scorePolicyState).scores[nodeName]
+	return score, ok
+}
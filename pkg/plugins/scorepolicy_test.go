@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"my-scheduler-plugins/pkg/scorepolicy"
+)
+
+type scorePolicyFunc func(ctx context.Context, req scorepolicy.Request) (map[string]int64, error)
+
+func (f scorePolicyFunc) Score(ctx context.Context, req scorepolicy.Request) (map[string]int64, error) {
+	return f(ctx, req)
+}
+
+func TestCustomScheduler_ScorePolicy(t *testing.T) {
+	nodeInfos := fakeframework.NodeInfoLister{makeNodeInfo("m1", 1000, 100), makeNodeInfo("m2", 1000, 200)}
+	nodes := []*v1.Node{nodeInfos[0].Node(), nodeInfos[1].Node()}
+	tests := []struct {
+		name   string
+		policy scorePolicyFunc
+		want   map[string]int64
+	}{
+		{
+			name: "delegated",
+			policy: func(ctx context.Context, req scorepolicy.Request) (map[string]int64, error) {
+				if len(req.Nodes) != 2 || req.Nodes[1].AllocatableMemory != 200 {
+					t.Errorf("unexpected request %+v", req)
+				}
+				return map[string]int64{"m1": 7, "m2": 3}, nil
+			},
+			want: map[string]int64{"m1": 7, "m2": 3},
+		},
+		{
+			name: "fails open",
+			policy: func(ctx context.Context, req scorepolicy.Request) (map[string]int64, error) {
+				return nil, errors.New("deadline exceeded")
+			},
+			want: map[string]int64{"m1": 100, "m2": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, nodes: nodeInfos, scorePolicy: tt.policy}
+			state := framework.NewCycleState()
+			pod := &v1.Pod{}
+			if status := cs.PreScore(context.Background(), state, pod, nodes); !status.IsSuccess() {
+				t.Fatalf("unexpected status %v", status)
+			}
+			for name, want := range tt.want {
+				if got, _ := cs.Score(context.Background(), state, pod, name); got != want {
+					t.Errorf("node %s: expected %d, got %d", name, want, got)
+				}
+			}
+		})
+	}
+}
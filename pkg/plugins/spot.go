@@ -0,0 +1,127 @@
+package plugins
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// defaultInterruptionTaints are set by common cloud termination handlers on
+// nodes about to be reclaimed.
+var defaultInterruptionTaints = []string{
+	"aws-node-termination-handler/spot-itn",
+	"aws-node-termination-handler/rebalance-recommendation",
+	"cloud.google.com/impending-node-termination",
+	"karpenter.sh/disruption",
+}
+
+// SpotInterruptionArgs configures which node signals announce an interruption.
+type SpotInterruptionArgs struct {
+	// Taints are taint keys that mark a doomed node; the common AWS, GKE and
+	// Karpenter keys by default.
+	Taints []string `json:"taints,omitempty"`
+	// Annotations are annotation keys that mark a doomed node.
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// spotWatcher tracks nodes with a pending interruption from node events, so
+// they are avoided as soon as the signal arrives.
+type spotWatcher struct {
+	taints      map[string]bool
+	annotations []string
+	logger      klog.Logger
+
+	mu     sync.RWMutex
+	doomed map[string]bool
+}
+
+func newSpotWatcher(args SpotInterruptionArgs) *spotWatcher {
+	w := &spotWatcher{taints: map[string]bool{}, annotations: args.Annotations, logger: klog.Background(), doomed: map[string]bool{}}
+	taints := args.Taints
+	if len(taints) == 0 && len(args.Annotations) == 0 {
+		taints = defaultInterruptionTaints
+	}
+	for _, key := range taints {
+		w.taints[key] = true
+	}
+	return w
+}
+
+// interrupted reports whether node carries an interruption signal.
+func (w *spotWatcher) interrupted(node *v1.Node) bool {
+	for _, t := range node.Spec.Taints {
+		if w.taints[t.Key] {
+			return true
+		}
+	}
+	for _, key := range w.annotations {
+		if _, ok := node.Annotations[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *spotWatcher) update(node *v1.Node) {
+	interrupted := w.interrupted(node)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if interrupted && !w.doomed[node.Name] {
+		w.logger.Info("Node is about to be interrupted", "node", klog.KObj(node))
+	}
+	if interrupted {
+		w.doomed[node.Name] = true
+	} else {
+		delete(w.doomed, node.Name)
+	}
+}
+
+func (w *spotWatcher) remove(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if node, ok := obj.(*v1.Node); ok {
+		w.mu.Lock()
+		delete(w.doomed, node.Name)
+		w.mu.Unlock()
+	}
+}
+
+// handler keeps the doomed set in sync with node events.
+func (w *spotWatcher) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if node, ok := obj.(*v1.Node); ok {
+				w.update(node)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if node, ok := obj.(*v1.Node); ok {
+				w.update(node)
+			}
+		},
+		DeleteFunc: w.remove,
+	}
+}
+
+// isDoomed reports whether node has a pending interruption, as seen by the
+// watcher or on the node itself.
+func (w *spotWatcher) isDoomed(node *v1.Node) bool {
+	w.mu.RLock()
+	doomed := w.doomed[node.Name]
+	w.mu.RUnlock()
+	return doomed || w.interrupted(node)
+}
+
+// filterInterruptedNodes keeps new gang members off nodes about to be
+// interrupted, since losing one member can stall the whole gang.
+func (cs *CustomScheduler) filterInterruptedNodes(pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if cs.spot == nil || cs.isUngrouped(pod) || !cs.spot.isDoomed(nodeInfo.Node()) {
+		return nil
+	}
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node %s is about to be interrupted", nodeInfo.Node().Name))
+}
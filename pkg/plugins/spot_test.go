@@ -0,0 +1,65 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func TestSpotWatcher(t *testing.T) {
+	w := newSpotWatcher(SpotInterruptionArgs{})
+	node := makeNodeInfo("n1", 1000, 100).Node()
+	handler := w.handler()
+
+	handler.OnAdd(node, false)
+	if w.isDoomed(makeNodeInfo("n1", 1000, 100).Node()) {
+		t.Fatal("node without a signal must not be doomed")
+	}
+	doomed := node.DeepCopy()
+	doomed.Spec.Taints = []v1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: v1.TaintEffectNoSchedule}}
+	handler.OnUpdate(node, doomed)
+	// the watcher must see the signal even if the snapshot still has the old node.
+	if !w.isDoomed(node) {
+		t.Error("expected the node to be doomed")
+	}
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "n1", Obj: doomed})
+	if w.isDoomed(node) {
+		t.Error("expected a deleted node to be forgotten")
+	}
+
+	w = newSpotWatcher(SpotInterruptionArgs{Annotations: []string{"example.com/preempted"}})
+	node.Annotations = map[string]string{"example.com/preempted": "true"}
+	if !w.isDoomed(node) {
+		t.Error("expected an annotated node to be doomed")
+	}
+}
+
+func TestCustomScheduler_SpotInterruption(t *testing.T) {
+	healthy := makeNodeInfo("healthy", 1000, 100)
+	doomed := makeNodeInfo("doomed", 1000, 200)
+	w := newSpotWatcher(SpotInterruptionArgs{})
+	node := doomed.Node().DeepCopy()
+	node.Spec.Taints = []v1.Taint{{Key: "karpenter.sh/disruption", Effect: v1.TaintEffectNoSchedule}}
+	w.update(node)
+	cs := &CustomScheduler{scoreMode: mostMode, spot: w, nodes: fakeframework.NodeInfoLister{healthy, doomed}}
+
+	member := makeGroupPods("g1", 1, 1)[0]
+	if status := cs.Filter(context.Background(), nil, member, doomed); status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected a gang member to be filtered, got %v", status)
+	}
+	if status := cs.Filter(context.Background(), nil, &v1.Pod{}, doomed); !status.IsSuccess() {
+		t.Errorf("expected an ungrouped pod to pass, got %v", status)
+	}
+
+	scores := framework.NodeScoreList{{Name: "healthy", Score: 100}, {Name: "doomed", Score: 200}}
+	if status := cs.NormalizeScore(context.Background(), nil, member, scores); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if scores[1].Score != framework.MinNodeScore {
+		t.Errorf("expected the doomed node to score %d, got %d", framework.MinNodeScore, scores[1].Score)
+	}
+}
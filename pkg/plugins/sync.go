@@ -0,0 +1,37 @@
+package plugins
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// addInformerSynced registers an informer PreFilter must wait for.
+func (cs *CustomScheduler) addInformerSynced(synced cache.InformerSynced) {
+	cs.informersSynced = append(cs.informersSynced, synced)
+}
+
+// cachesSynced reports whether every informer the plugin reads from has
+// synced. Once it has, it stays true without checking again.
+func (cs *CustomScheduler) cachesSynced() bool {
+	if cs.synced.Load() {
+		return true
+	}
+	for _, synced := range cs.informersSynced {
+		if !synced() {
+			return false
+		}
+	}
+	cs.synced.Store(true)
+	return true
+}
+
+// logCacheSync reports how long the informer caches took to sync.
+func (cs *CustomScheduler) logCacheSync(ctx context.Context) {
+	start := cs.clock.Now()
+	if !cache.WaitForCacheSync(ctx.Done(), cs.informersSynced...) {
+		return
+	}
+	cs.logger().Info("Informer caches synced", "duration", cs.clock.Since(start).Round(time.Millisecond))
+}
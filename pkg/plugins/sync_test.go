@@ -0,0 +1,27 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestCustomScheduler_PreFilterWaitsForCacheSync(t *testing.T) {
+	pods := makeGroupPods("g1", 2, 2)
+	synced := false
+	cs := &CustomScheduler{scoreMode: leastMode, pods: &faultyPodLister{stale: pods}}
+	cs.addInformerSynced(func() bool { return synced })
+
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); status.Code() != framework.Unschedulable {
+		t.Errorf("expected Unschedulable before the caches synced, got %v", status)
+	}
+	synced = true
+	if _, status := cs.PreFilter(context.Background(), framework.NewCycleState(), pods[0]); !status.IsSuccess() {
+		t.Errorf("expected Success once the caches synced, got %v", status)
+	}
+	synced = false
+	if !cs.cachesSynced() {
+		t.Error("expected the synced state to stick")
+	}
+}
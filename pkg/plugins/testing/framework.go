@@ -0,0 +1,100 @@
+// Package testing builds scheduling frameworks backed by fakes for the tests
+// of the CustomScheduler plugin, so they exercise it the way kube-scheduler
+// calls it without a cluster.
+package testing
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+// ProfileName is the profile of the frameworks NewFramework returns.
+const ProfileName = "custom-scheduler"
+
+// SharedLister is a scheduling snapshot of nodes.
+type SharedLister struct {
+	Nodes []*framework.NodeInfo
+}
+
+var _ framework.SharedLister = &SharedLister{}
+
+func (s *SharedLister) NodeInfos() framework.NodeInfoLister {
+	return fakeframework.NodeInfoLister(s.Nodes)
+}
+
+func (s *SharedLister) StorageInfos() framework.StorageInfoLister {
+	return nil
+}
+
+// Framework is a framework.Framework together with the fakes behind it.
+type Framework struct {
+	framework.Framework
+	Client   *clientsetfake.Clientset
+	Recorder *events.FakeRecorder
+}
+
+// NewFramework returns a framework whose snapshot holds nodes and whose pod
+// informer, already synced, and clientset hold pods. Events go to a fake
+// recorder buffering up to 100 of them. opts override the defaults.
+func NewFramework(nodes []*framework.NodeInfo, pods []*v1.Pod, opts ...frameworkruntime.Option) (*Framework, error) {
+	objects := make([]runtime.Object, 0, len(pods))
+	for _, p := range pods {
+		objects = append(objects, p)
+	}
+	client := clientsetfake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	store := informerFactory.Core().V1().Pods().Informer().GetStore()
+	for _, p := range pods {
+		if err := store.Add(p); err != nil {
+			return nil, fmt.Errorf("failed to add pod %s: %w", p.Name, err)
+		}
+	}
+	recorder := events.NewFakeRecorder(100)
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		ProfileName,
+		wait.NeverStop,
+		append([]frameworkruntime.Option{
+			frameworkruntime.WithClientSet(client),
+			frameworkruntime.WithInformerFactory(informerFactory),
+			frameworkruntime.WithSnapshotSharedLister(&SharedLister{Nodes: nodes}),
+			frameworkruntime.WithEventRecorder(recorder),
+		}, opts...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create framework: %w", err)
+	}
+	return &Framework{Framework: fwk, Client: client, Recorder: recorder}, nil
+}
+
+// MakeNodeInfo returns a node with milliCPU and memory allocatable, running pods.
+func MakeNodeInfo(name string, milliCPU, memory int64, pods ...*v1.Pod) *framework.NodeInfo {
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(milliCPU, resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+		v1.ResourcePods:   *resource.NewQuantity(110, resource.DecimalSI),
+	}
+	ni := framework.NewNodeInfo(pods...)
+	ni.SetNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Capacity: allocatable, Allocatable: allocatable},
+	})
+	return ni
+}
@@ -0,0 +1,63 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// FreeResourceThreshold is the least of a resource a node must have free,
+// allocatable less what its pods request, to be a candidate at all.
+type FreeResourceThreshold struct {
+	Name v1.ResourceName `json:"name"`
+	// Quantity is an absolute threshold, such as 2Gi of memory.
+	Quantity *resource.Quantity `json:"quantity,omitempty"`
+	// Percent is a threshold relative to the node's allocatable amount.
+	Percent int64 `json:"percent,omitempty"`
+}
+
+func validateFreeResourceThresholds(thresholds []FreeResourceThreshold) error {
+	for _, t := range thresholds {
+		if t.Name == "" {
+			return fmt.Errorf("resource name must not be empty")
+		}
+		if (t.Quantity == nil) == (t.Percent == 0) {
+			return fmt.Errorf("threshold of resource %s must set exactly one of quantity and percent", t.Name)
+		}
+		if t.Quantity != nil && t.Quantity.Sign() < 0 {
+			return fmt.Errorf("quantity of resource %s must not be negative, got %s", t.Name, t.Quantity.String())
+		}
+		if t.Percent < 0 || t.Percent > 100 {
+			return fmt.Errorf("percent of resource %s must be between 1 and 100, got %d", t.Name, t.Percent)
+		}
+	}
+	return nil
+}
+
+// filterFreeResources rejects nodes with less of a resource free than its
+// threshold, however the score mode ranks them.
+func (cs *CustomScheduler) filterFreeResources(nodeInfo *framework.NodeInfo) *framework.Status {
+	for _, t := range cs.config().freeResourceThresholds {
+		allocatable := resourceAmount(nodeInfo.Allocatable, t.Name)
+		free := allocatable - resourceAmount(nodeInfo.Requested, t.Name)
+		var threshold int64
+		if t.Quantity != nil {
+			threshold = requestAmount(v1.ResourceList{t.Name: *t.Quantity}, t.Name)
+		} else {
+			threshold = allocatable * t.Percent / 100
+		}
+		if free < threshold {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("node has less free %s than the threshold of %s", t.Name, formatThreshold(t)))
+		}
+	}
+	return nil
+}
+
+func formatThreshold(t FreeResourceThreshold) string {
+	if t.Quantity != nil {
+		return t.Quantity.String()
+	}
+	return fmt.Sprintf("%d%%", t.Percent)
+}
@@ -0,0 +1,58 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestCustomScheduler_FilterFreeResources(t *testing.T) {
+	// 600 of 1000 millicores and 700 of 1000 bytes are requested.
+	node := makeNodeInfo("n1", 1000, 1000)
+	node.AddPod(makeRequestingPod("default", "running", "n1", v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(600, resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(700, resource.BinarySI),
+	}))
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+	tests := []struct {
+		name       string
+		thresholds []FreeResourceThreshold
+		want       framework.Code
+	}{
+		{name: "no thresholds", want: framework.Success},
+		{name: "enough memory", thresholds: []FreeResourceThreshold{{Name: v1.ResourceMemory, Quantity: quantity("300")}}, want: framework.Success},
+		{name: "too little memory", thresholds: []FreeResourceThreshold{{Name: v1.ResourceMemory, Quantity: quantity("301")}}, want: framework.Unschedulable},
+		{name: "enough cpu by percent", thresholds: []FreeResourceThreshold{{Name: v1.ResourceCPU, Percent: 40}}, want: framework.Success},
+		{name: "too little cpu by quantity", thresholds: []FreeResourceThreshold{{Name: v1.ResourceCPU, Quantity: quantity("500m")}}, want: framework.Unschedulable},
+		{name: "too little memory by percent", thresholds: []FreeResourceThreshold{{Name: v1.ResourceCPU, Percent: 10}, {Name: v1.ResourceMemory, Percent: 31}}, want: framework.Unschedulable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Most mode would prefer the node with the most allocatable memory.
+			cs := &CustomScheduler{scoreMode: mostMode, freeResourceThresholds: tt.thresholds}
+			if got := cs.Filter(context.Background(), framework.NewCycleState(), &v1.Pod{}, node); got.Code() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateFreeResourceThresholds(t *testing.T) {
+	q := resource.MustParse("1Gi")
+	for _, thresholds := range [][]FreeResourceThreshold{
+		{{Quantity: &q}},
+		{{Name: v1.ResourceMemory}},
+		{{Name: v1.ResourceMemory, Quantity: &q, Percent: 10}},
+		{{Name: v1.ResourceMemory, Percent: 101}},
+	} {
+		if err := validateFreeResourceThresholds(thresholds); err == nil {
+			t.Errorf("expected %+v to be invalid", thresholds)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// nodeTier is a parsed entry of CustomSchedulerArgs.NodeTierWeights.
+type nodeTier struct {
+	selector labels.Selector
+	weight   float64
+}
+
+// newNodeTiers parses the label selectors of weights, ordered by selector so
+// that scores do not depend on map order.
+func newNodeTiers(weights map[string]float64) ([]nodeTier, error) {
+	selectors := make([]string, 0, len(weights))
+	for s := range weights {
+		selectors = append(selectors, s)
+	}
+	sort.Strings(selectors)
+	var tiers []nodeTier
+	for _, s := range selectors {
+		weight := weights[s]
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("nodeTierWeights[%q]: weight must be a non-negative number, got %v", s, weight)
+		}
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("nodeTierWeights[%q]: invalid label selector: %w", s, err)
+		}
+		tiers = append(tiers, nodeTier{selector: selector, weight: weight})
+	}
+	return tiers, nil
+}
+
+// tierScore is score multiplied by the weights of the tiers node is in,
+// unchanged if it is in none.
+func (cs *CustomScheduler) tierScore(score int64, node *v1.Node) int64 {
+	weight := 1.0
+	for _, t := range cs.config().nodeTiers {
+		if t.selector.Matches(labels.Set(node.Labels)) {
+			weight *= t.weight
+		}
+	}
+	return int64(math.Round(float64(score) * weight))
+}
@@ -0,0 +1,66 @@
+package plugins
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func TestCustomScheduler_NodeTierWeights(t *testing.T) {
+	// equal nodes, but for their tiers.
+	spot, onDemand, gpuSpot, other := makeNodeInfo("spot", 1000, 1000), makeNodeInfo("on-demand", 1000, 1000), makeNodeInfo("gpu-spot", 1000, 1000), makeNodeInfo("other", 1000, 1000)
+	spot.Node().Labels = map[string]string{"lifecycle": "spot"}
+	onDemand.Node().Labels = map[string]string{"lifecycle": "on-demand"}
+	gpuSpot.Node().Labels = map[string]string{"lifecycle": "spot", "gpu": "true"}
+	tiers, err := newNodeTiers(map[string]float64{"lifecycle=spot": 0.5, "lifecycle=on-demand": 1, "gpu": 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &CustomScheduler{scoreMode: mostMode, nodeTiers: tiers, nodes: fakeframework.NodeInfoLister{spot, onDemand, gpuSpot, other}}
+
+	scores := framework.NodeScoreList{}
+	for _, name := range []string{"spot", "on-demand", "gpu-spot", "other"} {
+		score, status := cs.Score(context.Background(), nil, &v1.Pod{}, name)
+		if !status.IsSuccess() {
+			t.Fatal(status)
+		}
+		scores = append(scores, framework.NodeScore{Name: name, Score: score})
+	}
+	if status := cs.NormalizeScore(context.Background(), nil, &v1.Pod{}, scores); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	got := map[string]int64{}
+	for _, s := range scores {
+		got[s.Name] = s.Score
+	}
+	base := float64(got["other"])
+	want := map[string]int64{"spot": int64(math.Round(base * 0.5)), "on-demand": int64(base), "gpu-spot": int64(math.Round(base * 0.25)), "other": int64(base)}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestNewNodeTiers_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		weights map[string]float64
+		wantErr string
+	}{
+		{name: "negative weight", weights: map[string]float64{"lifecycle=spot": -1}, wantErr: "weight must be a non-negative number"},
+		{name: "invalid selector", weights: map[string]float64{"lifecycle in (spot": 1}, wantErr: "invalid label selector"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newNodeTiers(tt.weights); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Rack topology modes accepted in RackTopologyArgs.Mode.
+const (
+	// rackTopologyPreferred only scores nodes by how close they are to the
+	// rest of the gang.
+	rackTopologyPreferred string = "Preferred"
+	// rackTopologyRequired additionally keeps gangs within one row, or one
+	// rack if there are no rows.
+	rackTopologyRequired string = "Required"
+)
+
+// RackTopologyArgs configures rack-aware gang placement from node labels.
+type RackTopologyArgs struct {
+	// RackLabel is the node label naming the rack of a node,
+	// nthu.scheduler/rack by default.
+	RackLabel string `json:"rackLabel,omitempty"`
+	// RowLabel, if set, is the node label naming the row of racks a node is
+	// in. Racks of a row are adjacent, so a gang that outgrows its rack
+	// falls back to the other racks of its row.
+	RowLabel string `json:"rowLabel,omitempty"`
+	// Mode is Preferred (the default), which only scores nodes, or
+	// Required, which also rejects nodes outside the row (or, without
+	// rows, the rack) the gang was placed in.
+	Mode string `json:"mode,omitempty"`
+	// Weight is the score bonus, 0 to 100, of a node in the rack of the
+	// gang; nodes in its row get half of it. 30 by default.
+	Weight int64 `json:"weight,omitempty"`
+}
+
+func newRackTopologyArgs(args RackTopologyArgs) (*RackTopologyArgs, error) {
+	if args.RackLabel == "" {
+		args.RackLabel = "nthu.scheduler/rack"
+	}
+	if args.Mode == "" {
+		args.Mode = rackTopologyPreferred
+	}
+	if args.Mode != rackTopologyPreferred && args.Mode != rackTopologyRequired {
+		return nil, fmt.Errorf("invalid rack topology mode, got %s", args.Mode)
+	}
+	if args.Weight == 0 {
+		args.Weight = 30
+	}
+	if args.Weight < 0 || args.Weight > framework.MaxNodeScore {
+		return nil, fmt.Errorf("rack topology weight must be between 0 and %d, got %d", framework.MaxNodeScore, args.Weight)
+	}
+	return &args, nil
+}
+
+const rackPlacementStateKey = framework.StateKey(Name + "/rack-placement")
+
+// rackPlacement is where a gang is, or should start, computed once per cycle.
+type rackPlacement struct {
+	// rack and row hold most of the members already placed. Before any
+	// member is placed, rack is the rack with the most free memory, so that
+	// the whole gang is likely to fit in it.
+	rack, row string
+	// placed is set once members are placed.
+	placed bool
+}
+
+func (p *rackPlacement) Clone() framework.StateData {
+	return p
+}
+
+// placeGang records in state where the members of a gang are placed. The
+// snapshot rather than the pod cache tells, since it has the members
+// assumed on nodes but not yet bound.
+func (cs *CustomScheduler) placeGang(state *framework.CycleState, members []*v1.Pod) error {
+	if cs.rackTopology == nil || state == nil {
+		return nil
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return err
+	}
+	racks, rows := map[string]int{}, map[string]int{}
+	freeMemory := map[string]int64{}
+	for _, ni := range nodeInfos {
+		node := ni.Node()
+		if node == nil {
+			continue
+		}
+		rack, ok := node.Labels[cs.rackTopology.RackLabel]
+		if !ok {
+			continue
+		}
+		freeMemory[rack] += ni.Allocatable.Memory - ni.Requested.Memory
+		for _, pi := range ni.Pods {
+			if !isMember[podKey(pi.Pod)] {
+				continue
+			}
+			racks[rack]++
+			if row, ok := node.Labels[cs.rackTopology.RowLabel]; ok && cs.rackTopology.RowLabel != "" {
+				rows[row]++
+			}
+		}
+	}
+	placement := &rackPlacement{rack: mostCommon(racks), row: mostCommon(rows), placed: len(racks) > 0}
+	if !placement.placed {
+		for rack, free := range freeMemory {
+			if placement.rack == "" || free > freeMemory[placement.rack] || (free == freeMemory[placement.rack] && rack < placement.rack) {
+				placement.rack = rack
+			}
+		}
+	}
+	state.Write(rackPlacementStateKey, placement)
+	return nil
+}
+
+// mostCommon returns the key with the largest count, the smallest such key
+// on ties, or "" if counts is empty.
+func mostCommon(counts map[string]int) string {
+	best := ""
+	for key, n := range counts {
+		if best == "" || n > counts[best] || (n == counts[best] && key < best) {
+			best = key
+		}
+	}
+	return best
+}
+
+func readRackPlacement(state *framework.CycleState) *rackPlacement {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(rackPlacementStateKey)
+	if err != nil {
+		return nil
+	}
+	return data.(*rackPlacement)
+}
+
+// filterRackTopology rejects, in Required mode, nodes outside the row of the
+// placed members of the gang, or outside their rack if there are no rows,
+// unless aging relaxed the topology of pod.
+func (cs *CustomScheduler) filterRackTopology(state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if cs.rackTopology == nil || cs.rackTopology.Mode != rackTopologyRequired || cs.topologyRelaxed(pod) {
+		return nil
+	}
+	placement := readRackPlacement(state)
+	if placement == nil || !placement.placed {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if placement.row != "" {
+		if node.Labels[cs.rackTopology.RowLabel] != placement.row {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node is outside row %s of its pod group", placement.row))
+		}
+		return nil
+	}
+	if node.Labels[cs.rackTopology.RackLabel] != placement.rack {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node is outside rack %s of its pod group", placement.rack))
+	}
+	return nil
+}
+
+// rackTopologyBonus is the score bonus of node: the full weight in the rack
+// of the gang, half of it in its row.
+func (cs *CustomScheduler) rackTopologyBonus(state *framework.CycleState, node *v1.Node) int64 {
+	placement := readRackPlacement(state)
+	if placement == nil {
+		return 0
+	}
+	if placement.rack != "" && node.Labels[cs.rackTopology.RackLabel] == placement.rack {
+		return cs.rackTopology.Weight
+	}
+	if placement.row != "" && node.Labels[cs.rackTopology.RowLabel] == placement.row {
+		return cs.rackTopology.Weight / 2
+	}
+	return 0
+}
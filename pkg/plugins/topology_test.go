@@ -0,0 +1,121 @@
+package plugins
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func makeRackNodeInfo(name, rack, row string, memory int64) *framework.NodeInfo {
+	ni := makeNodeInfo(name, 1000, memory)
+	ni.Node().Labels = map[string]string{"nthu.scheduler/rack": rack, "nthu.scheduler/row": row}
+	return ni
+}
+
+func TestCustomScheduler_RackTopology(t *testing.T) {
+	b1 := makeRackNodeInfo("b1", "rack-b", "row-1", 100)
+	c2 := makeRackNodeInfo("c2", "rack-c", "row-2", 300)
+	members := makeGroupPods("g1", 3, 3)
+
+	for _, tt := range []struct {
+		name     string
+		args     RackTopologyArgs
+		placed   bool
+		wantPass map[string]bool
+		wantBest string
+	}{
+		{
+			name:     "the first member prefers the rack with the most free memory",
+			args:     RackTopologyArgs{RowLabel: "nthu.scheduler/row", Mode: rackTopologyRequired},
+			wantPass: map[string]bool{"a1": true, "b1": true, "c2": true},
+			wantBest: "c2",
+		},
+		{
+			name:     "required keeps the gang within its row",
+			args:     RackTopologyArgs{RowLabel: "nthu.scheduler/row", Mode: rackTopologyRequired},
+			placed:   true,
+			wantPass: map[string]bool{"a1": true, "b1": true, "c2": false},
+			wantBest: "a1",
+		},
+		{
+			name:     "required keeps the gang within its rack without rows",
+			args:     RackTopologyArgs{Mode: rackTopologyRequired},
+			placed:   true,
+			wantPass: map[string]bool{"a1": true, "b1": false, "c2": false},
+			wantBest: "a1",
+		},
+		{
+			name:     "preferred only scores",
+			args:     RackTopologyArgs{RowLabel: "nthu.scheduler/row"},
+			placed:   true,
+			wantPass: map[string]bool{"a1": true, "b1": true, "c2": true},
+			wantBest: "a1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := fakeframework.NodeInfoLister{makeRackNodeInfo("a1", "rack-a", "row-1", 100), b1, c2}
+			if tt.placed {
+				nodes[0].AddPod(members[0])
+			}
+			args, err := newRackTopologyArgs(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := &CustomScheduler{rackTopology: args, nodes: nodes}
+			state := framework.NewCycleState()
+			if err := cs.placeGang(state, members); err != nil {
+				t.Fatal(err)
+			}
+			bonus := map[string]int64{}
+			for _, ni := range nodes {
+				name := ni.Node().Name
+				if got := cs.filterRackTopology(state, &v1.Pod{}, ni).IsSuccess(); got != tt.wantPass[name] {
+					t.Errorf("node %s: expected to pass %v, got %v", name, tt.wantPass[name], got)
+				}
+				bonus[name] = cs.rackTopologyBonus(state, ni.Node())
+			}
+			for name, b := range bonus {
+				if name != tt.wantBest && b >= bonus[tt.wantBest] {
+					t.Errorf("expected %s to get the largest bonus, got %v", tt.wantBest, bonus)
+				}
+			}
+		})
+	}
+
+	if _, err := newRackTopologyArgs(RackTopologyArgs{Mode: "Sometimes"}); err == nil {
+		t.Errorf("expected an invalid mode to be rejected")
+	}
+}
+
+func TestCustomScheduler_RackTopologyRowFallback(t *testing.T) {
+	nodes := fakeframework.NodeInfoLister{
+		makeRackNodeInfo("a1", "rack-a", "row-1", 100),
+		makeRackNodeInfo("b1", "rack-b", "row-1", 100),
+		makeRackNodeInfo("c2", "rack-c", "row-2", 100),
+	}
+	members := makeGroupPods("g1", 2, 2)
+	nodes[0].AddPod(members[0])
+	args, err := newRackTopologyArgs(RackTopologyArgs{RowLabel: "nthu.scheduler/row"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &CustomScheduler{rackTopology: args, nodes: nodes}
+	state := framework.NewCycleState()
+	if err := cs.placeGang(state, members); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		node *v1.Node
+		want int64
+	}{
+		{node: nodes[0].Node(), want: 30},
+		{node: nodes[1].Node(), want: 15},
+		{node: nodes[2].Node(), want: 0},
+	} {
+		if got := cs.rackTopologyBonus(state, tt.node); got != tt.want {
+			t.Errorf("node %s: expected a bonus of %d, got %d", tt.node.Name, tt.want, got)
+		}
+	}
+}
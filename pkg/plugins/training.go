@@ -0,0 +1,111 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// trainingJob describes a Kubeflow training-operator kind.
+type trainingJob struct {
+	gvr schema.GroupVersionResource
+	// replicaSpecsField is the spec field holding the replica specs by role.
+	replicaSpecsField string
+}
+
+// trainingJobs are the training CRs whose pods get gang semantics without labels, by kind.
+var trainingJobs = map[string]trainingJob{
+	"PyTorchJob": {gvr: schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "pytorchjobs"}, replicaSpecsField: "pytorchReplicaSpecs"},
+	"TFJob":      {gvr: schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "tfjobs"}, replicaSpecsField: "tfReplicaSpecs"},
+	"MPIJob":     {gvr: schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "mpijobs"}, replicaSpecsField: "mpiReplicaSpecs"},
+}
+
+// trainingJobOwner returns the controller reference of pod if it points at a
+// recognized training CR.
+func trainingJobOwner(pod *v1.Pod) (kind, name string, ok bool) {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != "kubeflow.org" {
+			continue
+		}
+		if _, known := trainingJobs[ref.Kind]; known {
+			return ref.Kind, ref.Name, true
+		}
+	}
+	return "", "", false
+}
+
+// trainingGroup derives the group of a pod owned by a training CR: the CR is
+// the group, keyed by its kind, its members are the pods it controls, and
+// minAvailable is its schedulingPolicy.minAvailable or else the
+// total number of replicas across all roles.
+func (cs *CustomScheduler) trainingGroup(pod *v1.Pod, kind, name string) (*podGroup, *framework.Status) {
+	job, err := cs.trainingJobs[kind].Get(pod.Namespace, name)
+	if err != nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to get %s %s/%s: %v", kind, pod.Namespace, name, err))
+	}
+	if job == nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("%s %s/%s not found", kind, pod.Namespace, name))
+	}
+	minAvailable, err := trainingMinAvailable(job, trainingJobs[kind].replicaSpecsField)
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid %s %s/%s: %v", kind, pod.Namespace, name, err))
+	}
+
+	uid := job.GetUID()
+	return &podGroup{
+		name:         name,
+		namespace:    pod.Namespace,
+		minAvailable: minAvailable,
+		source:       strings.ToLower(kind),
+		selector:     labels.Everything(),
+		indexLabel:   controllerUIDIndex,
+		indexValue:   string(uid),
+		member: func(p *v1.Pod) bool {
+			if p.Namespace != pod.Namespace {
+				return false
+			}
+			for _, ref := range p.OwnerReferences {
+				if ref.UID == uid {
+					return true
+				}
+			}
+			return false
+		},
+	}, nil
+}
+
+func trainingMinAvailable(job *unstructured.Unstructured, replicaSpecsField string) (int, error) {
+	minAvailable, found, err := unstructured.NestedInt64(job.Object, "spec", "runPolicy", "schedulingPolicy", "minAvailable")
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		return int(minAvailable), nil
+	}
+
+	specs, _, err := unstructured.NestedMap(job.Object, "spec", replicaSpecsField)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for role := range specs {
+		replicas, found, err := unstructured.NestedInt64(specs, role, "replicas")
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			replicas = 1
+		}
+		total += int(replicas)
+	}
+	return total, nil
+}
@@ -0,0 +1,129 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makePyTorchJob(name string, uid types.UID, spec map[string]interface{}) *unstructured.Unstructured {
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kubeflow.org/v1",
+		"kind":       "PyTorchJob",
+		"spec":       spec,
+	}}
+	job.SetNamespace("default")
+	job.SetName(name)
+	job.SetUID(uid)
+	return job
+}
+
+func makeJobPods(kind, name string, uid types.UID, n int) []*v1.Pod {
+	isController := true
+	var pods []*v1.Pod
+	for i := 0; i < n; i++ {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", name, i),
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "kubeflow.org/v1", Kind: kind, Name: name, UID: uid, Controller: &isController,
+			}},
+		}})
+	}
+	return pods
+}
+
+func TestCustomScheduler_PreFilterTrainingJobs(t *testing.T) {
+	replicas := map[string]interface{}{
+		"pytorchReplicaSpecs": map[string]interface{}{
+			"Master": map[string]interface{}{"replicas": int64(1)},
+			"Worker": map[string]interface{}{"replicas": int64(3)},
+		},
+	}
+	withPolicy := map[string]interface{}{
+		"pytorchReplicaSpecs": replicas["pytorchReplicaSpecs"],
+		"runPolicy": map[string]interface{}{
+			"schedulingPolicy": map[string]interface{}{"minAvailable": int64(2)},
+		},
+	}
+	jobs := newFakeUnstructuredLister(t,
+		makePyTorchJob("all-replicas", "uid-1", replicas),
+		makePyTorchJob("min-available", "uid-2", withPolicy),
+	)
+	pods := append(makeJobPods("PyTorchJob", "all-replicas", "uid-1", 3), makeJobPods("PyTorchJob", "min-available", "uid-2", 2)...)
+	lister := podListerFunc(func(selector labels.Selector) ([]*v1.Pod, error) {
+		return pods, nil
+	})
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want framework.Code
+	}{
+		{name: "replicas not all created", pod: pods[0], want: framework.Unschedulable},
+		{name: "schedulingPolicy minAvailable reached", pod: pods[3], want: framework.Success},
+		{name: "job missing", pod: makeJobPods("PyTorchJob", "missing", "uid-3", 1)[0], want: framework.Unschedulable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{
+				scoreMode:    leastMode,
+				pods:         lister,
+				trainingJobs: map[string]PodGroupGetter{"PyTorchJob": jobs},
+			}
+			if _, got := cs.PreFilter(context.Background(), nil, tt.pod); got.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %v", tt.want, got.Code(), got.Message())
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_TrainingGroupKey(t *testing.T) {
+	jobs := newFakeUnstructuredLister(t, makePyTorchJob("train", "uid-1", map[string]interface{}{
+		"pytorchReplicaSpecs": map[string]interface{}{"Worker": map[string]interface{}{"replicas": int64(2)}},
+	}))
+	members := makeJobPods("PyTorchJob", "train", "uid-1", 2)
+	gang := makeGroupPods("train", 2, 1)[0]
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Pods().Informer()
+	indexer, err := indexPodsByGroup(informer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range append(members, gang) {
+		if err := indexer.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a failing lister shows the members are looked up through the index.
+	cs := &CustomScheduler{podIndexer: indexer, pods: &faultyPodLister{err: errors.New("listed")}, trainingJobs: map[string]PodGroupGetter{"PyTorchJob": jobs}}
+
+	job, status := cs.groupOf(members[0])
+	if !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	label, status := cs.groupOf(gang)
+	if !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	if job.key() == label.key() {
+		t.Errorf("expected the PyTorchJob and the label gang of the same name to be distinct, both are %s", job.key())
+	}
+	got, err := cs.groupMembers(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := podNames(members); !reflect.DeepEqual(podNames(got), want) {
+		t.Errorf("expected members %v, got %v", want, podNames(got))
+	}
+}
@@ -0,0 +1,143 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+// SchemeGroupVersionV1 is the group and version of CustomSchedulerArgsV1,
+// those of a v1 KubeSchedulerConfiguration.
+var SchemeGroupVersionV1 = schema.GroupVersion{Group: SchemeGroupVersion.Group, Version: "v1"}
+
+// internalGroupVersion is the version of CustomSchedulerArgs, which New
+// reads, that the versioned args convert to.
+var internalGroupVersion = schema.GroupVersion{Group: SchemeGroupVersion.Group, Version: runtime.APIVersionInternal}
+
+// CustomSchedulerArgsV1beta3 and CustomSchedulerArgsV1 are the args of the
+// plugin in the pluginConfig entry of a v1beta3 or v1 KubeSchedulerConfiguration,
+// with apiVersion and kind set. They are defaulted when they are loaded.
+type (
+	CustomSchedulerArgsV1beta3 CustomSchedulerArgs
+	CustomSchedulerArgsV1      CustomSchedulerArgs
+)
+
+// codecs decode args with apiVersion and kind set, rejecting unknown fields.
+var codecs = serializer.NewCodecFactory(Scheme, serializer.EnableStrict)
+
+func addVersionedTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("CustomSchedulerArgs"), &CustomSchedulerArgsV1beta3{})
+	scheme.AddKnownTypeWithName(SchemeGroupVersionV1.WithKind("CustomSchedulerArgs"), &CustomSchedulerArgsV1{})
+	scheme.AddTypeDefaultingFunc(&CustomSchedulerArgsV1beta3{}, func(obj interface{}) {
+		SetDefaultsCustomSchedulerArgs((*CustomSchedulerArgs)(obj.(*CustomSchedulerArgsV1beta3)))
+	})
+	scheme.AddTypeDefaultingFunc(&CustomSchedulerArgsV1{}, func(obj interface{}) {
+		SetDefaultsCustomSchedulerArgs((*CustomSchedulerArgs)(obj.(*CustomSchedulerArgsV1)))
+	})
+	for _, err := range []error{
+		scheme.AddConversionFunc((*CustomSchedulerArgsV1beta3)(nil), (*CustomSchedulerArgs)(nil), func(a, b interface{}, _ conversion.Scope) error {
+			*b.(*CustomSchedulerArgs) = internalArgs(CustomSchedulerArgs(*a.(*CustomSchedulerArgsV1beta3)))
+			return nil
+		}),
+		scheme.AddConversionFunc((*CustomSchedulerArgs)(nil), (*CustomSchedulerArgsV1beta3)(nil), func(a, b interface{}, _ conversion.Scope) error {
+			*b.(*CustomSchedulerArgsV1beta3) = CustomSchedulerArgsV1beta3(internalArgs(*a.(*CustomSchedulerArgs)))
+			return nil
+		}),
+		scheme.AddConversionFunc((*CustomSchedulerArgsV1)(nil), (*CustomSchedulerArgs)(nil), func(a, b interface{}, _ conversion.Scope) error {
+			*b.(*CustomSchedulerArgs) = internalArgs(CustomSchedulerArgs(*a.(*CustomSchedulerArgsV1)))
+			return nil
+		}),
+		scheme.AddConversionFunc((*CustomSchedulerArgs)(nil), (*CustomSchedulerArgsV1)(nil), func(a, b interface{}, _ conversion.Scope) error {
+			*b.(*CustomSchedulerArgsV1) = CustomSchedulerArgsV1(internalArgs(*a.(*CustomSchedulerArgs)))
+			return nil
+		}),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// internalArgs returns a deep copy of args without its apiVersion and kind,
+// which the scheme sets on the versions it converts to. The versions share
+// their fields, so converting is copying.
+func internalArgs(args CustomSchedulerArgs) CustomSchedulerArgs {
+	out := args.DeepCopyObject().(*CustomSchedulerArgs)
+	out.TypeMeta = metav1.TypeMeta{}
+	return *out
+}
+
+// SetDefaultsCustomSchedulerArgs sets the defaults of the versioned args:
+// the Weighted mode if they list scoreResources and the Least mode
+// otherwise, scoring memory.
+func SetDefaultsCustomSchedulerArgs(args *CustomSchedulerArgs) {
+	if args.Mode == "" {
+		args.Mode = leastMode
+		if len(args.ScoreResources) > 0 {
+			args.Mode = weightedMode
+		}
+	}
+	if args.Resource == "" {
+		args.Resource = v1.ResourceMemory
+	}
+}
+
+// DeepCopyObject implements runtime.Object.
+func (args *CustomSchedulerArgsV1beta3) DeepCopyObject() runtime.Object {
+	if args == nil {
+		return nil
+	}
+	out := CustomSchedulerArgsV1beta3(*(*CustomSchedulerArgs)(args).DeepCopyObject().(*CustomSchedulerArgs))
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (args *CustomSchedulerArgsV1) DeepCopyObject() runtime.Object {
+	if args == nil {
+		return nil
+	}
+	out := CustomSchedulerArgsV1(*(*CustomSchedulerArgs)(args).DeepCopyObject().(*CustomSchedulerArgs))
+	return &out
+}
+
+// LoadArgs returns the args of New from obj, which may be:
+//   - nil, for the Least mode;
+//   - CustomSchedulerArgs, used as they are;
+//   - CustomSchedulerArgsV1beta3 or CustomSchedulerArgsV1, defaulted and
+//     converted;
+//   - the runtime.Unknown the scheduler hands out-of-tree plugins, decoded as
+//     the version its apiVersion and kind name, or else, as before versions,
+//     as CustomSchedulerArgs without defaults.
+func LoadArgs(obj runtime.Object) (*CustomSchedulerArgs, error) {
+	switch obj := obj.(type) {
+	case *CustomSchedulerArgsV1beta3, *CustomSchedulerArgsV1:
+		versioned := obj.DeepCopyObject()
+		Scheme.Default(versioned)
+		args := &CustomSchedulerArgs{}
+		if err := Scheme.Convert(versioned, args, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert %s args: %w", Name, err)
+		}
+		return args, nil
+	case *runtime.Unknown:
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(obj.Raw, &typeMeta); err == nil && typeMeta.APIVersion != "" {
+			decoded, _, err := codecs.UniversalDecoder(internalGroupVersion).Decode(obj.Raw, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s args: %w", Name, err)
+			}
+			args, ok := decoded.(*CustomSchedulerArgs)
+			if !ok {
+				return nil, fmt.Errorf("failed to decode %s args: got %s", Name, typeMeta.Kind)
+			}
+			return args, nil
+		}
+	}
+	return decodeArgs(obj)
+}
@@ -0,0 +1,78 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestLoadArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		obj          runtime.Object
+		wantMode     string
+		wantResource v1.ResourceName
+	}{
+		{name: "v1", obj: &runtime.Unknown{Raw: []byte(`{"apiVersion": "kubescheduler.config.k8s.io/v1", "kind": "CustomSchedulerArgs", "mode": "Most"}`)}, wantMode: mostMode, wantResource: v1.ResourceMemory},
+		{name: "v1 defaults", obj: &runtime.Unknown{Raw: []byte(`{"apiVersion": "kubescheduler.config.k8s.io/v1", "kind": "CustomSchedulerArgs"}`)}, wantMode: leastMode, wantResource: v1.ResourceMemory},
+		{name: "v1beta3 with scoreResources", obj: &runtime.Unknown{Raw: []byte(`{"apiVersion": "kubescheduler.config.k8s.io/v1beta3", "kind": "CustomSchedulerArgs", "scoreResources": [{"name": "cpu", "weight": 1, "strategy": "Most"}]}`)}, wantMode: weightedMode, wantResource: v1.ResourceMemory},
+		{name: "typed v1", obj: &CustomSchedulerArgsV1{Resource: v1.ResourceCPU}, wantMode: leastMode, wantResource: v1.ResourceCPU},
+		{name: "legacy without apiVersion", obj: &runtime.Unknown{Raw: []byte(`{"mode": "Most"}`)}, wantMode: mostMode},
+		{name: "internal", obj: &CustomSchedulerArgs{Mode: mostMode}, wantMode: mostMode},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := LoadArgs(tt.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if args.Mode != tt.wantMode || args.Resource != tt.wantResource {
+				t.Errorf("expected mode %q and resource %q, got %q and %q", tt.wantMode, tt.wantResource, args.Mode, args.Resource)
+			}
+			if args.APIVersion != "" || args.Kind != "" {
+				t.Errorf("expected the internal args without apiVersion and kind, got %+v", args.TypeMeta)
+			}
+		})
+	}
+}
+
+func TestLoadArgs_Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{name: "unknown field", raw: `{"apiVersion": "kubescheduler.config.k8s.io/v1", "kind": "CustomSchedulerArgs", "scoreMode": "Most"}`, wantErr: `unknown field "scoreMode"`},
+		{name: "unknown version", raw: `{"apiVersion": "kubescheduler.config.k8s.io/v2", "kind": "CustomSchedulerArgs"}`, wantErr: "failed to decode"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadArgs(&runtime.Unknown{Raw: []byte(tt.raw)}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCustomSchedulerArgs_ConvertToVersion(t *testing.T) {
+	args := &CustomSchedulerArgs{Mode: mostMode, FailurePolicies: map[string]string{integrationKueue: failurePolicyFail}}
+	for _, gv := range []schema.GroupVersion{SchemeGroupVersion, SchemeGroupVersionV1} {
+		t.Run(gv.Version, func(t *testing.T) {
+			out, err := Scheme.ConvertToVersion(args, gv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gvk := out.GetObjectKind().GroupVersionKind(); gvk != gv.WithKind("CustomSchedulerArgs") {
+				t.Errorf("expected %v, got %v", gv.WithKind("CustomSchedulerArgs"), gvk)
+			}
+			back, err := LoadArgs(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if back.Mode != mostMode || back.FailurePolicies[integrationKueue] != failurePolicyFail {
+				t.Errorf("expected the args to survive a round trip, got %+v", back)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Annotations Volcano sets on pods to name their PodGroup, newest first.
+var volcanoGroupNameAnnotations = []string{
+	"scheduling.k8s.io/group-name",
+	"scheduling.volcano.sh/group-name",
+}
+
+var volcanoPodGroupGVR = schema.GroupVersionResource{Group: "scheduling.volcano.sh", Version: "v1beta1", Resource: "podgroups"}
+
+// PodGroupGetter gets custom resources describing pod groups by namespace and name.
+type PodGroupGetter interface {
+	Get(namespace, name string) (*unstructured.Unstructured, error)
+}
+
+func volcanoGroupName(pod *v1.Pod) string {
+	for _, key := range volcanoGroupNameAnnotations {
+		if name := pod.GetAnnotations()[key]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// volcanoGroup reads minMember and queue from the Volcano PodGroup name in namespace.
+func (cs *CustomScheduler) volcanoGroup(namespace, name string) (*podGroup, *framework.Status) {
+	pg, err := cs.volcanoPodGroups.Get(namespace, name)
+	if err != nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("failed to get Volcano PodGroup %s/%s: %v", namespace, name, err))
+	}
+	if pg == nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Volcano PodGroup %s/%s not found", namespace, name))
+	}
+	minMember, _, err := unstructured.NestedInt64(pg.Object, "spec", "minMember")
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("invalid minMember in Volcano PodGroup %s/%s: %v", namespace, name, err))
+	}
+	queue, _, _ := unstructured.NestedString(pg.Object, "spec", "queue")
+
+	return &podGroup{
+		name:         name,
+		namespace:    namespace,
+		minAvailable: int(minMember),
+		source:       volcanoSource,
+		queue:        queue,
+		selector:     labels.Everything(),
+		member: func(p *v1.Pod) bool {
+			return p.Namespace == namespace && volcanoGroupName(p) == name
+		},
+	}, nil
+}
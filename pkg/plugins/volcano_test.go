@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func makeVolcanoPodGroup(name string, minMember int64) *unstructured.Unstructured {
+	pg := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "scheduling.volcano.sh/v1beta1",
+		"kind":       "PodGroup",
+		"spec":       map[string]interface{}{"minMember": minMember, "queue": "default"},
+	}}
+	pg.SetNamespace("default")
+	pg.SetName(name)
+	return pg
+}
+
+func makeVolcanoPods(group string, n int) []*v1.Pod {
+	var pods []*v1.Pod
+	for i := 0; i < n; i++ {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", group, i),
+			Namespace:   "default",
+			Annotations: map[string]string{volcanoGroupNameAnnotations[0]: group},
+		}})
+	}
+	return pods
+}
+
+func TestCustomScheduler_PreFilterVolcano(t *testing.T) {
+	broken := makeVolcanoPodGroup("broken", 0)
+	broken.Object["spec"].(map[string]interface{})["minMember"] = "two"
+	podGroups := newFakeUnstructuredLister(t, makeVolcanoPodGroup("small", 2), makeVolcanoPodGroup("large", 4), makeVolcanoPodGroup("empty", 0), broken)
+	pods := append(makeVolcanoPods("small", 2), makeVolcanoPods("large", 3)...)
+	// a pod of the same name in another namespace must not count
+	other := makeVolcanoPods("large", 1)[0]
+	other.Namespace = "other"
+	pods = append(pods, other)
+	lister := podListerFunc(func(selector labels.Selector) ([]*v1.Pod, error) {
+		return pods, nil
+	})
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want framework.Code
+	}{
+		{name: "enough members", pod: makeVolcanoPods("small", 1)[0], want: framework.Success},
+		{name: "not enough members", pod: makeVolcanoPods("large", 1)[0], want: framework.Unschedulable},
+		{name: "PodGroup missing", pod: makeVolcanoPods("missing", 1)[0], want: framework.Unschedulable},
+		{name: "invalid minMember", pod: makeVolcanoPods("broken", 1)[0], want: framework.UnschedulableAndUnresolvable},
+		{name: "zero minMember", pod: makeVolcanoPods("empty", 1)[0], want: framework.UnschedulableAndUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: leastMode, pods: lister, volcanoPodGroups: podGroups}
+			if _, got := cs.PreFilter(context.Background(), nil, tt.pod); got.Code() != tt.want {
+				t.Errorf("expected %v, got %v: %v", tt.want, got.Code(), got.Message())
+			}
+		})
+	}
+}
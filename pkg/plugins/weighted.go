@@ -0,0 +1,119 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// weightedMode sums the weighted scores of CustomSchedulerArgs.ScoreResources,
+// each scoring its resource like the Least or Most mode. It is the mode when
+// ScoreResources is set and Mode is not.
+const weightedMode string = "Weighted"
+
+// ResourceStrategy scores one resource in the Weighted mode.
+type ResourceStrategy struct {
+	Name   v1.ResourceName `json:"name"`
+	Weight int64           `json:"weight"`
+	// Strategy is Least, to prefer nodes with less of the resource
+	// allocatable (or free), or Most, to prefer those with more.
+	Strategy string `json:"strategy"`
+}
+
+// maxResourceWeight bounds the weights of ScoreResources, so that the sum
+// of their weighted permille scores cannot overflow.
+const maxResourceWeight = 1000000
+
+func validateResourceStrategies(strategies []ResourceStrategy) error {
+	seen := map[v1.ResourceName]bool{}
+	for _, s := range strategies {
+		if s.Name == "" {
+			return fmt.Errorf("resource name must not be empty")
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("resource %s is listed twice", s.Name)
+		}
+		seen[s.Name] = true
+		if s.Weight <= 0 || s.Weight > maxResourceWeight {
+			return fmt.Errorf("weight of resource %s must be between 1 and %d, got %d", s.Name, maxResourceWeight, s.Weight)
+		}
+		if s.Strategy != leastMode && s.Strategy != mostMode {
+			return fmt.Errorf("strategy of resource %s must be %s or %s, got %q", s.Name, leastMode, mostMode, s.Strategy)
+		}
+	}
+	return nil
+}
+
+const largestAmountsStateKey = framework.StateKey(Name + "/largest-amounts")
+
+// largestAmounts are the largest allocatable (or, scoring free resources,
+// free) amounts of the ScoreResources on any node, computed once per cycle.
+type largestAmounts map[v1.ResourceName]int64
+
+func (l largestAmounts) Clone() framework.StateData {
+	return l
+}
+
+// largestAmounts returns the largest amounts of the ScoreResources on any
+// node, from state if PreScore stored them.
+func (cs *CustomScheduler) largestAmounts(state *framework.CycleState) (largestAmounts, error) {
+	if state != nil {
+		if data, err := state.Read(largestAmountsStateKey); err == nil {
+			return data.(largestAmounts), nil
+		}
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	largest := largestAmounts{}
+	for _, ni := range nodeInfos {
+		for _, s := range cs.scoreResources {
+			if amount := cs.scoredAmount(ni, s.Name); amount > largest[s.Name] {
+				largest[s.Name] = amount
+			}
+		}
+	}
+	return largest, nil
+}
+
+// scoredAmount is scoredResources for any resource.
+func (cs *CustomScheduler) scoredAmount(nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	amount := resourceAmount(nodeInfo.Allocatable, name)
+	if cs.scoreFreeResources {
+		amount = nonNegative(amount - requestedAmount(nodeInfo, name))
+	}
+	return amount
+}
+
+// requestedAmount returns the amount of name the pods on nodeInfo request.
+// The framework does not count pods in Requested, so they are counted here.
+func requestedAmount(nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	if name == v1.ResourcePods {
+		return int64(len(nodeInfo.Pods))
+	}
+	return resourceAmount(nodeInfo.Requested, name)
+}
+
+// weightedScore sums, over the ScoreResources, the weight of each times the
+// permille of the largest amount on any node that nodeInfo has, for Most,
+// or does not have, for Least. Resources no node has are skipped.
+func (cs *CustomScheduler) weightedScore(largest largestAmounts, nodeInfo *framework.NodeInfo) int64 {
+	var score int64
+	for _, s := range cs.scoreResources {
+		if largest[s.Name] <= 0 {
+			continue
+		}
+		amount := cs.scoredAmount(nodeInfo, s.Name)
+		if amount > largest[s.Name] {
+			amount = largest[s.Name]
+		}
+		permille := scaleScore(uint64(amount), uint64(largest[s.Name]), 1000)
+		if s.Strategy == leastMode {
+			permille = 1000 - permille
+		}
+		score += s.Weight * permille
+	}
+	return score
+}
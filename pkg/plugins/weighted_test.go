@@ -0,0 +1,96 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+)
+
+func TestCustomScheduler_ScoreWeighted(t *testing.T) {
+	// big has the most memory and the least CPU, small the reverse.
+	big := makeNodeInfo("big", 1000, 800)
+	small := makeNodeInfo("small", 4000, 200)
+	nodeInfos := fakeframework.NodeInfoLister{big, small}
+	nodes := []*v1.Node{big.Node(), small.Node()}
+	for _, tt := range []struct {
+		name      string
+		resources []ResourceStrategy
+		want      map[string]int64
+	}{
+		{
+			name:      "least memory",
+			resources: []ResourceStrategy{{Name: v1.ResourceMemory, Weight: 1, Strategy: leastMode}},
+			want:      map[string]int64{"big": 0, "small": 750},
+		},
+		{
+			name: "memory weight 2 least, cpu weight 1 most",
+			resources: []ResourceStrategy{
+				{Name: v1.ResourceMemory, Weight: 2, Strategy: leastMode},
+				{Name: v1.ResourceCPU, Weight: 1, Strategy: mostMode},
+			},
+			want: map[string]int64{"big": 250, "small": 2500},
+		},
+		{
+			name: "most memory outweighs most cpu",
+			resources: []ResourceStrategy{
+				{Name: v1.ResourceMemory, Weight: 3, Strategy: mostMode},
+				{Name: v1.ResourceCPU, Weight: 1, Strategy: mostMode},
+			},
+			want: map[string]int64{"big": 3250, "small": 1750},
+		},
+		{
+			name:      "resource no node has",
+			resources: []ResourceStrategy{{Name: "nvidia.com/gpu", Weight: 1, Strategy: mostMode}},
+			want:      map[string]int64{"big": 0, "small": 0},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: weightedMode, scoreResources: tt.resources, nodes: nodeInfos}
+			state := framework.NewCycleState()
+			if status := cs.PreScore(context.Background(), state, &v1.Pod{}, nodes); !status.IsSuccess() {
+				t.Fatal(status)
+			}
+			for node, want := range tt.want {
+				score, status := cs.Score(context.Background(), state, &v1.Pod{}, node)
+				if !status.IsSuccess() {
+					t.Fatal(status)
+				}
+				if score != want {
+					t.Errorf("expected %s to score %d, got %d", node, want, score)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomScheduler_ScoreResource(t *testing.T) {
+	// big has more memory, small more GPUs and room for more pods.
+	big, small := makeNodeInfo("big", 1000, 800), makeNodeInfo("small", 1000, 200)
+	big.Allocatable.ScalarResources = map[v1.ResourceName]int64{"nvidia.com/gpu": 2}
+	small.Allocatable.ScalarResources = map[v1.ResourceName]int64{"nvidia.com/gpu": 8}
+	big.Allocatable.AllowedPodNumber, small.Allocatable.AllowedPodNumber = 110, 110
+	big.AddPod(makeGroupPods("g1", 1, 1)[0])
+	nodeInfos := fakeframework.NodeInfoLister{big, small}
+	for _, tt := range []struct {
+		name     string
+		resource v1.ResourceName
+		free     bool
+		want     map[string]int64
+	}{
+		{name: "memory by default", want: map[string]int64{"big": 800, "small": 200}},
+		{name: "extended resource", resource: "nvidia.com/gpu", want: map[string]int64{"big": 2, "small": 8}},
+		{name: "free pods", resource: v1.ResourcePods, free: true, want: map[string]int64{"big": 109, "small": 110}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{scoreMode: mostMode, resource: tt.resource, scoreFreeResources: tt.free, nodes: nodeInfos}
+			for name, want := range tt.want {
+				if got, status := cs.Score(context.Background(), nil, &v1.Pod{}, name); !status.IsSuccess() || got != want {
+					t.Errorf("node %s: expected %d, got %d, %v", name, want, got, status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// startWindowAnnotation restricts when the gang of a pod may start, e.g.
+// "Mon-Fri 22:00-06:00; Sat,Sun 00:00-24:00". Windows are separated by
+// semicolons; each is an optional list or range of weekdays followed by a
+// time range, which wraps past midnight when it ends before it starts.
+const startWindowAnnotation string = "nthu.scheduler/start-window"
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+	"Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+}
+
+// startWindow is a parsed window: on each of days, from start to end, as
+// offsets from midnight.
+type startWindow struct {
+	days       [7]bool
+	start, end time.Duration
+}
+
+// parseStartWindows parses the value of the start window annotation.
+func parseStartWindows(value string) ([]startWindow, error) {
+	var windows []startWindow
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		w := startWindow{}
+		fields := strings.Fields(entry)
+		switch len(fields) {
+		case 1:
+			for d := range w.days {
+				w.days[d] = true
+			}
+		case 2:
+			if err := parseWeekdays(fields[0], &w.days); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid window %q: want [days] HH:MM-HH:MM", entry)
+		}
+		times := strings.Split(fields[len(fields)-1], "-")
+		if len(times) != 2 {
+			return nil, fmt.Errorf("invalid time range %q: want HH:MM-HH:MM", fields[len(fields)-1])
+		}
+		var err error
+		if w.start, err = parseClock(times[0]); err != nil {
+			return nil, err
+		}
+		if w.end, err = parseClock(times[1]); err != nil {
+			return nil, err
+		}
+		if w.start == w.end {
+			return nil, fmt.Errorf("invalid time range %q: empty window", fields[len(fields)-1])
+		}
+		windows = append(windows, w)
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no windows in %q", value)
+	}
+	return windows, nil
+}
+
+// parseWeekdays parses comma-separated weekdays or weekday ranges such as "Mon-Fri,Sun".
+func parseWeekdays(value string, days *[7]bool) error {
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.Split(part, "-")
+		if len(bounds) > 2 {
+			return fmt.Errorf("invalid weekdays %q", part)
+		}
+		first, ok := weekdays[bounds[0]]
+		if !ok {
+			return fmt.Errorf("invalid weekday %q", bounds[0])
+		}
+		last, ok := weekdays[bounds[len(bounds)-1]]
+		if !ok {
+			return fmt.Errorf("invalid weekday %q", bounds[len(bounds)-1])
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM, where 24:00 is the end of the day.
+func parseClock(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes > 0) {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM", value)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// contains reports whether t falls in w. A window that wraps past midnight
+// belongs to the day it starts on.
+func (w startWindow) contains(t time.Time) bool {
+	offset := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()))
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && offset >= w.start && offset < w.end
+	}
+	yesterday := (day + 6) % 7
+	return (w.days[day] && offset >= w.start) || (w.days[yesterday] && offset < w.end)
+}
+
+// startWindowGate holds pod while its gang is outside its start window. Once
+// a member of the gang is bound, the gang has started and the rest of it is
+// let through whatever the time.
+func (cs *CustomScheduler) startWindowGate(pod *v1.Pod) *framework.Status {
+	value, ok := pod.Annotations[startWindowAnnotation]
+	if !ok {
+		return nil
+	}
+	windows, err := parseStartWindows(value)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("invalid %s annotation %q: %v", startWindowAnnotation, truncate(value), err))
+	}
+	now := cs.clock.Now()
+	if cs.startWindowLocation != nil {
+		now = now.In(cs.startWindowLocation)
+	}
+	for _, w := range windows {
+		if w.contains(now) {
+			return nil
+		}
+	}
+	if cs.gangStarted(pod) {
+		return nil
+	}
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+		fmt.Sprintf("outside of the start window %q of its pod group", truncate(value)))
+}
+
+// gangStarted reports whether a member of the group of pod is bound.
+func (cs *CustomScheduler) gangStarted(pod *v1.Pod) bool {
+	if cs.isUngrouped(pod) {
+		return false
+	}
+	group, status := cs.groupOf(pod)
+	if !status.IsSuccess() {
+		return false
+	}
+	members, err := cs.groupMembers(group)
+	if err != nil {
+		return false
+	}
+	for _, p := range members {
+		if p.Spec.NodeName != "" {
+			return true
+		}
+	}
+	return false
+}
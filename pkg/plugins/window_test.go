@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestParseStartWindows(t *testing.T) {
+	for _, value := range []string{"", "22:00", "Mon 22:00-22:00", "Funday 01:00-02:00", "25:00-26:00", "1:00-2:00", "Mon Tue 01:00-02:00"} {
+		if _, err := parseStartWindows(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+	if _, err := parseStartWindows("Mon-Fri 22:00-06:00; Sat,Sun 00:00-24:00"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStartWindowContains(t *testing.T) {
+	windows, err := parseStartWindows("Mon-Fri 22:00-06:00; Sat 09:00-17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2023-05-01 is a Monday.
+	for _, tt := range []struct {
+		at   string
+		want bool
+	}{
+		{at: "2023-05-01T21:59:00Z", want: false},
+		{at: "2023-05-01T22:00:00Z", want: true},
+		{at: "2023-05-02T05:59:00Z", want: true},
+		{at: "2023-05-02T06:00:00Z", want: false},
+		// Friday night runs into Saturday morning, Sunday night does not run into Monday.
+		{at: "2023-05-06T03:00:00Z", want: true},
+		{at: "2023-05-06T12:00:00Z", want: true},
+		{at: "2023-05-01T03:00:00Z", want: false},
+		{at: "2023-05-07T23:00:00Z", want: false},
+	} {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		got := false
+		for _, w := range windows {
+			got = got || w.contains(at)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.at, tt.want, got)
+		}
+	}
+}
+
+func TestCustomScheduler_StartWindowGate(t *testing.T) {
+	night := map[string]string{startWindowAnnotation: "22:00-06:00"}
+	pods := makeGroupPods("g1", 2, 2)
+	for _, p := range pods {
+		p.Annotations = night
+	}
+	noon, _ := time.Parse(time.RFC3339, "2023-05-01T12:00:00Z")
+	midnight, _ := time.Parse(time.RFC3339, "2023-05-01T23:00:00Z")
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := pods[0].DeepCopy()
+	started.Spec.NodeName = "n1"
+
+	for _, tt := range []struct {
+		name     string
+		now      time.Time
+		location *time.Location
+		members  []*v1.Pod
+		pod      *v1.Pod
+		want     framework.Code
+	}{
+		{name: "no window", now: noon, members: makeGroupPods("g2", 1, 1), pod: makeGroupPods("g2", 1, 1)[0], want: framework.Success},
+		{name: "outside the window", now: noon, members: pods, pod: pods[1], want: framework.UnschedulableAndUnresolvable},
+		{name: "inside the window", now: midnight, members: pods, pod: pods[1], want: framework.Success},
+		// 12:00 UTC is 20:00 in Taipei, 16:00 UTC is midnight.
+		{name: "time zone", now: noon.Add(4 * time.Hour), location: taipei, members: pods, pod: pods[1], want: framework.Success},
+		{name: "gang already started", now: noon, members: []*v1.Pod{started, pods[1]}, pod: pods[1], want: framework.Success},
+		{
+			name: "invalid window",
+			now:  noon,
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{startWindowAnnotation: "at night"}}},
+			want: framework.UnschedulableAndUnresolvable,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CustomScheduler{
+				scoreMode:           leastMode,
+				pods:                &faultyPodLister{stale: tt.members},
+				clock:               testingclock.NewFakeClock(tt.now),
+				startWindowLocation: tt.location,
+			}
+			if got := cs.PreEnqueue(context.Background(), tt.pod); got.Code() != tt.want {
+				t.Errorf("PreEnqueue: expected %v, got %v", tt.want, got)
+			}
+			if tt.want == framework.Success {
+				return
+			}
+			if _, got := cs.PreFilter(context.Background(), framework.NewCycleState(), tt.pod); got.Code() != tt.want {
+				t.Errorf("PreFilter: expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package plugins
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ZoneSpreadArgs configures spreading the members of each gang evenly
+// across zones.
+type ZoneSpreadArgs struct {
+	// TopologyKey is the node label naming the zone of a node,
+	// topology.kubernetes.io/zone by default.
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// MaxSkew is how many more members of a gang one zone may host than the
+	// zone hosting the fewest, 1 by default.
+	MaxSkew int `json:"maxSkew,omitempty"`
+	// Mode is Preferred (the default), which only scores nodes, or
+	// Required, which also rejects nodes whose zone would exceed MaxSkew.
+	Mode string `json:"mode,omitempty"`
+	// Weight is the score bonus, 0 to 100, of the zones hosting the fewest
+	// members, others getting less the more they host. 30 by default.
+	Weight int64 `json:"weight,omitempty"`
+}
+
+// Zone spread modes accepted in ZoneSpreadArgs.Mode, named like the rack
+// topology modes.
+const (
+	zoneSpreadPreferred = rackTopologyPreferred
+	zoneSpreadRequired  = rackTopologyRequired
+)
+
+func newZoneSpreadArgs(args ZoneSpreadArgs) (*ZoneSpreadArgs, error) {
+	if args.TopologyKey == "" {
+		args.TopologyKey = v1.LabelTopologyZone
+	}
+	if args.MaxSkew == 0 {
+		args.MaxSkew = 1
+	}
+	if args.MaxSkew < 0 {
+		return nil, fmt.Errorf("zone spread maxSkew must be positive, got %d", args.MaxSkew)
+	}
+	if args.Mode == "" {
+		args.Mode = zoneSpreadPreferred
+	}
+	if args.Mode != zoneSpreadPreferred && args.Mode != zoneSpreadRequired {
+		return nil, fmt.Errorf("invalid zone spread mode, got %s", args.Mode)
+	}
+	if args.Weight == 0 {
+		args.Weight = 30
+	}
+	if args.Weight < 0 || args.Weight > framework.MaxNodeScore {
+		return nil, fmt.Errorf("zone spread weight must be between 0 and %d, got %d", framework.MaxNodeScore, args.Weight)
+	}
+	return &args, nil
+}
+
+const zoneSpreadStateKey = framework.StateKey(Name + "/zone-spread")
+
+// zoneCounts are the members of a gang each zone hosts, including zones
+// hosting none, computed once per cycle.
+type zoneCounts map[string]int
+
+func (z zoneCounts) Clone() framework.StateData {
+	return z
+}
+
+// fewestMost returns the fewest and most members any zone hosts.
+func (z zoneCounts) fewestMost() (fewest, most int) {
+	first := true
+	for _, n := range z {
+		if first || n < fewest {
+			fewest = n
+		}
+		if first || n > most {
+			most = n
+		}
+		first = false
+	}
+	return fewest, most
+}
+
+// countZones records in state how many members of the gang each zone
+// hosts, bound or assumed, from the snapshot.
+func (cs *CustomScheduler) countZones(state *framework.CycleState, pod *v1.Pod, members []*v1.Pod) error {
+	if cs.zoneSpread == nil || state == nil {
+		return nil
+	}
+	isMember := make(map[string]bool, len(members))
+	for _, p := range members {
+		isMember[podKey(p)] = true
+	}
+	nodeInfos, err := cs.nodeInfos().List()
+	if err != nil {
+		return err
+	}
+	counts := zoneCounts{}
+	for _, ni := range nodeInfos {
+		zone, ok := ni.Node().Labels[cs.zoneSpread.TopologyKey]
+		if !ok {
+			continue
+		}
+		if _, ok := counts[zone]; !ok {
+			counts[zone] = 0
+		}
+		for _, pi := range ni.Pods {
+			if isMember[podKey(pi.Pod)] && podKey(pi.Pod) != podKey(pod) {
+				counts[zone]++
+			}
+		}
+	}
+	state.Write(zoneSpreadStateKey, counts)
+	return nil
+}
+
+func readZoneCounts(state *framework.CycleState) zoneCounts {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(zoneSpreadStateKey)
+	if err != nil {
+		return nil
+	}
+	return data.(zoneCounts)
+}
+
+// filterZoneSpread rejects, in Required mode, nodes whose zone would host
+// more than MaxSkew members more than the zone hosting the fewest, and
+// nodes outside every zone.
+func (cs *CustomScheduler) filterZoneSpread(state *framework.CycleState, nodeInfo *framework.NodeInfo) *framework.Status {
+	if cs.zoneSpread == nil || cs.zoneSpread.Mode != zoneSpreadRequired {
+		return nil
+	}
+	counts := readZoneCounts(state)
+	if counts == nil {
+		return nil
+	}
+	zone, ok := nodeInfo.Node().Labels[cs.zoneSpread.TopologyKey]
+	if !ok {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("node has no %s label to spread its pod group by", cs.zoneSpread.TopologyKey))
+	}
+	fewest, _ := counts.fewestMost()
+	if skew := counts[zone] + 1 - fewest; skew > cs.zoneSpread.MaxSkew {
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("zone %s would host %d more members of its pod group than another zone, more than the maximum skew of %d", zone, skew, cs.zoneSpread.MaxSkew))
+	}
+	return nil
+}
+
+// zoneSpreadBonus is the score bonus of node: the full weight in the zones
+// hosting the fewest members, down to none in those hosting the most.
+func (cs *CustomScheduler) zoneSpreadBonus(state *framework.CycleState, node *v1.Node) int64 {
+	counts := readZoneCounts(state)
+	zone, ok := node.Labels[cs.zoneSpread.TopologyKey]
+	if counts == nil || !ok {
+		return 0
+	}
+	fewest, most := counts.fewestMost()
+	if most == fewest {
+		return cs.zoneSpread.Weight
+	}
+	return cs.zoneSpread.Weight * int64(most-counts[zone]) / int64(most-fewest)
+}
@@ -0,0 +1,71 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"my-scheduler-plugins/pkg/fixtures"
+	plugintesting "my-scheduler-plugins/pkg/plugins/testing"
+)
+
+func TestCustomScheduler_ZoneSpread(t *testing.T) {
+	pods := fixtures.GroupSpec{Name: "g1", Namespace: "default", Size: 5, MinAvailable: 5}.Pods()
+	zoned := func(name, zone string, members ...*v1.Pod) *framework.NodeInfo {
+		ni := plugintesting.MakeNodeInfo(name, 4000, 8<<30, members...)
+		node := ni.Node()
+		node.Labels = map[string]string{v1.LabelTopologyZone: zone}
+		ni.SetNode(node)
+		return ni
+	}
+	// zone a hosts two members, b one and c none.
+	nodes := []*framework.NodeInfo{
+		zoned("a1", "a", pods[0], pods[1]),
+		zoned("b1", "b", pods[2]),
+		zoned("c1", "c"),
+	}
+	fwk, err := plugintesting.NewFramework(nodes, pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zoneSpread, err := newZoneSpreadArgs(ZoneSpreadArgs{Mode: zoneSpreadRequired, Weight: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &CustomScheduler{handle: fwk, scoreMode: leastMode, zoneSpread: zoneSpread}
+	state := framework.NewCycleState()
+	if _, status := cs.PreFilter(context.Background(), state, pods[3]); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+
+	wantFiltered := map[string]bool{"a1": true, "b1": true, "c1": false}
+	for _, ni := range nodes {
+		if status := cs.Filter(context.Background(), state, pods[3], ni); status.IsSuccess() == wantFiltered[ni.Node().Name] {
+			t.Errorf("node %s: expected filtered %v, got %v", ni.Node().Name, wantFiltered[ni.Node().Name], status)
+		}
+	}
+
+	var scores framework.NodeScoreList
+	for _, ni := range nodes {
+		scores = append(scores, framework.NodeScore{Name: ni.Node().Name})
+	}
+	if status := cs.NormalizeScore(context.Background(), state, pods[3], scores); !status.IsSuccess() {
+		t.Fatal(status)
+	}
+	// the neutral 50 plus 40 in the emptiest zone, 20 halfway, none in the fullest.
+	want := map[string]int64{"a1": 50, "b1": 70, "c1": 90}
+	for _, s := range scores {
+		if s.Score != want[s.Name] {
+			t.Errorf("expected %s to score %d, got %d", s.Name, want[s.Name], s.Score)
+		}
+	}
+}
+
+func TestNewZoneSpreadArgs(t *testing.T) {
+	for _, args := range []ZoneSpreadArgs{{MaxSkew: -1}, {Mode: "Sometimes"}, {Weight: 101}} {
+		if _, err := newZoneSpreadArgs(args); err == nil {
+			t.Errorf("expected %+v to be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package scorepolicy delegates Score decisions to a local gRPC policy
+// service, described by scorepolicy.proto, so placement policies can be
+// prototyped in any language without rebuilding the scheduler.
+package scorepolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const scoreMethod = "/nthu.scheduler.scorepolicy.v1.ScorePolicy/Score"
+
+// Config points the scheduler at a policy service.
+type Config struct {
+	// Address is the gRPC target of the service, e.g. "localhost:50051".
+	Address string `json:"address"`
+	// TimeoutMilliseconds bounds every call, 100 by default.
+	TimeoutMilliseconds int64 `json:"timeoutMilliseconds,omitempty"`
+}
+
+// Pod is the pod being scheduled.
+type Pod struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	MilliCPU  int64             `json:"milliCPU"`
+	Memory    int64             `json:"memory"`
+}
+
+// Node is a feasible node of the pod.
+type Node struct {
+	Name                string            `json:"name"`
+	Labels              map[string]string `json:"labels,omitempty"`
+	AllocatableMilliCPU int64             `json:"allocatableMilliCPU"`
+	AllocatableMemory   int64             `json:"allocatableMemory"`
+	RequestedMilliCPU   int64             `json:"requestedMilliCPU"`
+	RequestedMemory     int64             `json:"requestedMemory"`
+}
+
+// Request asks the service to score the nodes of one scheduling cycle.
+type Request struct {
+	Mode  string `json:"mode"`
+	Pod   Pod    `json:"pod"`
+	Nodes []Node `json:"nodes"`
+}
+
+// Response carries a score for every node of the request.
+type Response struct {
+	Scores map[string]float64 `json:"scores"`
+}
+
+// Client calls a policy service.
+type Client struct {
+	conn    grpc.ClientConnInterface
+	timeout time.Duration
+}
+
+// New connects to the service of cfg. The connection is established lazily,
+// so a service that is not up yet does not block scheduler startup.
+func New(cfg Config) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("score policy requires an address")
+	}
+	conn, err := grpc.Dial(cfg.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial score policy %s: %w", cfg.Address, err)
+	}
+	return NewForConn(conn, time.Duration(cfg.TimeoutMilliseconds)*time.Millisecond), nil
+}
+
+// NewForConn returns a Client on an existing connection; a non-positive
+// timeout selects the default.
+func NewForConn(conn grpc.ClientConnInterface, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = 100 * time.Millisecond
+	}
+	return &Client{conn: conn, timeout: timeout}
+}
+
+// Score returns the service's score of every node in req.
+func (c *Client) Score(ctx context.Context, req Request) (map[string]int64, error) {
+	in, err := toStruct(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	out := &structpb.Struct{}
+	if err := c.conn.Invoke(ctx, scoreMethod, in, out); err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	raw, err := out.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("invalid score policy response: %w", err)
+	}
+	scores := make(map[string]int64, len(req.Nodes))
+	for _, n := range req.Nodes {
+		score, ok := resp.Scores[n.Name]
+		if !ok || math.IsNaN(score) || math.IsInf(score, 0) {
+			return nil, fmt.Errorf("score policy returned no valid score for node %s", n.Name)
+		}
+		scores[n.Name] = int64(math.Round(score))
+	}
+	return scores, nil
+}
+
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := s.UnmarshalJSON(raw); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ScorePolicyServer is implemented by policy services written in Go.
+type ScorePolicyServer interface {
+	Score(ctx context.Context, req Request) (*Response, error)
+}
+
+// RegisterScorePolicyServer serves srv on s as the ScorePolicy service.
+func RegisterScorePolicyServer(s *grpc.Server, srv ScorePolicyServer) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "nthu.scheduler.scorepolicy.v1.ScorePolicy",
+		HandlerType: (*ScorePolicyServer)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Score",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := &structpb.Struct{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				raw, err := in.MarshalJSON()
+				if err != nil {
+					return nil, err
+				}
+				var req Request
+				if err := json.Unmarshal(raw, &req); err != nil {
+					return nil, err
+				}
+				resp, err := srv.(ScorePolicyServer).Score(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				return toStruct(resp)
+			},
+		}},
+		Metadata: "scorepolicy.proto",
+	}, srv)
+}
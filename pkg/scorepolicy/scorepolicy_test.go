@@ -0,0 +1,96 @@
+package scorepolicy
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type policyFunc func(ctx context.Context, req Request) (*Response, error)
+
+func (f policyFunc) Score(ctx context.Context, req Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+func newTestClient(t *testing.T, policy policyFunc, timeout time.Duration) *Client {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterScorePolicyServer(srv, policy)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewForConn(conn, timeout)
+}
+
+func TestClient_Score(t *testing.T) {
+	client := newTestClient(t, func(ctx context.Context, req Request) (*Response, error) {
+		scores := map[string]float64{}
+		for _, n := range req.Nodes {
+			scores[n.Name] = float64(n.AllocatableMemory-n.RequestedMemory-req.Pod.Memory) / 2
+		}
+		return &Response{Scores: scores}, nil
+	}, time.Second)
+
+	got, err := client.Score(context.Background(), Request{
+		Mode:  "Least",
+		Pod:   Pod{Name: "p", Memory: 10},
+		Nodes: []Node{{Name: "n1", AllocatableMemory: 100, RequestedMemory: 20}, {Name: "n2", AllocatableMemory: 50}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["n1"] != 35 || got["n2"] != 20 {
+		t.Errorf("unexpected scores %v", got)
+	}
+}
+
+func TestClient_ScoreFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  policyFunc
+		timeout time.Duration
+	}{
+		{
+			name: "missing node",
+			policy: func(ctx context.Context, req Request) (*Response, error) {
+				return &Response{Scores: map[string]float64{"n1": 1}}, nil
+			},
+			timeout: time.Second,
+		},
+		{
+			name: "timeout",
+			policy: func(ctx context.Context, req Request) (*Response, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			timeout: 10 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.policy, tt.timeout)
+			_, err := client.Score(context.Background(), Request{Nodes: []Node{{Name: "n1"}, {Name: "n2"}}})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Error("expected an error without an address")
+	}
+}
@@ -0,0 +1,272 @@
+// Package simulate runs the CustomScheduler plugin against nodes and pods
+// read from YAML, without a cluster, to preview how it would rank nodes and
+// admit gangs under a given configuration.
+package simulate
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"my-scheduler-plugins/pkg/plugins"
+	"sigs.k8s.io/yaml"
+)
+
+// Cluster is the nodes and pods a simulation runs against. Pods with a
+// nodeName are running there; the others are pending and get scheduled.
+type Cluster struct {
+	Nodes []*v1.Node
+	Pods  []*v1.Pod
+}
+
+// Load adds the Nodes, Pods and Lists of them in the multi-document YAML
+// or JSON stream r to the cluster. Other kinds are rejected.
+func (c *Cluster) Load(r io.Reader) error {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bufio.NewReader(r), 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode: %w", err)
+		}
+		if len(raw.Raw) == 0 {
+			continue
+		}
+		if err := c.add(raw.Raw); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Cluster) add(raw []byte) error {
+	var meta struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return fmt.Errorf("failed to decode: %w", err)
+	}
+	switch meta.Kind {
+	case "Node":
+		node := &v1.Node{}
+		if err := yaml.UnmarshalStrict(raw, node); err != nil {
+			return fmt.Errorf("failed to decode node: %w", err)
+		}
+		c.Nodes = append(c.Nodes, node)
+	case "Pod":
+		pod := &v1.Pod{}
+		if err := yaml.UnmarshalStrict(raw, pod); err != nil {
+			return fmt.Errorf("failed to decode pod: %w", err)
+		}
+		if pod.Namespace == "" {
+			pod.Namespace = "default"
+		}
+		c.Pods = append(c.Pods, pod)
+	case "List", "NodeList", "PodList":
+		for _, item := range meta.Items {
+			if err := c.add(item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported kind %q, want Node, Pod or a List of them", meta.Kind)
+	}
+	return nil
+}
+
+// NodeScore is the normalized score of a node for a pod.
+type NodeScore struct {
+	Node  string `json:"node"`
+	Score int64  `json:"score"`
+}
+
+// Decision is what the plugin made of a pending pod.
+type Decision struct {
+	Pod string `json:"pod"`
+	// Admitted is whether the pod passed PreFilter, where gangs short of
+	// minAvailable members are held back.
+	Admitted bool `json:"admitted"`
+	// Reason is why the pod was not admitted, or why no node fits it.
+	Reason string `json:"reason,omitempty"`
+	// Ranking lists the nodes that passed Filter, best first.
+	Ranking []NodeScore `json:"ranking,omitempty"`
+	// Filtered are the nodes Filter rejected, with why.
+	Filtered map[string]string `json:"filtered,omitempty"`
+}
+
+type sharedLister struct {
+	nodes []*framework.NodeInfo
+}
+
+func (s *sharedLister) NodeInfos() framework.NodeInfoLister {
+	return fakeframework.NodeInfoLister(s.nodes)
+}
+
+func (s *sharedLister) StorageInfos() framework.StorageInfoLister {
+	return nil
+}
+
+// Run creates the plugin with args, the JSON or YAML of its pluginConfig
+// args, and runs every pending pod of c through PreFilter, Filter, Score
+// and NormalizeScore. Each pod is decided against c as given: earlier
+// decisions do not place pods on nodes.
+func Run(c *Cluster, args []byte) ([]Decision, error) {
+	nodes := make([]*framework.NodeInfo, 0, len(c.Nodes))
+	byName := map[string]*framework.NodeInfo{}
+	for _, n := range c.Nodes {
+		ni := framework.NewNodeInfo()
+		ni.SetNode(n)
+		nodes = append(nodes, ni)
+		byName[n.Name] = ni
+	}
+	var pending []*v1.Pod
+	for _, p := range c.Pods {
+		if p.Spec.NodeName == "" {
+			pending = append(pending, p)
+			continue
+		}
+		ni, ok := byName[p.Spec.NodeName]
+		if !ok {
+			return nil, fmt.Errorf("pod %s/%s runs on unknown node %s", p.Namespace, p.Name, p.Spec.NodeName)
+		}
+		ni.AddPod(p)
+	}
+
+	client := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"simulate",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(&sharedLister{nodes: nodes}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create framework: %w", err)
+	}
+	if len(args) == 0 {
+		args = []byte(`{"mode": "Least"}`)
+	}
+	if args, err = yaml.YAMLToJSON(args); err != nil {
+		return nil, fmt.Errorf("failed to decode args: %w", err)
+	}
+	p, err := plugins.New(&runtime.Unknown{Raw: args, ContentType: runtime.ContentTypeJSON}, fh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plugin: %w", err)
+	}
+	cs := p.(*plugins.CustomScheduler)
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+	store := informerFactory.Core().V1().Pods().Informer().GetStore()
+	for _, p := range c.Pods {
+		if err := store.Add(p); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	var decisions []Decision
+	for _, pod := range pending {
+		d := Decision{Pod: pod.Namespace + "/" + pod.Name}
+		state := framework.NewCycleState()
+		if _, status := cs.PreFilter(ctx, state, pod); !status.IsSuccess() {
+			d.Reason = status.Message()
+			decisions = append(decisions, d)
+			continue
+		}
+		d.Admitted = true
+		var feasible []*v1.Node
+		for _, ni := range nodes {
+			if status := cs.Filter(ctx, state, pod, ni); !status.IsSuccess() {
+				if d.Filtered == nil {
+					d.Filtered = map[string]string{}
+				}
+				d.Filtered[ni.Node().Name] = status.Message()
+				continue
+			}
+			feasible = append(feasible, ni.Node())
+		}
+		if len(feasible) == 0 {
+			d.Reason = "no node passed Filter"
+			decisions = append(decisions, d)
+			continue
+		}
+		if status := cs.PreScore(ctx, state, pod, feasible); !status.IsSuccess() {
+			return nil, fmt.Errorf("pod %s: %w", d.Pod, status.AsError())
+		}
+		scores := make(framework.NodeScoreList, 0, len(feasible))
+		for _, n := range feasible {
+			score, status := cs.Score(ctx, state, pod, n.Name)
+			if !status.IsSuccess() {
+				return nil, fmt.Errorf("pod %s: %w", d.Pod, status.AsError())
+			}
+			scores = append(scores, framework.NodeScore{Name: n.Name, Score: score})
+		}
+		if status := cs.NormalizeScore(ctx, state, pod, scores); !status.IsSuccess() {
+			return nil, fmt.Errorf("pod %s: %w", d.Pod, status.AsError())
+		}
+		sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+		for _, s := range scores {
+			d.Ranking = append(d.Ranking, NodeScore{Node: s.Name, Score: s.Score})
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, nil
+}
+
+// WriteText writes decisions for people: each pod with its ranked nodes.
+func WriteText(w io.Writer, decisions []Decision) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, d := range decisions {
+		switch {
+		case !d.Admitted:
+			fmt.Fprintf(tw, "%s\trejected: %s\n", d.Pod, d.Reason)
+		case len(d.Ranking) == 0:
+			fmt.Fprintf(tw, "%s\tunschedulable: %s\n", d.Pod, d.Reason)
+		default:
+			fmt.Fprintf(tw, "%s\tadmitted\n", d.Pod)
+		}
+		for i, s := range d.Ranking {
+			fmt.Fprintf(tw, "\t%d. %s\t%d\n", i+1, s.Node, s.Score)
+		}
+		filtered := make([]string, 0, len(d.Filtered))
+		for node := range d.Filtered {
+			filtered = append(filtered, node)
+		}
+		sort.Strings(filtered)
+		for _, node := range filtered {
+			fmt.Fprintf(tw, "\t-  %s\tfiltered: %s\n", node, d.Filtered[node])
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes decisions as an indented JSON array.
+func WriteJSON(w io.Writer, decisions []Decision) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(decisions)
+}
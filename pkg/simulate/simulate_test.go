@@ -0,0 +1,99 @@
+package simulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const clusterYAML = `
+apiVersion: v1
+kind: Node
+metadata:
+  name: small
+status:
+  allocatable: {cpu: "2", memory: 4Gi, pods: "110"}
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: large
+status:
+  allocatable: {cpu: "8", memory: 32Gi, pods: "110"}
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: train-0
+    labels: {podGroup: train, minAvailable: "2"}
+  spec:
+    containers: [{name: c, image: busybox}]
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: train-1
+    labels: {podGroup: train, minAvailable: "2"}
+  spec:
+    containers: [{name: c, image: busybox}]
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: lonely-0
+    labels: {podGroup: lonely, minAvailable: "3"}
+  spec:
+    containers: [{name: c, image: busybox}]
+`
+
+func TestRun(t *testing.T) {
+	cluster := &Cluster{}
+	if err := cluster.Load(strings.NewReader(clusterYAML)); err != nil {
+		t.Fatal(err)
+	}
+	if len(cluster.Nodes) != 2 || len(cluster.Pods) != 3 {
+		t.Fatalf("expected 2 nodes and 3 pods, got %d and %d", len(cluster.Nodes), len(cluster.Pods))
+	}
+
+	for _, tt := range []struct {
+		mode string
+		best string
+	}{
+		{mode: "Least", best: "small"},
+		{mode: "Most", best: "large"},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			decisions, err := Run(cluster, []byte("mode: "+tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decisions) != 3 {
+				t.Fatalf("expected 3 decisions, got %+v", decisions)
+			}
+			for _, d := range decisions[:2] {
+				if !d.Admitted || len(d.Ranking) != 2 || d.Ranking[0].Node != tt.best {
+					t.Errorf("expected %s admitted with %s ranked first, got %+v", d.Pod, tt.best, d)
+				}
+			}
+			if d := decisions[2]; d.Admitted || !strings.Contains(d.Reason, "lonely") {
+				t.Errorf("expected default/lonely-0 rejected for its pod group, got %+v", d)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteText(&buf, decisions); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), "1. "+tt.best) {
+				t.Errorf("expected %s ranked first in:\n%s", tt.best, buf.String())
+			}
+		})
+	}
+}
+
+func TestLoad_UnsupportedKind(t *testing.T) {
+	err := (&Cluster{}).Load(strings.NewReader("apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: d}\n"))
+	if err == nil || !strings.Contains(err.Error(), `unsupported kind "Deployment"`) {
+		t.Errorf("expected an unsupported kind error, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package throughput measures how fast the CustomScheduler plugin schedules
+// pods across score modes and synthetic cluster shapes.
+package throughput
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakeframework "k8s.io/kubernetes/pkg/scheduler/framework/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"my-scheduler-plugins/pkg/fixtures"
+	"my-scheduler-plugins/pkg/plugins"
+)
+
+// NodeSize is the allocatable capacity of a node.
+type NodeSize struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// Shape is a synthetic cluster. Node i gets Sizes[i % len(Sizes)].
+type Shape struct {
+	Name  string     `json:"name"`
+	Nodes int        `json:"nodes"`
+	Sizes []NodeSize `json:"sizes"`
+}
+
+// Shapes are the cluster shapes compared by default.
+var Shapes = []Shape{
+	{Name: "many-small", Nodes: 500, Sizes: []NodeSize{{CPU: "2", Memory: "4Gi"}}},
+	{Name: "few-huge", Nodes: 10, Sizes: []NodeSize{{CPU: "96", Memory: "768Gi"}}},
+	{Name: "heterogeneous", Nodes: 100, Sizes: []NodeSize{
+		{CPU: "2", Memory: "4Gi"},
+		{CPU: "8", Memory: "32Gi"},
+		{CPU: "32", Memory: "128Gi"},
+		{CPU: "96", Memory: "768Gi"},
+	}},
+}
+
+// Result is the outcome of scheduling one workload on one shape with one mode.
+type Result struct {
+	Shape         string        `json:"shape"`
+	Mode          string        `json:"mode"`
+	Pods          int           `json:"pods"`
+	Scheduled     int           `json:"scheduled"`
+	Duration      time.Duration `json:"duration"`
+	PodsPerSecond float64       `json:"podsPerSecond"`
+}
+
+type sharedLister struct {
+	nodes []*framework.NodeInfo
+}
+
+func (s *sharedLister) NodeInfos() framework.NodeInfoLister {
+	return fakeframework.NodeInfoLister(s.nodes)
+}
+
+func (s *sharedLister) StorageInfos() framework.StorageInfoLister {
+	return nil
+}
+
+func (s Shape) nodeInfos() []*framework.NodeInfo {
+	var nodes []*framework.NodeInfo
+	for i := 0; i < s.Nodes; i++ {
+		size := s.Sizes[i%len(s.Sizes)]
+		capacity := v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(size.CPU),
+			v1.ResourceMemory: resource.MustParse(size.Memory),
+			v1.ResourcePods:   resource.MustParse("110"),
+		}
+		ni := framework.NewNodeInfo()
+		ni.SetNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", s.Name, i)},
+			Status:     v1.NodeStatus{Capacity: capacity, Allocatable: capacity},
+		})
+		nodes = append(nodes, ni)
+	}
+	return nodes
+}
+
+// Run runs every pod of groups through PreFilter, Filter, Score and
+// NormalizeScore, places it on the top-scoring node it fits, so later pods
+// see it there, and reports how many pods per second were assigned a node.
+func Run(shape Shape, mode string, groups []fixtures.GroupSpec) (Result, error) {
+	nodes := shape.nodeInfos()
+	client := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	fh, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		},
+		"throughput",
+		wait.NeverStop,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(&sharedLister{nodes: nodes}),
+	)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create framework: %w", err)
+	}
+	args, err := json.Marshal(map[string]string{"mode": mode})
+	if err != nil {
+		return Result{}, err
+	}
+	p, err := plugins.New(&runtime.Unknown{Raw: args}, fh)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create plugin: %w", err)
+	}
+	cs := p.(*plugins.CustomScheduler)
+	// like kube-scheduler, start the informers the plugin registered and wait for them.
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+
+	var pods []*v1.Pod
+	store := informerFactory.Core().V1().Pods().Informer().GetStore()
+	for _, g := range groups {
+		for _, pod := range g.Pods() {
+			store.Add(pod)
+			pods = append(pods, pod)
+		}
+	}
+
+	ctx := context.Background()
+	result := Result{Shape: shape.Name, Mode: mode, Pods: len(pods)}
+	start := time.Now()
+	for _, pod := range pods {
+		state := framework.NewCycleState()
+		if _, status := cs.PreFilter(ctx, state, pod); !status.IsSuccess() {
+			continue
+		}
+		feasible := make([]*framework.NodeInfo, 0, len(nodes))
+		for _, n := range nodes {
+			if len(noderesources.Fits(pod, n)) == 0 && cs.Filter(ctx, state, pod, n).IsSuccess() {
+				feasible = append(feasible, n)
+			}
+		}
+		if len(feasible) == 0 {
+			continue
+		}
+		scores := make(framework.NodeScoreList, 0, len(feasible))
+		for _, n := range feasible {
+			score, status := cs.Score(ctx, state, pod, n.Node().Name)
+			if !status.IsSuccess() {
+				return Result{}, status.AsError()
+			}
+			scores = append(scores, framework.NodeScore{Name: n.Node().Name, Score: score})
+		}
+		if status := cs.NormalizeScore(ctx, state, pod, scores); !status.IsSuccess() {
+			return Result{}, status.AsError()
+		}
+		best := 0
+		for i := range scores {
+			if scores[i].Score > scores[best].Score {
+				best = i
+			}
+		}
+		placed := pod.DeepCopy()
+		placed.Spec.NodeName = scores[best].Name
+		feasible[best].AddPod(placed)
+		result.Scheduled++
+	}
+	result.Duration = time.Since(start)
+	if result.Duration > 0 {
+		result.PodsPerSecond = float64(result.Scheduled) / result.Duration.Seconds()
+	}
+	return result, nil
+}
+
+// WriteCSV writes results as CSV with a header row.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"shape", "mode", "pods", "scheduled", "duration_ms", "pods_per_second"})
+	for _, r := range results {
+		cw.Write([]string{
+			r.Shape,
+			r.Mode,
+			strconv.Itoa(r.Pods),
+			strconv.Itoa(r.Scheduled),
+			strconv.FormatInt(r.Duration.Milliseconds(), 10),
+			strconv.FormatFloat(r.PodsPerSecond, 'f', 1, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
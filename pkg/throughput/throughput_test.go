@@ -0,0 +1,59 @@
+package throughput
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+func TestRun(t *testing.T) {
+	groups := fixtures.Generate(fixtures.Options{Seed: 1, Groups: 5, MinSize: 2, MaxSize: 4})
+	shape := Shape{Name: "tiny", Nodes: 3, Sizes: []NodeSize{{CPU: "16", Memory: "32Gi"}, {CPU: "32", Memory: "64Gi"}}}
+
+	var results []Result
+	for _, mode := range []string{"Least", "Most"} {
+		r, err := Run(shape, mode, groups)
+		if err != nil {
+			t.Fatalf("mode %s: %v", mode, err)
+		}
+		if r.Scheduled != r.Pods {
+			t.Errorf("mode %s: expected all %d pods scheduled, got %d", mode, r.Pods, r.Scheduled)
+		}
+		results = append(results, r)
+	}
+
+	// the first pod, of the large shape, fills the only node.
+	full := Shape{Name: "full", Nodes: 1, Sizes: []NodeSize{{CPU: "2", Memory: "4Gi"}}}
+	r, err := Run(full, "Least", groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Scheduled != 1 {
+		t.Errorf("expected 1 pod scheduled on a full node, got %d", r.Scheduled)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Errorf("expected header and 2 rows, got %d lines:\n%s", lines, buf.String())
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	groups := fixtures.Generate(fixtures.Options{Seed: 1, Groups: 20, MinSize: 2, MaxSize: 8})
+	for _, shape := range Shapes {
+		for _, mode := range []string{"Least", "Most"} {
+			b.Run(shape.Name+"/"+mode, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := Run(shape, mode, groups); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/rest"
+)
+
+// customMetricsProvider reads node usage from the custom.metrics.k8s.io API.
+type customMetricsProvider struct {
+	client       rest.Interface
+	cpuMetric    string
+	memoryMetric string
+	timeout      time.Duration
+}
+
+// metricValueList is the subset of custom.metrics.k8s.io/v1beta2 MetricValueList used here.
+type metricValueList struct {
+	Items []struct {
+		Timestamp time.Time         `json:"timestamp"`
+		Value     resource.Quantity `json:"value"`
+	} `json:"items"`
+}
+
+func (p *customMetricsProvider) NodeUsage(ctx context.Context, nodeName string) (*NodeUsage, error) {
+	cpu, ts, err := p.metric(ctx, nodeName, p.cpuMetric)
+	if err != nil {
+		return nil, err
+	}
+	memory, _, err := p.metric(ctx, nodeName, p.memoryMetric)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeUsage{MilliCPU: cpu.MilliValue(), Memory: memory.Value(), Timestamp: ts}, nil
+}
+
+func (p *customMetricsProvider) metric(ctx context.Context, nodeName, metric string) (resource.Quantity, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	raw, err := p.client.Get().AbsPath("/apis/custom.metrics.k8s.io/v1beta2/nodes", nodeName, metric).DoRaw(ctx)
+	if err != nil {
+		return resource.Quantity{}, time.Time{}, fmt.Errorf("failed to get metric %s of node %s: %w", metric, nodeName, err)
+	}
+	var list metricValueList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return resource.Quantity{}, time.Time{}, fmt.Errorf("failed to decode metric %s of node %s: %w", metric, nodeName, err)
+	}
+	if len(list.Items) == 0 {
+		return resource.Quantity{}, time.Time{}, fmt.Errorf("no value for metric %s of node %s", metric, nodeName)
+	}
+	return list.Items[0].Value, list.Items[0].Timestamp, nil
+}
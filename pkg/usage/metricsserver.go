@@ -0,0 +1,41 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// metricsServerProvider reads node usage from the metrics.k8s.io API.
+type metricsServerProvider struct {
+	client  rest.Interface
+	timeout time.Duration
+}
+
+// nodeMetrics is the subset of metrics.k8s.io/v1beta1 NodeMetrics used here.
+type nodeMetrics struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Usage     v1.ResourceList `json:"usage"`
+}
+
+func (p *metricsServerProvider) NodeUsage(ctx context.Context, nodeName string) (*NodeUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	raw, err := p.client.Get().AbsPath("/apis/metrics.k8s.io/v1beta1/nodes", nodeName).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics of node %s: %w", nodeName, err)
+	}
+	var m nodeMetrics
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode metrics of node %s: %w", nodeName, err)
+	}
+	return &NodeUsage{
+		MilliCPU:  m.Usage.Cpu().MilliValue(),
+		Memory:    m.Usage.Memory().Value(),
+		Timestamp: m.Timestamp,
+	}, nil
+}
@@ -0,0 +1,84 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// the default queries read the root cgroup of cAdvisor as scraped from the kubelet.
+	defaultPrometheusCPUQuery    = `sum(rate(container_cpu_usage_seconds_total{id="/",node="%s"}[5m]))`
+	defaultPrometheusMemoryQuery = `sum(container_memory_working_set_bytes{id="/",node="%s"})`
+)
+
+// prometheusProvider reads node usage from the Prometheus HTTP query API.
+type prometheusProvider struct {
+	address     string
+	cpuQuery    string
+	memoryQuery string
+	client      *http.Client
+}
+
+// queryResponse is the subset of a Prometheus instant query response used here.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			// Value is a [unix timestamp, "value"] pair.
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (p *prometheusProvider) NodeUsage(ctx context.Context, nodeName string) (*NodeUsage, error) {
+	cores, ts, err := p.query(ctx, fmt.Sprintf(p.cpuQuery, nodeName))
+	if err != nil {
+		return nil, err
+	}
+	memory, _, err := p.query(ctx, fmt.Sprintf(p.memoryQuery, nodeName))
+	if err != nil {
+		return nil, err
+	}
+	return &NodeUsage{MilliCPU: int64(cores * 1000), Memory: int64(memory), Timestamp: ts}, nil
+}
+
+// query runs an instant query that must return a single sample.
+func (p *prometheusProvider) query(ctx context.Context, query string) (float64, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/api/v1/query?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var r queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to decode prometheus response: %w", err)
+	}
+	if r.Status != "success" {
+		return 0, time.Time{}, fmt.Errorf("prometheus query %q failed: %s", query, r.Error)
+	}
+	if len(r.Data.Result) != 1 {
+		return 0, time.Time{}, fmt.Errorf("prometheus query %q returned %d samples, expected 1", query, len(r.Data.Result))
+	}
+	ts, ok := r.Data.Result[0].Value[0].(float64)
+	s, ok2 := r.Data.Result[0].Value[1].(string)
+	if !ok || !ok2 {
+		return 0, time.Time{}, fmt.Errorf("prometheus query %q returned a malformed sample", query)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("prometheus query %q returned a malformed value: %w", query, err)
+	}
+	sec := int64(ts)
+	return v, time.Unix(sec, int64((ts-float64(sec))*1e9)), nil
+}
@@ -0,0 +1,86 @@
+// Package usage reports the observed resource usage of nodes from pluggable
+// telemetry backends, for usage-based scoring and filtering.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// Provider types accepted in Config.Type.
+const (
+	MetricsServer = "metrics-server"
+	Prometheus    = "prometheus"
+	CustomMetrics = "custom-metrics"
+)
+
+// NodeUsage is the observed resource usage of a node.
+type NodeUsage struct {
+	MilliCPU  int64
+	Memory    int64
+	Timestamp time.Time
+}
+
+// MetricsProvider reports the observed resource usage of nodes.
+type MetricsProvider interface {
+	NodeUsage(ctx context.Context, nodeName string) (*NodeUsage, error)
+}
+
+// Config selects and configures a MetricsProvider.
+type Config struct {
+	// Type is one of metrics-server, prometheus or custom-metrics.
+	Type string `json:"type"`
+	// Address is the base URL of the Prometheus server.
+	Address string `json:"address,omitempty"`
+	// CPUQuery and MemoryQuery are PromQL expressions returning a node's CPU
+	// usage in cores and memory usage in bytes; %s is replaced by the node name.
+	CPUQuery    string `json:"cpuQuery,omitempty"`
+	MemoryQuery string `json:"memoryQuery,omitempty"`
+	// CPUMetric and MemoryMetric are the custom metrics API metric names for
+	// a node's CPU usage in cores and memory usage in bytes.
+	CPUMetric    string `json:"cpuMetric,omitempty"`
+	MemoryMetric string `json:"memoryMetric,omitempty"`
+	// TimeoutSeconds bounds every query, 5 by default.
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// New returns the MetricsProvider selected by cfg. client talks to the API
+// server and is used by the metrics-server and custom-metrics providers.
+func New(cfg Config, client rest.Interface) (MetricsProvider, error) {
+	timeout := 5 * time.Second
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	switch cfg.Type {
+	case MetricsServer:
+		return &metricsServerProvider{client: client, timeout: timeout}, nil
+	case Prometheus:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("prometheus metrics provider requires an address")
+		}
+		p := &prometheusProvider{
+			address:     cfg.Address,
+			cpuQuery:    cfg.CPUQuery,
+			memoryQuery: cfg.MemoryQuery,
+			client:      &http.Client{Timeout: timeout},
+		}
+		if p.cpuQuery == "" {
+			p.cpuQuery = defaultPrometheusCPUQuery
+		}
+		if p.memoryQuery == "" {
+			p.memoryQuery = defaultPrometheusMemoryQuery
+		}
+		return p, nil
+	case CustomMetrics:
+		if cfg.CPUMetric == "" || cfg.MemoryMetric == "" {
+			return nil, fmt.Errorf("custom-metrics provider requires cpuMetric and memoryMetric")
+		}
+		return &customMetricsProvider{client: client, cpuMetric: cfg.CPUMetric, memoryMetric: cfg.MemoryMetric, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown metrics provider type %q", cfg.Type)
+	}
+}
@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apis/metrics.k8s.io/v1beta1/nodes/n1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"timestamp":"2023-05-01T00:00:00Z","usage":{"cpu":"1500m","memory":"2Gi"}}`)
+	})
+	mux.HandleFunc("/apis/custom.metrics.k8s.io/v1beta2/nodes/n1/node_cpu", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"timestamp":"2023-05-01T00:00:00Z","value":"1500m"}]}`)
+	})
+	mux.HandleFunc("/apis/custom.metrics.k8s.io/v1beta2/nodes/n1/node_memory", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"timestamp":"2023-05-01T00:00:00Z","value":"2Gi"}]}`)
+	})
+	mux.HandleFunc("/api/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("query") {
+		case "cpu{node=\"n1\"}":
+			fmt.Fprint(w, `{"status":"success","data":{"result":[{"value":[1682899200,"1.5"]}]}}`)
+		case "memory{node=\"n1\"}":
+			fmt.Fprint(w, `{"status":"success","data":{"result":[{"value":[1682899200,"2147483648"]}]}}`)
+		default:
+			fmt.Fprint(w, `{"status":"success","data":{"result":[]}}`)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProviders(t *testing.T) {
+	srv := newTestServer(t)
+	client := kubernetes.NewForConfigOrDie(&rest.Config{Host: srv.URL}).Discovery().RESTClient()
+
+	configs := []Config{
+		{Type: MetricsServer},
+		{Type: Prometheus, Address: srv.URL, CPUQuery: `cpu{node="%s"}`, MemoryQuery: `memory{node="%s"}`},
+		{Type: CustomMetrics, CPUMetric: "node_cpu", MemoryMetric: "node_memory"},
+	}
+	for _, cfg := range configs {
+		t.Run(cfg.Type, func(t *testing.T) {
+			p, err := New(cfg, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.NodeUsage(context.Background(), "n1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.MilliCPU != 1500 || got.Memory != 2*1024*1024*1024 || got.Timestamp.Unix() != 1682899200 {
+				t.Errorf("unexpected usage %+v", got)
+			}
+			if _, err := p.NodeUsage(context.Background(), "missing"); err == nil {
+				t.Errorf("expected an error for a node without metrics")
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, cfg := range []Config{{Type: "statsd"}, {Type: Prometheus}, {Type: CustomMetrics}} {
+		if _, err := New(cfg, nil); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package webhook serves a mutating admission webhook that defaults and
+// validates the gang labels of pods, so that pods with a malformed group
+// are rejected when they are created rather than left pending by the
+// scheduler.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"my-scheduler-plugins/pkg/fixtures"
+	"my-scheduler-plugins/pkg/plugins"
+)
+
+// Server defaults the minAvailable label of pods in a labelled gang from a
+// PodGroup of the same name or the controller of the pod, and rejects pods
+// whose gang labels the scheduler would reject.
+type Server struct {
+	client kubernetes.Interface
+	// dynamicClient reads PodGroups; without it, they are not looked up.
+	dynamicClient dynamic.Interface
+}
+
+// NewServer returns a Server reading controllers through client and
+// PodGroups through dynamicClient, if set.
+func NewServer(client kubernetes.Interface, dynamicClient dynamic.Interface) *Server {
+	return &Server{client: client, dynamicClient: dynamicClient}
+}
+
+// Handler returns the HTTP handler serving AdmissionReviews on /mutate.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", s.serve)
+	return mux
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "missing request in admission review", http.StatusBadRequest)
+		return
+	}
+	review.Response = s.Admit(r.Context(), review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.ErrorS(err, "Failed to encode admission review")
+	}
+}
+
+// patchOperation is a JSON patch operation.
+type patchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// Admit admits a pod, defaulting a missing minAvailable, or rejects it if
+// its gang labels are invalid. Other objects and pods without a podGroup
+// label are admitted unchanged.
+func (s *Server) Admit(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Resource.Resource != "pods" || req.SubResource != "" {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	pod := &v1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return deny(http.StatusBadRequest, fmt.Sprintf("failed to decode pod: %v", err))
+	}
+	name, ok := pod.Labels[fixtures.GroupNameLabel]
+	if !ok {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	// the namespace of a pod being created may only be in the request.
+	if pod.Namespace == "" {
+		pod.Namespace = req.Namespace
+	}
+
+	var patch []patchOperation
+	_, hasLabel := pod.Labels[fixtures.MinAvailableLabel]
+	_, hasAnnotation := pod.Annotations[fixtures.MinAvailableLabel]
+	if !hasLabel && !hasAnnotation && req.Operation == admissionv1.Create {
+		minAvailable, err := s.defaultMinAvailable(ctx, pod, name)
+		if err != nil {
+			return deny(http.StatusInternalServerError, err.Error())
+		}
+		if minAvailable > 0 {
+			value := strconv.FormatInt(minAvailable, 10)
+			pod.Labels[fixtures.MinAvailableLabel] = value
+			patch = append(patch, patchOperation{Op: "add", Path: "/metadata/labels/" + fixtures.MinAvailableLabel, Value: value})
+		}
+	}
+	if _, ok := pod.Labels[fixtures.MinAvailableLabel]; !ok && !hasAnnotation {
+		return deny(http.StatusUnprocessableEntity, fmt.Sprintf("pod of pod group %s has no %s label, and neither a PodGroup nor a controller to default it from", name, fixtures.MinAvailableLabel))
+	}
+	if err := plugins.ValidateGroupLabels(pod); err != nil {
+		return deny(http.StatusUnprocessableEntity, err.Error())
+	}
+	if len(patch) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return deny(http.StatusInternalServerError, fmt.Sprintf("failed to encode patch: %v", err))
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{Allowed: true, Patch: raw, PatchType: &patchType}
+}
+
+// defaultMinAvailable returns the minAvailable of the gang name of pod: the
+// minMember of the PodGroup of the same name or else the replicas (or, for
+// a Job, the parallelism) of the controller of pod, or 0 if there is
+// neither.
+func (s *Server) defaultMinAvailable(ctx context.Context, pod *v1.Pod, name string) (int64, error) {
+	if s.dynamicClient != nil {
+		pg, err := s.dynamicClient.Resource(plugins.PodGroupGVR).Namespace(pod.Namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			minMember, err := plugins.PodGroupMinMember(pg)
+			if err != nil {
+				return 0, fmt.Errorf("invalid minMember in PodGroup %s/%s: %v", pod.Namespace, name, err)
+			}
+			return minMember, nil
+		case !apierrors.IsNotFound(err):
+			return 0, fmt.Errorf("failed to get PodGroup %s/%s: %v", pod.Namespace, name, err)
+		}
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return 0, nil
+	}
+	replicas, err := s.controllerReplicas(ctx, pod.Namespace, owner)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s %s/%s: %v", owner.Kind, pod.Namespace, owner.Name, err)
+	}
+	return replicas, nil
+}
+
+// controllerReplicas returns how many pods the controller owner runs at
+// once, 0 for kinds it does not know.
+func (s *Server) controllerReplicas(ctx context.Context, namespace string, owner *metav1.OwnerReference) (int64, error) {
+	switch owner.Kind {
+	case "ReplicaSet":
+		rs, err := s.client.AppsV1().ReplicaSets(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return int64(valueOr(rs.Spec.Replicas, 1)), nil
+	case "StatefulSet":
+		sts, err := s.client.AppsV1().StatefulSets(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return int64(valueOr(sts.Spec.Replicas, 1)), nil
+	case "Job":
+		job, err := s.client.BatchV1().Jobs(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return int64(valueOr(job.Spec.Parallelism, 1)), nil
+	case "ReplicationController":
+		rc, err := s.client.CoreV1().ReplicationControllers(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return int64(valueOr(rc.Spec.Replicas, 1)), nil
+	}
+	return 0, nil
+}
+
+// valueOr returns *p, or def if p is nil, the API default of replicas and
+// parallelism.
+func valueOr(p *int32, def int32) int32 {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// deny rejects a request with msg, as invalid or, for codes of 500 and
+// above, as an internal error.
+func deny(code int32, msg string) *admissionv1.AdmissionResponse {
+	reason := metav1.StatusReasonInvalid
+	if code >= http.StatusInternalServerError {
+		reason = metav1.StatusReasonInternalError
+	}
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result:  &metav1.Status{Status: metav1.StatusFailure, Code: code, Reason: reason, Message: msg},
+	}
+}
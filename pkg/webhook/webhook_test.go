@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"my-scheduler-plugins/pkg/plugins"
+)
+
+func makePod(labels map[string]string, owner *metav1.OwnerReference) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Labels: labels}}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func podRequest(t *testing.T, pod *v1.Pod) *admissionv1.AdmissionRequest {
+	t.Helper()
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+		Namespace: "default",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func newServer() *Server {
+	replicas := int32(4)
+	client := clientsetfake.NewSimpleClientset(&appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rs"},
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &replicas},
+	})
+	pg := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "nthu.scheduler/v1alpha1",
+		"kind":       "PodGroup",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "from-crd"},
+		"spec":       map[string]interface{}{"minMember": "50%", "replicas": int64(6)},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{plugins.PodGroupGVR: "PodGroupList"}, pg)
+	return NewServer(client, dynamicClient)
+}
+
+func TestServer_Admit(t *testing.T) {
+	controller := true
+	rs := &metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", Controller: &controller}
+	tests := []struct {
+		name        string
+		pod         *v1.Pod
+		wantAllowed bool
+		// wantDefault is the minAvailable the patch sets, if any.
+		wantDefault string
+	}{
+		{name: "ungrouped", pod: makePod(nil, nil), wantAllowed: true},
+		{name: "valid labels", pod: makePod(map[string]string{"podGroup": "g1", "minAvailable": "3"}, nil), wantAllowed: true},
+		{name: "not an integer", pod: makePod(map[string]string{"podGroup": "g1", "minAvailable": "three"}, nil)},
+		{name: "zero", pod: makePod(map[string]string{"podGroup": "g1", "minAvailable": "0"}, nil)},
+		{name: "missing without a default", pod: makePod(map[string]string{"podGroup": "g1"}, nil)},
+		{name: "defaulted from the PodGroup", pod: makePod(map[string]string{"podGroup": "from-crd"}, nil), wantAllowed: true, wantDefault: "3"},
+		{name: "defaulted from the controller", pod: makePod(map[string]string{"podGroup": "g1"}, rs), wantAllowed: true, wantDefault: "4"},
+	}
+	s := newServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := s.Admit(context.Background(), podRequest(t, tt.pod))
+			if resp.Allowed != tt.wantAllowed {
+				t.Fatalf("expected allowed %v, got %+v", tt.wantAllowed, resp.Result)
+			}
+			var patch []patchOperation
+			if resp.Patch != nil {
+				if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+					t.Fatal(err)
+				}
+			}
+			switch {
+			case tt.wantDefault == "" && len(patch) != 0:
+				t.Errorf("expected no patch, got %+v", patch)
+			case tt.wantDefault != "" && (len(patch) != 1 || patch[0].Path != "/metadata/labels/minAvailable" || patch[0].Value != tt.wantDefault):
+				t.Errorf("expected minAvailable defaulted to %s, got %+v", tt.wantDefault, patch)
+			}
+		})
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	srv := httptest.NewServer(newServer().Handler())
+	defer srv.Close()
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  podRequest(t, makePod(map[string]string{"podGroup": "g1"}, nil)),
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(srv.URL+"/mutate", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got admissionv1.AdmissionReview
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Response == nil || got.Response.UID != "uid" || got.Response.Allowed {
+		t.Errorf("expected the pod without minAvailable to be denied, got %+v", got.Response)
+	}
+}
@@ -0,0 +1,155 @@
+//go:build e2e
+
+// Package e2e runs the scheduler with the CustomScheduler plugin inside a kind
+// cluster and checks gang admission end to end.
+//
+// Run it with `make e2e`. Set E2E_KUBECONFIG to reuse an existing cluster that
+// already runs the scheduler instead of creating one.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"my-scheduler-plugins/pkg/fixtures"
+)
+
+const (
+	clusterName   = "scheduler-e2e"
+	schedulerName = "my-scheduler"
+	namespace     = "default"
+)
+
+var client kubernetes.Interface
+
+func TestMain(m *testing.M) {
+	kubeconfig := os.Getenv("E2E_KUBECONFIG")
+	teardown := func() {}
+	if kubeconfig == "" {
+		dir, err := os.MkdirTemp("", clusterName)
+		if err != nil {
+			log.Fatalf("failed to create temp dir: %v", err)
+		}
+		kubeconfig = filepath.Join(dir, "kubeconfig")
+		if err := setupCluster(kubeconfig); err != nil {
+			run("kind", "delete", "cluster", "--name", clusterName)
+			log.Fatalf("failed to set up cluster: %v", err)
+		}
+		teardown = func() { run("kind", "delete", "cluster", "--name", clusterName) }
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		log.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	client = kubernetes.NewForConfigOrDie(config)
+
+	code := m.Run()
+	teardown()
+	os.Exit(code)
+}
+
+// setupCluster creates a kind cluster, loads the scheduler image and installs the chart.
+func setupCluster(kubeconfig string) error {
+	root := filepath.Join("..", "..")
+	steps := [][]string{
+		{"kind", "create", "cluster", "--name", clusterName, "--kubeconfig", kubeconfig, "--wait", "120s"},
+		{"docker", "build", root, "-t", "my-scheduler:local"},
+		{"kind", "load", "docker-image", "my-scheduler:local", "--name", clusterName},
+		{"helm", "install", "scheduler-plugins", filepath.Join(root, "charts"), "--kubeconfig", kubeconfig, "--wait"},
+	}
+	for _, step := range steps {
+		if err := run(step[0], step[1:]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %v: %w", name, args, err)
+	}
+	return nil
+}
+
+func createPods(t *testing.T, pods []*v1.Pod) {
+	t.Helper()
+	for _, p := range pods {
+		if _, err := client.CoreV1().Pods(namespace).Create(context.Background(), p, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create pod %s: %v", p.Name, err)
+		}
+		t.Cleanup(func() {
+			client.CoreV1().Pods(namespace).Delete(context.Background(), p.Name, metav1.DeleteOptions{})
+		})
+	}
+}
+
+func boundCount(t *testing.T, pods []*v1.Pod) int {
+	t.Helper()
+	bound := 0
+	for _, p := range pods {
+		got, err := client.CoreV1().Pods(namespace).Get(context.Background(), p.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get pod %s: %v", p.Name, err)
+		}
+		if got.Spec.NodeName != "" {
+			bound++
+		}
+	}
+	return bound
+}
+
+func TestGangBelowMinAvailableStaysPending(t *testing.T) {
+	group := fixtures.GroupSpec{
+		Name:          "e2e-pending",
+		Namespace:     namespace,
+		Size:          2,
+		MinAvailable:  3,
+		Shape:         fixtures.Shapes["small"],
+		SchedulerName: schedulerName,
+	}
+	pods := group.Pods()
+	createPods(t, pods)
+
+	time.Sleep(30 * time.Second)
+	if n := boundCount(t, pods); n != 0 {
+		t.Errorf("expected all pods to stay Pending, %d were bound", n)
+	}
+}
+
+func TestGangAtMinAvailableBinds(t *testing.T) {
+	group := fixtures.GroupSpec{
+		Name:          "e2e-bound",
+		Namespace:     namespace,
+		Size:          3,
+		MinAvailable:  3,
+		Shape:         fixtures.Shapes["small"],
+		SchedulerName: schedulerName,
+	}
+	pods := group.Pods()
+	createPods(t, pods[:2])
+	time.Sleep(10 * time.Second)
+	createPods(t, pods[2:])
+
+	err := wait.PollImmediate(2*time.Second, 2*time.Minute, func() (bool, error) {
+		return boundCount(t, pods) == len(pods), nil
+	})
+	if err != nil {
+		t.Errorf("expected all %d pods to be bound, %d were", len(pods), boundCount(t, pods))
+	}
+}